@@ -5,11 +5,16 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/larkinwc/gitlab-runner-tui/pkg/config"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/gitlab"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/hosts"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/jobstore"
 	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
 	"github.com/larkinwc/gitlab-runner-tui/pkg/ui"
 )
@@ -22,32 +27,135 @@ type model struct {
 	configView  *ui.ConfigView
 	systemView  *ui.SystemView
 	historyView *ui.HistoryView
+	fleetView   *ui.FleetView
+	hosts       map[string]hostEnv
 	width       int
 	height      int
 	quitting    bool
 	debugMode   bool
+	hostName    string
 	initialized map[int]bool
 }
 
-func initialModel(configPath string, debugMode bool) model {
-	service := runner.NewService(configPath)
-	service.SetDebugMode(debugMode)
+// localHostName is the fleet entry for the machine the TUI runs on.
+const localHostName = "local"
+
+// hostEnv is what the tabs need to manage one host.
+type hostEnv struct {
+	service    runner.Service
+	configPath string
+	store      config.FileStore
+	jobs       *jobstore.Store // nil when the job history cannot be stored
+	backups    int             // number of config.toml backups kept
+}
+
+func (h hostEnv) configManager() *config.TOMLConfigManager {
+	cm := config.NewTOMLConfigManagerWithStore(h.configPath, h.store)
+	cm.SetBackupRetention(h.backups)
+	return cm
+}
+
+func initialModel(configPath, jobsDir string, backups int, debugMode bool, profiles []hosts.Host, host *hosts.Host, api *gitlab.Client) model {
+	// The config path applies to the host being managed; other hosts use
+	// their own profile.
+	localPath := configPath
+	if host != nil {
+		localPath = config.DefaultConfigPath
+	}
+
+	envs := map[string]hostEnv{
+		localHostName: newHostEnv(localPath, runner.NewLocalExecutor(), config.LocalStore{}, api),
+	}
+	fleet := []ui.FleetHost{{Name: localHostName, Service: envs[localHostName].service}}
+	for _, h := range profiles {
+		path := h.ConfigPath
+		if host != nil && h.Name == host.Name {
+			path = configPath
+		}
+		executor := h.Executor()
+		envs[h.Name] = newHostEnv(path, executor, config.NewExecStore(executor), api)
+		fleet = append(fleet, ui.FleetHost{Name: h.Name, Service: envs[h.Name].service})
+	}
+	for name, env := range envs {
+		env.service.SetDebugMode(debugMode)
+		env.jobs = openJobStore(jobsDir, name)
+		env.backups = backups
+		envs[name] = env
+	}
+
+	current := localHostName
+	hostName := ""
+	if host != nil {
+		current = host.Name
+		hostName = host.Name
+	}
+	env := envs[current]
 
 	m := model{
-		tabs:        []string{"Runners", "Logs", "Config", "System", "History"},
+		tabs:        []string{"Runners", "Logs", "Config", "System", "History", "Fleet"},
 		activeTab:   0,
-		runnersView: ui.NewRunnersView(service),
-		logsView:    ui.NewLogsView(service),
-		configView:  ui.NewConfigView(configPath),
-		systemView:  ui.NewSystemView(service),
-		historyView: ui.NewHistoryView(service),
+		runnersView: ui.NewRunnersView(env.service),
+		logsView:    ui.NewLogsView(env.service),
+		configView:  ui.NewConfigViewWithManager(env.configManager()),
+		systemView:  ui.NewSystemView(env.service),
+		historyView: ui.NewHistoryView(env.service),
+		fleetView:   ui.NewFleetView(fleet, current),
+		hosts:       envs,
 		debugMode:   debugMode,
+		hostName:    hostName,
 		initialized: make(map[int]bool),
 	}
+	m.runnersView.SetConfigManager(env.configManager())
+	m.configView.SetService(env.service)
+	m.historyView.SetStore(env.jobs)
 	m.initialized[0] = true // Mark first tab as initialized
 	return m
 }
 
+// newHostEnv returns the environment of one host. With a GitLab API
+// client, runner details are loaded from the API for runners whose
+// config.toml entry has an id.
+func newHostEnv(configPath string, executor runner.Executor, store config.FileStore, api *gitlab.Client) hostEnv {
+	env := hostEnv{
+		service:    runner.NewService(configPath, executor),
+		configPath: configPath,
+		store:      store,
+	}
+	if api != nil {
+		env.service = gitlab.NewService(env.service, api, gitlab.ConfigResolver(env.configManager()))
+	}
+	return env
+}
+
+// openJobStore opens the job history of a host in dir. Without a usable
+// directory, the History tab shows the host's recent jobs only.
+func openJobStore(dir, name string) *jobstore.Store {
+	if dir == "" {
+		return nil
+	}
+	name = strings.NewReplacer("/", "_", string(filepath.Separator), "_").Replace(name)
+	store, err := jobstore.Open(filepath.Join(dir, name+".jsonl"))
+	if err != nil {
+		log.Printf("Job history of %s is not kept: %v", name, err)
+		return nil
+	}
+	return store
+}
+
+// watchJobs records the jobs of every host in the background until stop is
+// closed. It only runs with -record-all-hosts; otherwise the jobs of the
+// selected host are recorded as its History tab refreshes.
+func (m model) watchJobs(stop <-chan struct{}) {
+	for _, env := range m.hosts {
+		if env.jobs != nil {
+			go env.jobs.Watch(env.service, jobsInterval, stop)
+		}
+	}
+}
+
+// jobsInterval is how often the job history of each host is recorded.
+const jobsInterval = 2 * time.Minute
+
 func (m model) Init() tea.Cmd {
 	// Only initialize the first view
 	return m.runnersView.Init()
@@ -59,6 +167,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.handleWindowSize(msg)
 	case tea.KeyMsg:
 		return m.handleKeyPress(msg)
+	case ui.HostSelectedMsg:
+		return m.selectHost(msg.Name)
 	}
 
 	return m.updateActiveView(msg)
@@ -73,17 +183,60 @@ func (m model) handleWindowSize(msg tea.WindowSizeMsg) (tea.Model, tea.Cmd) {
 	m.configView.Update(msg)
 	m.systemView.Update(msg)
 	m.historyView.Update(msg)
+	m.fleetView.Update(msg)
 
 	return m, nil
 }
 
+// selectHost scopes the Runners, Logs, Config, System and History tabs to
+// a host of the fleet and shows its runners.
+func (m model) selectHost(name string) (tea.Model, tea.Cmd) {
+	env, ok := m.hosts[name]
+	if !ok {
+		return m, nil
+	}
+
+	m.runnersView.SetService(env.service)
+	m.runnersView.SetConfigManager(env.configManager())
+	m.logsView.SetService(env.service)
+	m.systemView.SetService(env.service)
+	m.historyView.SetService(env.service)
+	m.historyView.SetStore(env.jobs)
+	// Unsaved edits of the previous host's config.toml are dropped
+	m.configView = ui.NewConfigViewWithManager(env.configManager())
+	m.configView.SetService(env.service)
+	m.configView.Update(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+
+	m.hostName = name
+	if name == localHostName {
+		m.hostName = ""
+	}
+
+	// The scoped tabs load again when they are next shown
+	for _, tab := range []int{0, 1, 2, 3, 4} {
+		delete(m.initialized, tab)
+	}
+	m.activeTab = 0
+	return m.switchTab()
+}
+
 func (m model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Modals and inputs get all keys but Ctrl+C
+	capturing := (m.activeTab == 0 && m.runnersView.Capturing()) ||
+		(m.activeTab == 1 && m.logsView.Capturing()) ||
+		(m.activeTab == 2 && m.configView.Capturing()) ||
+		(m.activeTab == 4 && m.historyView.Capturing())
+	if capturing && msg.String() != "ctrl+c" {
+		return m.updateActiveView(msg)
+	}
+
 	switch msg.String() {
 	case "ctrl+c", "q":
 		if m.activeTab == 1 {
 			m.activeTab = 0
-			return m, nil
+			return m.switchTab()
 		}
+		m.logsView.Deactivate()
 		m.quitting = true
 		return m, tea.Quit
 
@@ -95,7 +248,7 @@ func (m model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.activeTab = (m.activeTab - 1 + len(m.tabs)) % len(m.tabs)
 		return m.switchTab()
 
-	case "1", "2", "3", "4", "5":
+	case "1", "2", "3", "4", "5", "6":
 		if idx := int(msg.String()[0] - '1'); idx < len(m.tabs) {
 			m.activeTab = idx
 			return m.switchTab()
@@ -140,15 +293,26 @@ func (m model) updateActiveView(msg tea.Msg) (tea.Model, tea.Cmd) {
 		var updatedView tea.Model
 		updatedView, cmd = m.historyView.Update(msg)
 		m.historyView = updatedView.(*ui.HistoryView)
+	case 5:
+		var updatedView tea.Model
+		updatedView, cmd = m.fleetView.Update(msg)
+		m.fleetView = updatedView.(*ui.FleetView)
 	}
 
 	return m, cmd
 }
 
 func (m model) switchTab() (model, tea.Cmd) {
+	// The log stream runs only while the Logs tab is shown
+	if m.activeTab != 1 {
+		m.logsView.Deactivate()
+	}
+
 	if !m.initialized[m.activeTab] {
 		m.initialized[m.activeTab] = true
 		switch m.activeTab {
+		case 0:
+			return m, m.runnersView.Init()
 		case 1:
 			return m, m.logsView.Init()
 		case 2:
@@ -157,8 +321,20 @@ func (m model) switchTab() (model, tea.Cmd) {
 			return m, m.systemView.Init()
 		case 4:
 			return m, m.historyView.Init()
+		case 5:
+			return m, m.fleetView.Init()
 		}
 	}
+	switch m.activeTab {
+	case 0:
+		return m, m.runnersView.Activate()
+	case 1:
+		return m, m.logsView.Activate()
+	case 2:
+		return m, m.configView.Activate()
+	case 5:
+		return m, m.fleetView.Activate()
+	}
 	return m, nil
 }
 
@@ -186,6 +362,8 @@ func (m model) View() string {
 		content = m.systemView.View()
 	case 4:
 		content = m.historyView.View()
+	case 5:
+		content = m.fleetView.View()
 	}
 
 	statusBar := m.renderStatusBar()
@@ -257,20 +435,22 @@ func (m model) renderStatusBar() string {
 	var commands []string
 
 	// Global commands
-	commands = append(commands, "Tab/Shift+Tab: Switch tabs", "1-5: Jump to tab", "q: Quit")
+	commands = append(commands, "Tab/Shift+Tab: Switch tabs", "1-6: Jump to tab", "q: Quit")
 
 	// Tab-specific commands
 	switch m.activeTab {
 	case 0: // Runners
-		commands = append(commands, "↑/↓: Navigate", "Enter: View logs", "r: Refresh")
+		commands = append(commands, "↑/↓: Navigate", "Enter: View logs", "r: Refresh", "p: Pause/Resume", "e: Edit description", "d: Unregister")
 	case 1: // Logs
-		commands = append(commands, "↑/↓: Scroll", "g/G: Top/Bottom", "a: Auto-scroll", "c: Clear", "r: Refresh")
+		commands = append(commands, "↑/↓: Scroll", "g/G: Top/Bottom", "/: Search", "n/N: Next/Prev match", ":: Filter", "L: Level", "e: Export", "f: Follow", "a: Auto-scroll", "c: Clear", "r: Refresh")
 	case 2: // Config
-		commands = append(commands, "Tab: Next field", "Ctrl+S: Save", "r: Edit runners")
+		commands = append(commands, "Tab: Next field", "Ctrl+S: Save", "r: Edit runners", "[/]: Runner", "Ctrl+N: Register runner", "Ctrl+R: Backups", "Ctrl+L: Reload")
 	case 3: // System
 		commands = append(commands, "r: Refresh", "s: Restart service")
 	case 4: // History
-		commands = append(commands, "↑/↓: Navigate", "r: Refresh")
+		commands = append(commands, "↑/↓: Navigate", "Enter: Details", "←/→: Page", "/: Search", ":: Filter", "o/O: Sort/Reverse", "s: Statistics", "w: Window", "r: Refresh", "e: Export")
+	case 5: // Fleet
+		commands = append(commands, "↑/↓: Navigate", "Enter: Manage host", "r: Refresh")
 	}
 
 	// Add debug mode indicator if enabled
@@ -278,6 +458,9 @@ func (m model) renderStatusBar() string {
 	if m.debugMode {
 		statusText = " [DEBUG] "
 	}
+	if m.hostName != "" {
+		statusText += "@" + m.hostName + " "
+	}
 
 	// Combine status and help
 	status := statusStyle.Render(statusText + m.tabs[m.activeTab])
@@ -300,8 +483,20 @@ func main() {
 	var configPath string
 	var debugMode bool
 	var showHelp bool
+	var hostsPath string
+	var gitlabURL string
+	var hostName string
+	var jobsDir string
+	var backups int
+	var recordAll bool
 
 	flag.StringVar(&configPath, "config", config.DefaultConfigPath, "Path to GitLab Runner config file")
+	flag.StringVar(&hostsPath, "hosts", hosts.DefaultPath(), "Path to the remote hosts file")
+	flag.StringVar(&hostName, "host", "", "Manage the named remote host over SSH")
+	flag.StringVar(&gitlabURL, "gitlab-url", os.Getenv("GITLAB_URL"), "GitLab URL for runner details from the API (token in $GITLAB_TOKEN)")
+	flag.StringVar(&jobsDir, "jobs-dir", jobstore.DefaultDir(), "Directory of the recorded job history (empty to disable)")
+	flag.BoolVar(&recordAll, "record-all-hosts", false, "Record the job history of every host in the background, not only of the one shown")
+	flag.IntVar(&backups, "config-backups", config.DefaultBackupRetention, "Number of timestamped config.toml backups to keep (0 to disable)")
 	flag.BoolVar(&debugMode, "debug", false, "Enable debug mode for verbose logging")
 	flag.BoolVar(&showHelp, "help", false, "Show help information")
 	flag.BoolVar(&showHelp, "h", false, "Show help information")
@@ -315,6 +510,7 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  1. %s (system-wide)\n", config.DefaultConfigPath)
 		fmt.Fprintf(os.Stderr, "  2. $HOME/.gitlab-runner/config.toml (user-specific)\n")
 		fmt.Fprintf(os.Stderr, "\nIf no config is found at the default path, the user-specific path is tried.\n")
+		fmt.Fprintf(os.Stderr, "\nWith -host, all tabs manage the named host from the hosts file over SSH.\n")
 	}
 
 	flag.Parse()
@@ -324,8 +520,34 @@ func main() {
 		os.Exit(0)
 	}
 
+	profiles, err := hosts.Load(hostsPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var host *hosts.Host
+	if hostName != "" {
+		if host, err = hosts.Find(profiles, hostName); err != nil {
+			log.Fatalf("%v (hosts file: %s)", err, hostsPath)
+		}
+
+		// The config path is the host's, unless given on the command line
+		configSet := false
+		flag.Visit(func(f *flag.Flag) {
+			configSet = configSet || f.Name == "config"
+		})
+		if !configSet && host.ConfigPath != "" {
+			configPath = host.ConfigPath
+		}
+	}
+
+	var api *gitlab.Client
+	if token := os.Getenv("GITLAB_TOKEN"); gitlabURL != "" && token != "" {
+		api = gitlab.NewClient(gitlabURL, token, nil)
+	}
+
 	// Check if config exists at specified path
-	if configPath == config.DefaultConfigPath {
+	if host == nil && configPath == config.DefaultConfigPath {
 		if _, err := os.Stat(configPath); os.IsNotExist(err) {
 			altPath := os.ExpandEnv("$HOME/.gitlab-runner/config.toml")
 			if _, err := os.Stat(altPath); err == nil {
@@ -337,13 +559,21 @@ func main() {
 		}
 	}
 
+	m := initialModel(configPath, jobsDir, backups, debugMode, profiles, host, api)
+	stop := make(chan struct{})
+	if recordAll {
+		m.watchJobs(stop)
+	}
+
 	p := tea.NewProgram(
-		initialModel(configPath, debugMode),
+		m,
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
 	)
 
-	if _, err := p.Run(); err != nil {
+	_, err = p.Run()
+	close(stop)
+	if err != nil {
 		log.Fatal(err)
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/larkinwc/gitlab-runner-tui/pkg/config"
 	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
 	"github.com/larkinwc/gitlab-runner-tui/pkg/ui"
 )
@@ -103,7 +104,7 @@ func TestModelSwitchTab(t *testing.T) {
 	// Create a minimal model with mocked services
 	service := &mockRunnerService{}
 	m := model{
-		tabs:        []string{"Tab1", "Tab2", "Tab3"},
+		tabs:        []string{"Tab1", "Tab2", "Tab3", "Tab4"},
 		activeTab:   0,
 		initialized: map[int]bool{0: true},
 		runnersView: ui.NewRunnersView(service),
@@ -125,9 +126,9 @@ func TestModelSwitchTab(t *testing.T) {
 		t.Error("Expected init command for uninitialized tab")
 	}
 
-	// Test switching to already initialized tab
-	m.initialized[2] = true
-	m.activeTab = 2
+	// Test switching to already initialized tab without background work
+	m.initialized[3] = true
+	m.activeTab = 3
 	newModel, cmd = m.switchTab()
 
 	if cmd != nil {
@@ -135,11 +136,51 @@ func TestModelSwitchTab(t *testing.T) {
 	}
 }
 
+func TestModelSelectHost(t *testing.T) {
+	local, remote := &mockRunnerService{}, &mockRunnerService{}
+	m := model{
+		tabs:        []string{"Runners", "Logs", "Config", "System", "History", "Fleet"},
+		activeTab:   5,
+		initialized: map[int]bool{0: true, 1: true, 2: true, 3: true, 5: true},
+		runnersView: ui.NewRunnersView(local),
+		logsView:    ui.NewLogsView(local),
+		configView:  ui.NewConfigView("/tmp/test-config.toml"),
+		systemView:  ui.NewSystemView(local),
+		historyView: ui.NewHistoryView(local),
+		hosts: map[string]hostEnv{
+			localHostName: {service: local, configPath: "/tmp/test-config.toml", store: config.LocalStore{}},
+			"ci-01":       {service: remote, configPath: "/etc/gitlab-runner/config.toml", store: config.LocalStore{}},
+		},
+	}
+
+	updated, cmd := m.Update(ui.HostSelectedMsg{Name: "ci-01"})
+	m = updated.(model)
+
+	if m.activeTab != 0 || m.hostName != "ci-01" {
+		t.Errorf("activeTab = %d, hostName = %q", m.activeTab, m.hostName)
+	}
+	if cmd == nil {
+		t.Error("expected runners to be reloaded")
+	}
+	if m.initialized[1] || m.initialized[2] || m.initialized[3] {
+		t.Errorf("unexpected initialized tabs: %v", m.initialized)
+	}
+	if path := m.configView.ConfigPath(); path != "/etc/gitlab-runner/config.toml" {
+		t.Errorf("Config tab edits %s, expected the selected host's config.toml", path)
+	}
+
+	updated, _ = m.Update(ui.HostSelectedMsg{Name: localHostName})
+	if m = updated.(model); m.hostName != "" {
+		t.Errorf("hostName = %q after selecting the local host", m.hostName)
+	}
+}
+
 func TestRenderStatusBar(t *testing.T) {
 	tests := []struct {
 		name      string
 		activeTab int
 		debugMode bool
+		hostName  string
 		width     int
 		contains  []string
 	}{
@@ -162,6 +203,13 @@ func TestRenderStatusBar(t *testing.T) {
 			width:     100,
 			contains:  []string{"[DEBUG]"},
 		},
+		{
+			name:      "Remote host indicator",
+			activeTab: 2,
+			hostName:  "ci-01",
+			width:     100,
+			contains:  []string{"@ci-01", "Config"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -170,6 +218,7 @@ func TestRenderStatusBar(t *testing.T) {
 				tabs:      []string{"Runners", "Logs", "Config", "System", "History"},
 				activeTab: tt.activeTab,
 				debugMode: tt.debugMode,
+				hostName:  tt.hostName,
 				width:     tt.width,
 			}
 
@@ -207,6 +256,14 @@ func (m *mockRunnerService) RestartRunner() error {
 	return nil
 }
 
+func (m *mockRunnerService) RegisterRunner(_ runner.RegisterOptions) error {
+	return nil
+}
+
+func (m *mockRunnerService) UnregisterRunner(_ string) error {
+	return nil
+}
+
 func (m *mockRunnerService) GetSystemStatus() (*runner.SystemStatus, error) {
 	return &runner.SystemStatus{}, nil
 }
@@ -215,4 +272,12 @@ func (m *mockRunnerService) GetJobHistory(_ int) ([]runner.Job, error) {
 	return []runner.Job{}, nil
 }
 
+func (m *mockRunnerService) GetJobLogs(_ int) ([]string, error) {
+	return []string{}, nil
+}
+
+func (m *mockRunnerService) GetRunningJobs() ([]runner.Job, error) {
+	return []runner.Job{}, nil
+}
+
 func (m *mockRunnerService) SetDebugMode(_ bool) {}
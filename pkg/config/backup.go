@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBackupRetention is the number of config.toml backups kept unless
+// SetBackupRetention says otherwise.
+const DefaultBackupRetention = 10
+
+// backupTimeLayout is the timestamp in backup names, as in
+// config.toml.bak.20250101-120000. Names are suffixed with -1, -2, ... when
+// several backups are made within a second.
+const backupTimeLayout = "20060102-150405"
+
+// Backup is a previous version of the config file, kept next to it when it
+// was saved or restored.
+type Backup struct {
+	Path string
+	// Time is when the version was replaced.
+	Time time.Time
+}
+
+// SetBackupRetention sets the number of backups Save and Restore keep. 0
+// turns backups off.
+func (cm *TOMLConfigManager) SetBackupRetention(n int) {
+	cm.keepBackups = max(n, 0)
+}
+
+func (cm *TOMLConfigManager) backupPrefix() string {
+	return cm.path + ".bak."
+}
+
+// Backups lists the backups of the config file, newest first.
+func (cm *TOMLConfigManager) Backups() ([]Backup, error) {
+	paths, err := cm.store.List(cm.backupPrefix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	type numbered struct {
+		Backup
+		seq int
+	}
+	var found []numbered
+	for _, path := range paths {
+		name := strings.TrimPrefix(path, cm.backupPrefix())
+		if len(name) < len(backupTimeLayout) {
+			continue
+		}
+		t, err := time.ParseInLocation(backupTimeLayout, name[:len(backupTimeLayout)], time.Local)
+		if err != nil {
+			continue
+		}
+		seq := 0
+		if suffix := name[len(backupTimeLayout):]; suffix != "" {
+			if seq, err = strconv.Atoi(strings.TrimPrefix(suffix, "-")); err != nil || !strings.HasPrefix(suffix, "-") {
+				continue
+			}
+		}
+		found = append(found, numbered{Backup{Path: path, Time: t}, seq})
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if !found[i].Time.Equal(found[j].Time) {
+			return found[i].Time.After(found[j].Time)
+		}
+		return found[i].seq > found[j].seq
+	})
+
+	backups := make([]Backup, len(found))
+	for i, b := range found {
+		backups[i] = b.Backup
+	}
+	return backups, nil
+}
+
+// nextBackup returns the path the current file is backed up to on the
+// next write, or "" when backups are off.
+func (cm *TOMLConfigManager) nextBackup() string {
+	if cm.keepBackups == 0 {
+		return ""
+	}
+	base := cm.backupPrefix() + cm.now().Format(backupTimeLayout)
+	taken := make(map[string]bool)
+	if paths, err := cm.store.List(base); err == nil {
+		for _, path := range paths {
+			taken[path] = true
+		}
+	}
+	path := base
+	for i := 1; taken[path]; i++ {
+		path = fmt.Sprintf("%s-%d", base, i)
+	}
+	return path
+}
+
+// pruneBackups removes the oldest backups beyond the retention count.
+func (cm *TOMLConfigManager) pruneBackups() error {
+	if cm.keepBackups == 0 {
+		return nil
+	}
+	backups, err := cm.Backups()
+	if err != nil {
+		return err
+	}
+	for _, backup := range backups[min(cm.keepBackups, len(backups)):] {
+		if err := cm.store.Remove(backup.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BackupDiff returns a unified diff of the backup against the config file
+// as it is now, or "" when they are the same.
+func (cm *TOMLConfigManager) BackupDiff(backup Backup) (string, error) {
+	old, err := cm.store.ReadFile(backup.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read backup: %w", err)
+	}
+	current, err := cm.store.ReadFile(cm.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read config file: %w", err)
+	}
+	return UnifiedDiff(backup.Path, cm.path, old, current), nil
+}
+
+// Restore replaces the config file with the backup in one atomic write and
+// loads it. Unsaved changes are dropped. The replaced file is backed up in
+// turn, so a restore can be undone.
+func (cm *TOMLConfigManager) Restore(backup Backup) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	data, err := cm.store.ReadFile(backup.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+	if err := cm.store.WriteFile(cm.path, data, cm.nextBackup()); err != nil {
+		return err
+	}
+	// A backup that could not be removed is only kept longer
+	_ = cm.pruneBackups()
+	return cm.load()
+}
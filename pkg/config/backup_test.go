@@ -0,0 +1,133 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+// steppingClock returns a clock that advances by step on each call.
+func steppingClock(step time.Duration) func() time.Time {
+	t := time.Date(2025, 1, 1, 12, 0, 0, 0, time.Local)
+	return func() time.Time {
+		t = t.Add(step)
+		return t
+	}
+}
+
+func saveConcurrency(t *testing.T, cm *TOMLConfigManager, concurrent int) {
+	t.Helper()
+	if err := cm.UpdateConcurrency(concurrent); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+}
+
+func TestBackups_Retention(t *testing.T) {
+	cm := loadConfig(t, "concurrent = 1\n")
+	cm.now = steppingClock(time.Minute)
+	cm.SetBackupRetention(2)
+
+	for concurrent := 2; concurrent <= 4; concurrent++ {
+		saveConcurrency(t, cm, concurrent)
+	}
+
+	backups, err := cm.Backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups, got %v", backups)
+	}
+	if !strings.HasSuffix(backups[0].Path, ".bak.20250101-120300") || !backups[0].Time.Equal(time.Date(2025, 1, 1, 12, 3, 0, 0, time.Local)) {
+		t.Errorf("expected newest backup first, got %+v", backups[0])
+	}
+	for i, want := range []string{"concurrent = 3\n", "concurrent = 2\n"} {
+		if data, _ := os.ReadFile(backups[i].Path); string(data) != want {
+			t.Errorf("backup %d = %q, expected %q", i, data, want)
+		}
+	}
+}
+
+func TestBackups_SameSecond(t *testing.T) {
+	cm := loadConfig(t, "concurrent = 1\n")
+	cm.now = steppingClock(0)
+
+	for concurrent := 2; concurrent <= 4; concurrent++ {
+		saveConcurrency(t, cm, concurrent)
+	}
+
+	backups, err := cm.Backups()
+	if err != nil || len(backups) != 3 {
+		t.Fatalf("expected 3 backups, got %v %v", backups, err)
+	}
+	for i, suffix := range []string{".bak.20250101-120000-2", ".bak.20250101-120000-1", ".bak.20250101-120000"} {
+		if !strings.HasSuffix(backups[i].Path, suffix) {
+			t.Errorf("backup %d is %s, expected it to end in %s", i, backups[i].Path, suffix)
+		}
+	}
+}
+
+func TestBackups_Off(t *testing.T) {
+	cm := loadConfig(t, "concurrent = 1\n")
+	cm.SetBackupRetention(0)
+	saveConcurrency(t, cm, 2)
+
+	if backups, err := cm.Backups(); err != nil || len(backups) != 0 {
+		t.Errorf("expected no backups, got %v %v", backups, err)
+	}
+}
+
+func TestBackups_DiffAndRestore(t *testing.T) {
+	for name, store := range map[string]FileStore{
+		"local":  LocalStore{},
+		"remote": NewExecStore(runner.NewLocalExecutor()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := writeConfig(t, "concurrent = 1\n")
+			cm := NewTOMLConfigManagerWithStore(path, store)
+			cm.now = steppingClock(time.Minute)
+			if err := cm.Load(); err != nil {
+				t.Fatal(err)
+			}
+			saveConcurrency(t, cm, 2)
+
+			backups, err := cm.Backups()
+			if err != nil || len(backups) != 1 {
+				t.Fatalf("expected one backup, got %v %v", backups, err)
+			}
+			diff, err := cm.BackupDiff(backups[0])
+			if err != nil || !strings.Contains(diff, "-concurrent = 1\n+concurrent = 2\n") {
+				t.Fatalf("unexpected diff %q %v", diff, err)
+			}
+
+			if err := cm.Restore(backups[0]); err != nil {
+				t.Fatalf("Restore failed: %v", err)
+			}
+			if data, _ := os.ReadFile(path); string(data) != "concurrent = 1\n" {
+				t.Errorf("expected restored file, got %q", data)
+			}
+			if cm.GetConfig().Concurrent != 1 {
+				t.Errorf("expected restored config to be loaded, got %d", cm.GetConfig().Concurrent)
+			}
+
+			// The replaced version is backed up too
+			backups, _ = cm.Backups()
+			if len(backups) != 2 {
+				t.Fatalf("expected the restore to add a backup, got %v", backups)
+			}
+			if data, _ := os.ReadFile(backups[0].Path); string(data) != "concurrent = 2\n" {
+				t.Errorf("expected replaced version in the newest backup, got %q", data)
+			}
+			if leftovers, _ := filepath.Glob(path + ".tmp"); len(leftovers) != 0 {
+				t.Errorf("temporary file left behind: %v", leftovers)
+			}
+		})
+	}
+}
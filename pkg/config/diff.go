@@ -0,0 +1,169 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around changes.
+const diffContext = 3
+
+// diffLine is a line of a diff: ' ' for a line both sides have, '-' for
+// one only the old side has and '+' for one only the new side has.
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// UnifiedDiff returns the changes from old to new as a unified diff with
+// the file names oldName and newName, or "" when they are equal.
+func UnifiedDiff(oldName, newName string, old, new []byte) string {
+	lines := diffLines(splitLines(old), splitLines(new))
+
+	var b strings.Builder
+	for _, hunk := range hunks(lines) {
+		if b.Len() == 0 {
+			fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+		}
+		b.WriteString(hunk)
+	}
+	return b.String()
+}
+
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+// diffLines finds the shortest edit script from a to b with Myers'
+// algorithm.
+func diffLines(a, b []string) []diffLine {
+	n, m := len(a), len(b)
+	offset := n + m
+	v := make([]int, 2*(n+m)+2)
+	var trace [][]int
+
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk back from the end through the furthest reaching paths
+	var lines []diffLine
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			lines = append(lines, diffLine{' ', a[x-1]})
+			x--
+			y--
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			lines = append(lines, diffLine{'+', b[y-1]})
+		} else {
+			lines = append(lines, diffLine{'-', a[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return lines
+}
+
+// hunks groups the changes of lines with diffContext lines around them.
+// Changes closer than twice diffContext share a hunk.
+func hunks(lines []diffLine) []string {
+	var result []string
+	for start := 0; start < len(lines); {
+		first := start
+		for first < len(lines) && lines[first].op == ' ' {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+
+		// Extend the hunk while the next change is within reach
+		last := first
+		for i := first; i < len(lines); i++ {
+			if lines[i].op != ' ' {
+				last = i
+			} else if i-last > 2*diffContext {
+				break
+			}
+		}
+
+		from := max(first-diffContext, start)
+		to := min(last+diffContext+1, len(lines))
+		result = append(result, formatHunk(lines, from, to))
+		start = to
+	}
+	return result
+}
+
+// formatHunk formats lines[from:to] with its header. Line numbers count
+// the lines before from on each side.
+func formatHunk(lines []diffLine, from, to int) string {
+	oldStart, newStart := 1, 1
+	for _, line := range lines[:from] {
+		if line.op != '+' {
+			oldStart++
+		}
+		if line.op != '-' {
+			newStart++
+		}
+	}
+
+	var oldLen, newLen int
+	var body strings.Builder
+	for _, line := range lines[from:to] {
+		if line.op != '+' {
+			oldLen++
+		}
+		if line.op != '-' {
+			newLen++
+		}
+		body.WriteByte(line.op)
+		body.WriteString(line.text)
+		body.WriteByte('\n')
+	}
+	// Empty sides start at the line before the hunk, as in diff -u
+	if oldLen == 0 {
+		oldStart--
+	}
+	if newLen == 0 {
+		newStart--
+	}
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@\n%s", oldStart, oldLen, newStart, newLen, body.String())
+}
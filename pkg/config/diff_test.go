@@ -0,0 +1,78 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		expected string
+	}{
+		{
+			name: "Equal",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+		},
+		{
+			name:     "Changed line",
+			old:      "a\nb\nc\n",
+			new:      "a\nB\nc\n",
+			expected: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name:     "Added to an empty file",
+			old:      "",
+			new:      "a\nb\n",
+			expected: "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:     "Removed at the end",
+			old:      "1\n2\n3\n4\n5\n6\n",
+			new:      "1\n2\n3\n4\n5\n",
+			expected: "--- old\n+++ new\n@@ -3,4 +3,3 @@\n 3\n 4\n 5\n-6\n",
+		},
+		{
+			name: "Separate hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n",
+			new:  "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\nfifteen\n",
+			expected: "--- old\n+++ new\n" +
+				"@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+				"@@ -12,4 +12,4 @@\n 12\n 13\n 14\n-15\n+fifteen\n",
+		},
+		{
+			name:     "Close changes share a hunk",
+			old:      "1\n2\n3\n4\n5\n6\n7\n8\n",
+			new:      "1\nzwei\n3\n4\n5\n6\n7\nacht\n",
+			expected: "--- old\n+++ new\n@@ -1,8 +1,8 @@\n 1\n-2\n+zwei\n 3\n 4\n 5\n 6\n 7\n-8\n+acht\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UnifiedDiff("old", "new", []byte(tt.old), []byte(tt.new))
+			if got != tt.expected {
+				t.Errorf("diff:\n%s\nexpected:\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPendingDiff(t *testing.T) {
+	cm := loadConfig(t, "concurrent = 1\n\n[[runners]]\n  name = \"a\"\n  url = \"https://gitlab.example.com\"\n  token = \"t\"\n  executor = \"shell\"\n")
+
+	if diff, err := cm.PendingDiff(); err != nil || diff != "" {
+		t.Fatalf("expected no diff before changes, got %q %v", diff, err)
+	}
+
+	cm.GetConfig().Concurrent = 4
+	diff, err := cm.PendingDiff()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff, "-concurrent = 1\n+concurrent = 4\n") {
+		t.Errorf("unexpected diff:\n%s", diff)
+	}
+}
@@ -0,0 +1,922 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// pathElem is one segment of a table path. index is the position of the
+// element inside an array of tables, or -1 for a plain table.
+type pathElem struct {
+	name  string
+	index int
+}
+
+// tablePath identifies a table in a TOML document, e.g. runners[1].docker.
+// The empty path is the root table.
+type tablePath []pathElem
+
+func (p tablePath) String() string {
+	var b strings.Builder
+	for i, e := range p {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(e.name)
+		if e.index >= 0 {
+			fmt.Fprintf(&b, "[%d]", e.index)
+		}
+	}
+	return b.String()
+}
+
+func (p tablePath) child(name string, index int) tablePath {
+	c := make(tablePath, len(p), len(p)+1)
+	copy(c, p)
+	return append(c, pathElem{name: name, index: index})
+}
+
+func (p tablePath) parent() tablePath {
+	if len(p) == 0 {
+		return p
+	}
+	return p[:len(p)-1]
+}
+
+func (p tablePath) last() pathElem {
+	return p[len(p)-1]
+}
+
+func (p tablePath) hasPrefix(q tablePath) bool {
+	if len(q) > len(p) {
+		return false
+	}
+	for i := range q {
+		if p[i] != q[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (p tablePath) equal(q tablePath) bool {
+	return len(p) == len(q) && p.hasPrefix(q)
+}
+
+// entry is a header or key/value pair found while scanning a document.
+type entry struct {
+	header   bool
+	array    bool
+	table    tablePath
+	key      []string
+	start    int
+	end      int
+	indent   string
+	valStart int
+	valEnd   int
+}
+
+func (e *entry) isKey(name string) bool {
+	return !e.header && len(e.key) == 1 && e.key[0] == name
+}
+
+// document is a line-oriented view of a TOML file. It can change, add and
+// remove individual keys and tables while leaving every other byte of the
+// file - comments, ordering, formatting and unknown settings - untouched.
+type document struct {
+	lines []string
+	eol   string
+}
+
+func parseDocument(data []byte) (*document, error) {
+	d := &document{}
+	if len(data) > 0 {
+		d.lines = strings.Split(string(data), "\n")
+		if strings.HasSuffix(d.lines[0], "\r") {
+			d.eol = "\r"
+		}
+	}
+	if _, err := d.scan(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *document) Bytes() []byte {
+	return []byte(strings.Join(d.lines, "\n"))
+}
+
+// contentEnd returns the line index new content is appended at, which is
+// before the empty pseudo-line that follows a trailing newline.
+func (d *document) contentEnd() int {
+	if n := len(d.lines); n > 0 && d.lines[n-1] == "" {
+		return n - 1
+	}
+	return len(d.lines)
+}
+
+func (d *document) insert(at int, lines ...string) {
+	if len(d.lines) == 0 {
+		// An empty file gets a trailing newline once content is added.
+		d.lines = []string{""}
+		at = 0
+	}
+	for i := range lines {
+		lines[i] += d.eol
+	}
+	d.lines = append(d.lines[:at], append(lines, d.lines[at:]...)...)
+}
+
+func (d *document) delete(from, to int) {
+	d.lines = append(d.lines[:from], d.lines[to:]...)
+}
+
+func (d *document) scan() ([]entry, error) {
+	var entries []entry
+	var current tablePath
+	arrayCounts := make(map[string]int)
+
+	for i := 0; i < len(d.lines); i++ {
+		line := d.lines[i]
+		trimmed := strings.TrimLeft(line, " \t")
+		content := strings.TrimRight(trimmed, " \t\r")
+		if content == "" || content[0] == '#' {
+			continue
+		}
+		indent := line[:len(line)-len(trimmed)]
+
+		if content[0] == '[' {
+			e, err := parseHeader(line, len(indent), arrayCounts)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			e.start, e.end, e.indent = i, i+1, indent
+			current = e.table
+			entries = append(entries, e)
+			continue
+		}
+
+		key, pos, err := parseKey(line, len(indent))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		pos = skipSpace(line, pos)
+		if pos >= len(line) || line[pos] != '=' {
+			return nil, fmt.Errorf("line %d: expected '=' after key", i+1)
+		}
+		pos = skipSpace(line, pos+1)
+
+		endLine, endCol, err := d.scanValue(i, pos)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		entries = append(entries, entry{
+			table:    current,
+			key:      key,
+			start:    i,
+			end:      endLine + 1,
+			indent:   indent,
+			valStart: pos,
+			valEnd:   endCol,
+		})
+		i = endLine
+	}
+
+	return entries, nil
+}
+
+func parseHeader(line string, pos int, arrayCounts map[string]int) (entry, error) {
+	e := entry{header: true}
+	open, closing := "[", "]"
+	if strings.HasPrefix(line[pos:], "[[") {
+		e.array = true
+		open, closing = "[[", "]]"
+	}
+
+	segs, pos, err := parseKey(line, pos+len(open))
+	if err != nil {
+		return e, err
+	}
+	pos = skipSpace(line, pos)
+	if !strings.HasPrefix(line[pos:], closing) {
+		return e, fmt.Errorf("unterminated table header")
+	}
+
+	// Resolve every segment to a concrete array element so that e.g.
+	// [runners.docker] is attached to the most recent [[runners]].
+	resolved := ""
+	for j, seg := range segs {
+		key := seg
+		if resolved != "" {
+			key = resolved + "." + seg
+		}
+		index := -1
+		if j == len(segs)-1 && e.array {
+			arrayCounts[key]++
+		}
+		if n, ok := arrayCounts[key]; ok {
+			index = n - 1
+			key = fmt.Sprintf("%s[%d]", key, index)
+		}
+		e.table = append(e.table, pathElem{name: seg, index: index})
+		resolved = key
+	}
+
+	return e, nil
+}
+
+func skipSpace(line string, pos int) int {
+	for pos < len(line) && (line[pos] == ' ' || line[pos] == '\t') {
+		pos++
+	}
+	return pos
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// parseKey parses a possibly dotted, possibly quoted key starting at pos.
+func parseKey(line string, pos int) (segs []string, next int, err error) {
+	for {
+		pos = skipSpace(line, pos)
+		if pos >= len(line) {
+			return nil, pos, fmt.Errorf("expected key")
+		}
+
+		switch line[pos] {
+		case '"':
+			end := pos + 1
+			for end < len(line) && line[end] != '"' {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(line) {
+				return nil, pos, fmt.Errorf("unterminated quoted key")
+			}
+			seg, err := strconv.Unquote(line[pos : end+1])
+			if err != nil {
+				seg = line[pos+1 : end]
+			}
+			segs = append(segs, seg)
+			pos = end + 1
+		case '\'':
+			end := strings.IndexByte(line[pos+1:], '\'')
+			if end < 0 {
+				return nil, pos, fmt.Errorf("unterminated quoted key")
+			}
+			segs = append(segs, line[pos+1:pos+1+end])
+			pos += end + 2
+		default:
+			start := pos
+			for pos < len(line) && isBareKeyChar(line[pos]) {
+				pos++
+			}
+			if pos == start {
+				return nil, pos, fmt.Errorf("invalid character %q in key", line[pos])
+			}
+			segs = append(segs, line[start:pos])
+		}
+
+		pos = skipSpace(line, pos)
+		if pos < len(line) && line[pos] == '.' {
+			pos++
+			continue
+		}
+		return segs, pos, nil
+	}
+}
+
+type valueState int
+
+const (
+	stateNormal valueState = iota
+	stateMultiBasic
+	stateMultiLiteral
+)
+
+// scanValue finds where the value starting at (line, col) ends. The returned
+// column is just past the last significant character, so trailing
+// whitespace and comments are not part of the value.
+func (d *document) scanValue(line, col int) (endLine, endCol int, err error) {
+	depth := 0
+	state := stateNormal
+	endLine, endCol = line, col
+
+	for i := line; i < len(d.lines); i++ {
+		text := d.lines[i]
+		j := 0
+		if i == line {
+			j = col
+		}
+
+		for j < len(text) {
+			c := text[j]
+
+			if state != stateNormal {
+				closing := `"""`
+				if state == stateMultiLiteral {
+					closing = `'''`
+				}
+				switch {
+				case state == stateMultiBasic && c == '\\':
+					j += 2
+				case strings.HasPrefix(text[j:], closing):
+					j += 3
+					for j < len(text) && text[j] == closing[0] {
+						j++
+					}
+					state = stateNormal
+					endLine, endCol = i, j
+				default:
+					j++
+				}
+				continue
+			}
+
+			switch {
+			case c == ' ' || c == '\t' || c == '\r':
+				j++
+				continue
+			case c == '#':
+				j = len(text)
+				continue
+			case strings.HasPrefix(text[j:], `"""`):
+				state = stateMultiBasic
+				j += 3
+			case strings.HasPrefix(text[j:], `'''`):
+				state = stateMultiLiteral
+				j += 3
+			case c == '"':
+				k := j + 1
+				for k < len(text) && text[k] != '"' {
+					if text[k] == '\\' {
+						k++
+					}
+					k++
+				}
+				if k >= len(text) {
+					return 0, 0, fmt.Errorf("unterminated string")
+				}
+				j = k + 1
+			case c == '\'':
+				k := strings.IndexByte(text[j+1:], '\'')
+				if k < 0 {
+					return 0, 0, fmt.Errorf("unterminated string")
+				}
+				j += k + 2
+			case c == '[' || c == '{':
+				depth++
+				j++
+			case c == ']' || c == '}':
+				depth--
+				j++
+			default:
+				j++
+			}
+			endLine, endCol = i, j
+		}
+
+		if state == stateNormal && depth <= 0 {
+			if endLine == line && endCol == col {
+				return 0, 0, fmt.Errorf("missing value")
+			}
+			return endLine, endCol, nil
+		}
+	}
+
+	return 0, 0, fmt.Errorf("unterminated value")
+}
+
+func findHeader(entries []entry, p tablePath) int {
+	for i := range entries {
+		if entries[i].header && entries[i].table.equal(p) {
+			return i
+		}
+	}
+	return -1
+}
+
+func findKey(entries []entry, p tablePath, key string) int {
+	for i := range entries {
+		if entries[i].isKey(key) && entries[i].table.equal(p) {
+			return i
+		}
+	}
+	return -1
+}
+
+// regionEnd returns the index of the first entry after header h that does
+// not belong to the table at h or one of its sub-tables.
+func regionEnd(entries []entry, h int) int {
+	p := entries[h].table
+	for i := h + 1; i < len(entries); i++ {
+		if entries[i].header && !entries[i].table.hasPrefix(p) {
+			return i
+		}
+	}
+	return len(entries)
+}
+
+// attachedStart moves a line index up over the comment block directly
+// above it, so inserted content does not separate a header from its comment.
+func (d *document) attachedStart(line int) int {
+	for line > 0 && strings.HasPrefix(strings.TrimSpace(d.lines[line-1]), "#") {
+		line--
+	}
+	return line
+}
+
+func firstHeader(entries []entry) int {
+	for i := range entries {
+		if entries[i].header {
+			return i
+		}
+	}
+	return len(entries)
+}
+
+// set replaces the value of key in table p, adding the key (and the table)
+// if it does not exist yet. value must already be TOML encoded.
+func (d *document) set(p tablePath, key, value string) error {
+	entries, err := d.scan()
+	if err != nil {
+		return err
+	}
+
+	if i := findKey(entries, p, key); i >= 0 {
+		e := entries[i]
+		first, last := d.lines[e.start], d.lines[e.end-1]
+		line := first[:e.valStart] + value + last[e.valEnd:]
+		d.lines = append(d.lines[:e.start], append([]string{line}, d.lines[e.end:]...)...)
+		return nil
+	}
+
+	if err := d.ensureTable(p); err != nil {
+		return err
+	}
+	if entries, err = d.scan(); err != nil {
+		return err
+	}
+
+	from, to, at, indent := 0, firstHeader(entries), -1, ""
+	if len(p) > 0 {
+		from = findHeader(entries, p)
+		at, indent = entries[from].end, entries[from].indent+"  "
+		from++
+		to = from
+		for to < len(entries) && !entries[to].header {
+			to++
+		}
+	}
+	if at < 0 {
+		at = 0
+		if to < len(entries) {
+			at = d.attachedStart(entries[to].start)
+		} else {
+			at = d.contentEnd()
+		}
+	}
+	if from < to {
+		at, indent = entries[to-1].end, entries[to-1].indent
+	}
+
+	d.insert(at, indent+encodeKey(key)+" = "+value)
+	return nil
+}
+
+// setArray is set for an array value. An array laid out over several
+// lines keeps its layout: items that remain keep their line and the
+// comments above it, and new items get a line of their own.
+func (d *document) setArray(p tablePath, key, value string, items []any) error {
+	entries, err := d.scan()
+	if err != nil {
+		return err
+	}
+	i := findKey(entries, p, key)
+	if i < 0 || entries[i].end-entries[i].start == 1 || d.lines[entries[i].start][entries[i].valStart] != '[' {
+		return d.set(p, key, value)
+	}
+
+	e := entries[i]
+	first, last := d.lines[e.start], d.lines[e.end-1]
+	if !isBlankOrComment(first[e.valStart+1:]) || strings.TrimSpace(last[:e.valEnd-1]) != "" {
+		return fmt.Errorf("array %s shares a line with its brackets and cannot be edited", p.child(key, -1))
+	}
+
+	// Every item is kept with the comment and blank lines above it
+	type arrayLine struct {
+		lines      []string
+		value      string
+		start, end int
+	}
+	var old []arrayLine
+	var above []string
+	for _, line := range d.lines[e.start+1 : e.end-1] {
+		spans, ok := lineItems(line)
+		if !ok || len(spans) > 1 {
+			return fmt.Errorf("array %s has items sharing a line and cannot be edited", p.child(key, -1))
+		}
+		if len(spans) == 0 {
+			above = append(above, line)
+			continue
+		}
+		old = append(old, arrayLine{
+			lines: append(above, line),
+			value: canonicalItem(line[spans[0][0]:spans[0][1]]),
+			start: spans[0][0],
+			end:   spans[0][1],
+		})
+		above = nil
+	}
+
+	indent := last[:e.valEnd-1] + "  "
+	trailingComma := true
+	if n := len(old); n > 0 {
+		line := old[n-1].lines[len(old[n-1].lines)-1]
+		indent = line[:old[n-1].start]
+		trailingComma = strings.HasPrefix(strings.TrimLeft(line[old[n-1].end:], " \t"), ",")
+	}
+
+	lines := []string{first}
+	used := make([]bool, len(old))
+	for n, item := range items {
+		encoded, err := encodeValue(item)
+		if err != nil {
+			return err
+		}
+		comma := n < len(items)-1 || trailingComma
+
+		j := 0
+		for j < len(old) && (used[j] || old[j].value != encoded) {
+			j++
+		}
+		if j == len(old) {
+			line := indent + encoded
+			if comma {
+				line += ","
+			}
+			lines = append(lines, line+d.eol)
+			continue
+		}
+		used[j] = true
+		kept := old[j].lines
+		lines = append(lines, kept[:len(kept)-1]...)
+		lines = append(lines, withComma(kept[len(kept)-1], old[j].end, comma))
+	}
+	lines = append(lines, above...)
+	lines = append(lines, last)
+
+	d.lines = append(d.lines[:e.start], append(lines, d.lines[e.end:]...)...)
+	return nil
+}
+
+// lineItems returns where the array items on line start and end. ok is
+// false if an item does not end on the line.
+func lineItems(line string) (spans [][2]int, ok bool) {
+	depth, start, end := 0, -1, 0
+	for j := 0; j < len(line); {
+		c := line[j]
+		switch {
+		case c == '#':
+			j = len(line)
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			j++
+			continue
+		case c == ',' && depth == 0:
+			if start >= 0 {
+				spans = append(spans, [2]int{start, end})
+				start = -1
+			}
+			j++
+			continue
+		}
+
+		if start < 0 {
+			start = j
+		}
+		switch {
+		case strings.HasPrefix(line[j:], `"""`) || strings.HasPrefix(line[j:], `'''`):
+			k := strings.Index(line[j+3:], line[j:j+3])
+			if k < 0 {
+				return nil, false
+			}
+			j += k + 6
+		case c == '"':
+			k := j + 1
+			for k < len(line) && line[k] != '"' {
+				if line[k] == '\\' {
+					k++
+				}
+				k++
+			}
+			if k >= len(line) {
+				return nil, false
+			}
+			j = k + 1
+		case c == '\'':
+			k := strings.IndexByte(line[j+1:], '\'')
+			if k < 0 {
+				return nil, false
+			}
+			j += k + 2
+		case c == '[' || c == '{':
+			depth++
+			j++
+		case c == ']' || c == '}':
+			depth--
+			j++
+		default:
+			j++
+		}
+		end = j
+	}
+	if depth != 0 {
+		return nil, false
+	}
+	if start >= 0 {
+		spans = append(spans, [2]int{start, end})
+	}
+	return spans, true
+}
+
+// canonicalItem re-encodes an array item as it is written, so items can
+// be matched however they are quoted in the file.
+func canonicalItem(text string) string {
+	var m map[string]any
+	if _, err := toml.Decode("v = "+text, &m); err != nil {
+		return text
+	}
+	if s, err := encodeValue(normalize(m["v"])); err == nil {
+		return s
+	}
+	return text
+}
+
+// withComma adds or removes the comma after the item ending at end.
+func withComma(line string, end int, comma bool) string {
+	rest := line[end:]
+	after := strings.TrimLeft(rest, " \t")
+	switch has := strings.HasPrefix(after, ","); {
+	case comma && !has:
+		return line[:end] + "," + rest
+	case !comma && has:
+		return line[:end] + rest[:len(rest)-len(after)] + after[1:]
+	}
+	return line
+}
+
+func isBlankOrComment(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "" || s[0] == '#'
+}
+
+// remove deletes key from table p. Removing a missing key is not an error.
+func (d *document) remove(p tablePath, key string) error {
+	entries, err := d.scan()
+	if err != nil {
+		return err
+	}
+	if i := findKey(entries, p, key); i >= 0 {
+		d.delete(entries[i].start, entries[i].end)
+	}
+	return nil
+}
+
+// ensureTable adds a header for p if the table does not exist yet.
+func (d *document) ensureTable(p tablePath) error {
+	if len(p) == 0 {
+		return nil
+	}
+
+	entries, err := d.scan()
+	if err != nil {
+		return err
+	}
+	if findHeader(entries, p) >= 0 {
+		return nil
+	}
+	if p.last().index >= 0 {
+		return fmt.Errorf("table %s not found", p)
+	}
+	if findKey(entries, p.parent(), p.last().name) >= 0 {
+		return fmt.Errorf("table %s is defined inline and cannot be edited", p)
+	}
+	for i := range entries {
+		if !entries[i].header && len(entries[i].key) > 1 && entries[i].key[0] == p.last().name && entries[i].table.equal(p.parent()) {
+			return fmt.Errorf("table %s is defined with dotted keys and cannot be edited", p)
+		}
+	}
+
+	if err := d.ensureTable(p.parent()); err != nil {
+		return err
+	}
+	if entries, err = d.scan(); err != nil {
+		return err
+	}
+
+	at, indent := d.tableInsertPoint(entries, p.parent())
+	d.insert(at, indent+"["+encodePath(p)+"]")
+	return nil
+}
+
+// tableInsertPoint returns where a new sub-table of parent is inserted and
+// the indentation its header gets.
+func (d *document) tableInsertPoint(entries []entry, parent tablePath) (at int, indent string) {
+	if len(parent) == 0 {
+		for i := range entries {
+			if entries[i].header && entries[i].array && len(entries[i].table) == 1 {
+				return d.attachedStart(entries[i].start), ""
+			}
+		}
+		return d.contentEnd(), ""
+	}
+
+	h := findHeader(entries, parent)
+	end := regionEnd(entries, h)
+	return entries[end-1].end, entries[h].indent + "  "
+}
+
+// appendArrayTable adds a new element to the array of tables p. The index
+// of p's last element must equal the current number of elements.
+func (d *document) appendArrayTable(p tablePath) error {
+	parent := p.parent()
+	if err := d.ensureTable(parent); err != nil {
+		return err
+	}
+
+	entries, err := d.scan()
+	if err != nil {
+		return err
+	}
+
+	name := p.last().name
+	lastElem := -1
+	for i := range entries {
+		t := entries[i].table
+		if entries[i].header && entries[i].array && len(t) == len(p) && t.hasPrefix(parent) && t.last().name == name {
+			lastElem = i
+		}
+	}
+
+	var at int
+	var indent string
+	if lastElem >= 0 {
+		if entries[lastElem].table.last().index != p.last().index-1 {
+			return fmt.Errorf("cannot append %s: unexpected index", p)
+		}
+		end := regionEnd(entries, lastElem)
+		at, indent = entries[end-1].end, entries[lastElem].indent
+	} else {
+		if p.last().index != 0 {
+			return fmt.Errorf("cannot append %s: unexpected index", p)
+		}
+		at, indent = d.contentEnd(), ""
+		if len(parent) > 0 {
+			at, indent = d.tableInsertPoint(entries, parent)
+		}
+	}
+
+	header := indent + "[[" + encodePath(p) + "]]"
+	if at > 0 && strings.TrimSpace(d.lines[at-1]) != "" {
+		d.insert(at, "", header)
+	} else {
+		d.insert(at, header)
+	}
+	return nil
+}
+
+// removeTable deletes the table p together with its sub-tables and the
+// blank lines that follow it.
+func (d *document) removeTable(p tablePath) error {
+	entries, err := d.scan()
+	if err != nil {
+		return err
+	}
+
+	h := findHeader(entries, p)
+	if h < 0 {
+		return fmt.Errorf("table %s not found", p)
+	}
+	end := entries[regionEnd(entries, h)-1].end
+	for end < d.contentEnd() && strings.TrimSpace(d.lines[end]) == "" {
+		end++
+	}
+
+	d.delete(entries[h].start, end)
+	return nil
+}
+
+// pruneTable deletes the header of table p if nothing is left in it.
+func (d *document) pruneTable(p tablePath) error {
+	entries, err := d.scan()
+	if err != nil {
+		return err
+	}
+	if h := findHeader(entries, p); h >= 0 && regionEnd(entries, h) == h+1 {
+		d.delete(entries[h].start, entries[h].end)
+	}
+	return nil
+}
+
+var bareKeyRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func encodeKey(key string) string {
+	if bareKeyRegex.MatchString(key) {
+		return key
+	}
+	return quoteString(key)
+}
+
+func encodePath(p tablePath) string {
+	parts := make([]string, len(p))
+	for i, e := range p {
+		parts[i] = encodeKey(e.name)
+	}
+	return strings.Join(parts, ".")
+}
+
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// encodeValue renders a normalized value (see normalize) as TOML.
+func encodeValue(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return quoteString(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		s := strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.ContainsAny(s, ".eEnN") {
+			s += ".0"
+		}
+		return s, nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case []any:
+		parts := make([]string, len(v))
+		for i := range v {
+			s, err := encodeValue(v[i])
+			if err != nil {
+				return "", err
+			}
+			parts[i] = s
+		}
+		return "[" + strings.Join(parts, ", ") + "]", nil
+	case map[string]any:
+		keys := sortedKeys(v)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			s, err := encodeValue(v[k])
+			if err != nil {
+				return "", err
+			}
+			parts[i] = encodeKey(k) + " = " + s
+		}
+		if len(parts) == 0 {
+			return "{}", nil
+		}
+		return "{ " + strings.Join(parts, ", ") + " }", nil
+	default:
+		return "", fmt.Errorf("unsupported value type %T", v)
+	}
+}
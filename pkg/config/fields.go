@@ -0,0 +1,308 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+// Editable reports whether the field can be edited as text or as a list of
+// entries. Tables, arrays of tables, times and maps of free-form values
+// cannot.
+func (f Field) Editable() bool {
+	switch f.Kind {
+	case KindString, KindInt, KindFloat, KindBool:
+		return true
+	case KindList:
+		return f.Type.Elem().Kind() == reflect.String
+	case KindMap:
+		elem := f.Type.Elem().Kind()
+		return f.Type.Key().Kind() == reflect.String && (elem == reflect.String || elem == reflect.Bool)
+	}
+	return false
+}
+
+// value returns the field inside root, a pointer to the struct the schema
+// was built from. It reports false when a table on the way is not set or
+// the field is inside an array of tables.
+func (f Field) value(root any) (reflect.Value, bool) {
+	v := reflect.ValueOf(root).Elem()
+	for i, idx := range f.Index {
+		v = v.Field(idx)
+		if i == len(f.Index)-1 {
+			break
+		}
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+	}
+	return v, true
+}
+
+// Text formats the field's value in root the way the editor shows it.
+// Unset values are "".
+func (f Field) Text(root any) string {
+	v, ok := f.value(root)
+	if !ok {
+		return ""
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case reflect.Struct:
+		if t, ok := v.Interface().(time.Time); ok && !t.IsZero() {
+			return t.Format(time.RFC3339)
+		}
+		return ""
+	}
+	return strings.Join(f.Entries(root), ", ")
+}
+
+// Entries returns the items of a list field, or the entries of a map field
+// as key=value sorted by key.
+func (f Field) Entries(root any) []string {
+	v, ok := f.value(root)
+	if !ok {
+		return nil
+	}
+	var entries []string
+	switch v.Kind() {
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			entries = append(entries, fmt.Sprint(v.Index(i).Interface()))
+		}
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			entries = append(entries, fmt.Sprintf("%v=%v", k.Interface(), v.MapIndex(k).Interface()))
+		}
+		sort.Strings(entries)
+	}
+	return entries
+}
+
+// SetText parses text as the field's kind, checks it and stores it in
+// root, creating the tables on the way. Empty text unsets optional
+// values. root is left unchanged on errors.
+func (f Field) SetText(root any, text string) error {
+	text = strings.TrimSpace(text)
+	value, err := parseScalar(f.Type, text)
+	if err != nil {
+		return fmt.Errorf("%s: %w", f.Key, err)
+	}
+	return f.set(root, value)
+}
+
+// SetEntries stores items in a list field, or key=value entries in a map
+// field. Empty entries are dropped.
+func (f Field) SetEntries(root any, entries []string) error {
+	if !f.Editable() || (f.Kind != KindList && f.Kind != KindMap) {
+		return fmt.Errorf("%s: not a list or map", f.Key)
+	}
+
+	value := reflect.Zero(f.Type)
+	switch f.Kind {
+	case KindList:
+		list := reflect.MakeSlice(f.Type, 0, len(entries))
+		for _, entry := range entries {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				list = reflect.Append(list, reflect.ValueOf(entry).Convert(f.Type.Elem()))
+			}
+		}
+		if list.Len() > 0 {
+			value = list
+		}
+	case KindMap:
+		m := reflect.MakeMap(f.Type)
+		for _, entry := range entries {
+			if strings.TrimSpace(entry) == "" {
+				continue
+			}
+			k, val, err := ParseMapEntry(f, entry)
+			if err != nil {
+				return err
+			}
+			m.SetMapIndex(k, val)
+		}
+		if m.Len() > 0 {
+			value = m
+		}
+	}
+	return f.set(root, value)
+}
+
+// ParseMapEntry parses a key=value entry of a map field.
+func ParseMapEntry(f Field, entry string) (key, value reflect.Value, err error) {
+	k, v, ok := strings.Cut(entry, "=")
+	k = strings.TrimSpace(k)
+	if !ok || k == "" {
+		return key, value, fmt.Errorf("%s: %q is not key=value", f.Key, entry)
+	}
+	value, err = parseScalar(f.Type.Elem(), strings.TrimSpace(v))
+	if err != nil {
+		return key, value, fmt.Errorf("%s: %s: %w", f.Key, k, err)
+	}
+	return reflect.ValueOf(k).Convert(f.Type.Key()), value, nil
+}
+
+// set checks value against the field's rule and stores it. Unset tables
+// are only created for non-zero values.
+func (f Field) set(root any, value reflect.Value) error {
+	if rule, ok := fieldRules[f.Key]; ok {
+		if err := rule(value.Interface()); err != nil {
+			return fmt.Errorf("%s: %w", f.Key, err)
+		}
+	}
+
+	v := reflect.ValueOf(root).Elem()
+	for i, idx := range f.Index {
+		v = v.Field(idx)
+		if i == len(f.Index)-1 {
+			break
+		}
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if value.IsZero() {
+					return nil
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return fmt.Errorf("%s: entries of arrays of tables cannot be set", f.Key)
+		}
+	}
+	v.Set(value)
+	return nil
+}
+
+// parseScalar parses text as a value of type t. Pointer types are nil for
+// empty text, other types their zero value.
+func parseScalar(t reflect.Type, text string) (reflect.Value, error) {
+	if t.Kind() == reflect.Ptr {
+		if text == "" {
+			return reflect.Zero(t), nil
+		}
+		elem, err := parseScalar(t.Elem(), text)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	}
+
+	value := reflect.New(t).Elem()
+	if text == "" {
+		return value, nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		value.SetString(text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return value, fmt.Errorf("%q is not true or false", text)
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, t.Bits())
+		if err != nil {
+			return value, fmt.Errorf("%q is not a whole number", text)
+		}
+		value.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(text, 10, t.Bits())
+		if err != nil {
+			return value, fmt.Errorf("%q is not a non-negative whole number", text)
+		}
+		value.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(text, t.Bits())
+		if err != nil {
+			return value, fmt.Errorf("%q is not a number", text)
+		}
+		value.SetFloat(n)
+	default:
+		return value, fmt.Errorf("%s values cannot be edited", kindOf(t))
+	}
+	return value, nil
+}
+
+// fieldRules check values of [[runners]] keys, relative to
+// runner.RunnerConfig, before they are stored.
+var fieldRules = map[string]func(value any) error{
+	"url":                    checkURL,
+	"clone_url":              checkURL,
+	"executor":               checkExecutor,
+	"limit":                  checkNonNegative,
+	"max_builds":             checkNonNegative,
+	"request_concurrency":    checkNonNegative,
+	"output_limit":           checkNonNegative,
+	"docker.pull_policy":     checkPullPolicy,
+	"kubernetes.pull_policy": checkPullPolicy,
+}
+
+func checkURL(value any) error {
+	s := value.(string)
+	if s == "" {
+		return nil
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not an http or https URL", s)
+	}
+	return nil
+}
+
+func checkExecutor(value any) error {
+	if s := value.(string); s != "" && !slices.Contains(runner.Executors, s) {
+		return fmt.Errorf("unknown executor %q", s)
+	}
+	return nil
+}
+
+func checkNonNegative(value any) error {
+	if value.(int) < 0 {
+		return fmt.Errorf("must be non-negative")
+	}
+	return nil
+}
+
+func checkPullPolicy(value any) error {
+	for _, policy := range value.(runner.StringList) {
+		switch policy {
+		case "always", "never", "if-not-present":
+		default:
+			return fmt.Errorf("unknown pull policy %q", policy)
+		}
+	}
+	return nil
+}
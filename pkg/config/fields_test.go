@@ -0,0 +1,143 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+func runnerField(t *testing.T, key string) Field {
+	t.Helper()
+	for _, f := range RunnerSchema() {
+		if f.Key == key {
+			return f
+		}
+	}
+	t.Fatalf("no runner key %q", key)
+	return Field{}
+}
+
+func TestField_SetText(t *testing.T) {
+	r := &runner.RunnerConfig{Executor: "shell"}
+
+	if err := runnerField(t, "docker.image").SetText(r, ""); err != nil || r.Docker != nil {
+		t.Fatalf("expected empty value to leave [runners.docker] unset, got %v %+v", err, r.Docker)
+	}
+	if err := runnerField(t, "docker.image").SetText(r, " alpine:3.20 "); err != nil {
+		t.Fatal(err)
+	}
+	if r.Docker == nil || r.Docker.Image != "alpine:3.20" {
+		t.Fatalf("expected [runners.docker] to be created, got %+v", r.Docker)
+	}
+
+	if err := runnerField(t, "docker.shm_size").SetText(r, "268435456"); err != nil || r.Docker.ShmSize != 268435456 {
+		t.Errorf("expected int64 value, got %v %d", err, r.Docker.ShmSize)
+	}
+	if err := runnerField(t, "docker.privileged").SetText(r, "true"); err != nil || !r.Docker.Privileged {
+		t.Errorf("expected bool value, got %v", err)
+	}
+	if got := runnerField(t, "docker.privileged").Text(r); got != "true" {
+		t.Errorf("Text = %q, expected true", got)
+	}
+
+	if err := runnerField(t, "kubernetes.allow_privilege_escalation").SetText(r, "false"); err != nil {
+		t.Fatal(err)
+	}
+	if r.Kubernetes.AllowPrivilegeEscalation == nil || *r.Kubernetes.AllowPrivilegeEscalation {
+		t.Errorf("expected optional bool set to false, got %v", r.Kubernetes.AllowPrivilegeEscalation)
+	}
+	if err := runnerField(t, "kubernetes.allow_privilege_escalation").SetText(r, ""); err != nil || r.Kubernetes.AllowPrivilegeEscalation != nil {
+		t.Errorf("expected empty value to unset the optional bool, got %v", err)
+	}
+}
+
+func TestField_SetTextErrors(t *testing.T) {
+	tests := []struct {
+		key, text, err string
+	}{
+		{"limit", "four", `limit: "four" is not a whole number`},
+		{"limit", "-1", "limit: must be non-negative"},
+		{"docker.privileged", "maybe", `"maybe" is not true or false`},
+		{"url", "gitlab.example.com", "not an http or https URL"},
+		{"executor", "docker-ssh", `unknown executor "docker-ssh"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.text, func(t *testing.T) {
+			r := &runner.RunnerConfig{Limit: 2, URL: "https://gitlab.example.com", Executor: "shell"}
+			before := *r
+			err := runnerField(t, tt.key).SetText(r, tt.text)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error containing %q, got %v", tt.err, err)
+			}
+			if !reflect.DeepEqual(*r, before) {
+				t.Errorf("runner changed on error: %+v", r)
+			}
+		})
+	}
+}
+
+func TestField_SetEntries(t *testing.T) {
+	r := &runner.RunnerConfig{Docker: &runner.DockerConfig{Image: "alpine"}}
+
+	volumes := runnerField(t, "docker.volumes")
+	if err := volumes.SetEntries(r, []string{"/cache", " ", "/var/run/docker.sock:/var/run/docker.sock"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r.Docker.Volumes, []string{"/cache", "/var/run/docker.sock:/var/run/docker.sock"}) {
+		t.Errorf("unexpected volumes %v", r.Docker.Volumes)
+	}
+	if err := volumes.SetEntries(r, nil); err != nil || r.Docker.Volumes != nil {
+		t.Errorf("expected no entries to remove the key, got %v %v", err, r.Docker.Volumes)
+	}
+
+	pullPolicy := runnerField(t, "docker.pull_policy")
+	if err := pullPolicy.SetEntries(r, []string{"if-not-present", "always"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pullPolicy.SetEntries(r, []string{"sometimes"}); err == nil {
+		t.Error("expected unknown pull policy to be rejected")
+	}
+	if !reflect.DeepEqual(r.Docker.PullPolicy, runner.StringList{"if-not-present", "always"}) {
+		t.Errorf("unexpected pull policy %v", r.Docker.PullPolicy)
+	}
+
+	nodeSelector := runnerField(t, "kubernetes.node_selector")
+	if err := nodeSelector.SetEntries(r, []string{"kubernetes.io/os=linux", "disk = ssd"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := nodeSelector.Entries(r); !reflect.DeepEqual(got, []string{"disk=ssd", "kubernetes.io/os=linux"}) {
+		t.Errorf("Entries = %v", got)
+	}
+	if err := nodeSelector.SetEntries(r, []string{"=linux"}); err == nil {
+		t.Error("expected entry without a key to be rejected")
+	}
+
+	flags := runnerField(t, "feature_flags")
+	if err := flags.SetEntries(r, []string{"FF_USE_FASTZIP=yes"}); err == nil {
+		t.Error("expected non-bool feature flag to be rejected")
+	}
+	if err := flags.SetEntries(r, []string{"FF_USE_FASTZIP=true"}); err != nil || !r.FeatureFlags["FF_USE_FASTZIP"] {
+		t.Errorf("expected feature flag set, got %v %v", err, r.FeatureFlags)
+	}
+}
+
+func TestField_Editable(t *testing.T) {
+	for key, editable := range map[string]bool{
+		"name":                            true,
+		"tag_list":                        true,
+		"feature_flags":                   true,
+		"docker.services":                 false,
+		"kubernetes.pod_annotations":      true,
+		"kubernetes.affinity":             false,
+		"machine.autoscaling":             false,
+		"token_obtained_at":               false,
+		"kubernetes.pod_security_context": false,
+	} {
+		if got := runnerField(t, key).Editable(); got != editable {
+			t.Errorf("%s: Editable() = %v, expected %v", key, got, editable)
+		}
+	}
+}
@@ -0,0 +1,388 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// toMap converts a config struct into the generic form a TOML decoder
+// produces, so it can be compared against and merged into a document.
+// Empty tables, nil slices and omitempty zero values are left out.
+func toMap(v any) map[string]any {
+	m, _ := valueToAny(reflect.ValueOf(v))
+	tbl, _ := m.(map[string]any)
+	if tbl == nil {
+		tbl = map[string]any{}
+	}
+	return tbl
+}
+
+func structToMap(v reflect.Value) map[string]any {
+	m := make(map[string]any)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, omitEmpty := fieldKey(f)
+		if name == "" {
+			continue
+		}
+		fv := v.Field(i)
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+		if val, ok := valueToAny(fv); ok {
+			m[name] = val
+		}
+	}
+	return m
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func valueToAny(v reflect.Value) (any, bool) {
+	if v.IsValid() && v.Type() == timeType {
+		return v.Interface(), true
+	}
+
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil, false
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, false
+		}
+		return valueToAny(v.Elem())
+	case reflect.Struct:
+		m := structToMap(v)
+		return m, len(m) > 0
+	case reflect.Map:
+		if v.IsNil() || v.Len() == 0 {
+			return nil, false
+		}
+		m := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			if val, ok := valueToAny(iter.Value()); ok {
+				m[fmt.Sprint(iter.Key().Interface())] = val
+			}
+		}
+		return m, len(m) > 0
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, false
+		}
+		return sliceToAny(v), true
+	case reflect.String:
+		return v.String(), true
+	case reflect.Bool:
+		return v.Bool(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), true // #nosec G115 -- config values fit in int64
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return v.Interface(), true
+	}
+}
+
+// sliceToAny turns slices of structs or maps into arrays of tables and
+// everything else into plain arrays.
+func sliceToAny(v reflect.Value) any {
+	elem := v.Type().Elem()
+	for elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+
+	if elem.Kind() == reflect.Struct || elem.Kind() == reflect.Map {
+		tables := make([]map[string]any, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			val, _ := valueToAny(v.Index(i))
+			m, _ := val.(map[string]any)
+			if m == nil {
+				m = map[string]any{}
+			}
+			tables = append(tables, m)
+		}
+		return tables
+	}
+
+	values := make([]any, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		if val, ok := valueToAny(v.Index(i)); ok {
+			values = append(values, val)
+		}
+	}
+	return values
+}
+
+// normalize brings a value decoded by the toml package into the same form
+// toMap produces.
+func normalize(v any) any {
+	val, ok := valueToAny(reflect.ValueOf(v))
+	if !ok {
+		switch reflect.ValueOf(v).Kind() {
+		case reflect.Map:
+			return map[string]any{}
+		case reflect.Slice:
+			return []any{}
+		}
+	}
+	return val
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type opKind int
+
+const (
+	opSet opKind = iota
+	opRemove
+	opAppendTable
+	opRemoveTable
+	opPruneTable
+)
+
+// editOp is a single change to a TOML document.
+type editOp struct {
+	kind  opKind
+	table tablePath
+	key   string
+	value any
+}
+
+// diffTables records the edits that turn the modeled keys in old into new.
+// Keys that appear in neither are not touched, which is what keeps settings
+// the config types do not model intact.
+func diffTables(p tablePath, old, new map[string]any, ops *[]editOp) {
+	keys := sortedKeys(old)
+	for k := range new {
+		if _, ok := old[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		o, n := old[k], new[k]
+
+		switch nv := n.(type) {
+		case map[string]any:
+			ov, _ := o.(map[string]any)
+			diffTables(p.child(k, -1), ov, nv, ops)
+			continue
+		case []map[string]any:
+			ov, _ := o.([]map[string]any)
+			diffArrayTables(p, k, ov, nv, ops)
+			continue
+		}
+
+		switch ov := o.(type) {
+		case map[string]any:
+			diffTables(p.child(k, -1), ov, nil, ops)
+			*ops = append(*ops, editOp{kind: opPruneTable, table: p.child(k, -1)})
+			if n == nil {
+				continue
+			}
+		case []map[string]any:
+			diffArrayTables(p, k, ov, nil, ops)
+			if n == nil {
+				continue
+			}
+		}
+
+		if n == nil {
+			*ops = append(*ops, editOp{kind: opRemove, table: p, key: k})
+		} else if !reflect.DeepEqual(o, n) {
+			*ops = append(*ops, editOp{kind: opSet, table: p, key: k, value: n})
+		}
+	}
+}
+
+// diffArrayTables pairs up old and new elements of an array of tables.
+// Runners are matched by token (or name) so removing one from the middle
+// does not shift the unmodeled settings of the others; anything else is
+// matched by position.
+func diffArrayTables(p tablePath, key string, old, new []map[string]any, ops *[]editOp) {
+	matched := make([]bool, len(old))
+	pairs := make([]int, len(new))
+
+	for j := range new {
+		pairs[j] = -1
+		if len(p) == 0 && key == "runners" {
+			pairs[j] = matchRunner(old, matched, new[j])
+		} else if j < len(old) {
+			pairs[j] = j
+		}
+		if pairs[j] >= 0 {
+			matched[pairs[j]] = true
+		}
+	}
+
+	for j, i := range pairs {
+		if i >= 0 {
+			diffTables(p.child(key, i), old[i], new[j], ops)
+		}
+	}
+
+	count := len(old)
+	for i := len(old) - 1; i >= 0; i-- {
+		if !matched[i] {
+			*ops = append(*ops, editOp{kind: opRemoveTable, table: p.child(key, i)})
+			count--
+		}
+	}
+
+	for j, i := range pairs {
+		if i < 0 {
+			elem := p.child(key, count)
+			*ops = append(*ops, editOp{kind: opAppendTable, table: elem})
+			diffTables(elem, nil, new[j], ops)
+			count++
+		}
+	}
+}
+
+func matchRunner(old []map[string]any, matched []bool, r map[string]any) int {
+	for _, field := range []string{"token", "name"} {
+		want, _ := r[field].(string)
+		if want == "" {
+			continue
+		}
+		for i := range old {
+			if got, _ := old[i][field].(string); !matched[i] && got == want {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func (d *document) apply(op *editOp) error {
+	switch op.kind {
+	case opSet:
+		value, err := encodeValue(op.value)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", op.table, op.key, err)
+		}
+		if items, ok := op.value.([]any); ok {
+			return d.setArray(op.table, op.key, value, items)
+		}
+		return d.set(op.table, op.key, value)
+	case opRemove:
+		return d.remove(op.table, op.key)
+	case opAppendTable:
+		return d.appendArrayTable(op.table)
+	case opRemoveTable:
+		return d.removeTable(op.table)
+	case opPruneTable:
+		return d.pruneTable(op.table)
+	}
+	return fmt.Errorf("unknown edit operation %d", op.kind)
+}
+
+// applyToMap performs op on a decoded document so the expected result of
+// an edit can be checked against what the edited file actually decodes to.
+func applyToMap(root map[string]any, op *editOp) {
+	p := op.table
+	if op.kind != opSet && op.kind != opRemove {
+		p = p.parent()
+	}
+
+	m := root
+	for _, e := range p {
+		if e.index >= 0 {
+			m = m[e.name].([]map[string]any)[e.index]
+			continue
+		}
+		next, ok := m[e.name].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			m[e.name] = next
+		}
+		m = next
+	}
+
+	switch op.kind {
+	case opSet:
+		m[op.key] = op.value
+	case opRemove:
+		delete(m, op.key)
+	case opAppendTable:
+		name := op.table.last().name
+		tables, _ := m[name].([]map[string]any)
+		m[name] = append(tables, map[string]any{})
+	case opRemoveTable:
+		last := op.table.last()
+		tables := m[last.name].([]map[string]any)
+		m[last.name] = append(tables[:last.index:last.index], tables[last.index+1:]...)
+	case opPruneTable:
+		name := op.table.last().name
+		if t, ok := m[name].(map[string]any); ok && len(t) == 0 {
+			delete(m, name)
+		}
+	}
+}
+
+func decodeGeneric(data []byte) (map[string]any, error) {
+	var raw map[string]any
+	if _, err := toml.Decode(string(data), &raw); err != nil {
+		return nil, err
+	}
+	m, _ := normalize(raw).(map[string]any)
+	if m == nil {
+		m = map[string]any{}
+	}
+	return m, nil
+}
+
+// mergeDocument applies the difference between base and current to the
+// TOML document in data and returns the edited document. The result is
+// decoded again and compared with the expected content, so an edit that
+// would corrupt the file is refused instead of written.
+func mergeDocument(data []byte, base, current map[string]any) ([]byte, error) {
+	doc, err := parseDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config for editing: %w", err)
+	}
+
+	expected, err := decodeGeneric(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse TOML config: %w", err)
+	}
+
+	var ops []editOp
+	diffTables(nil, base, current, &ops)
+	for i := range ops {
+		if err := doc.apply(&ops[i]); err != nil {
+			return nil, fmt.Errorf("failed to edit config: %w", err)
+		}
+		applyToMap(expected, &ops[i])
+	}
+
+	out := doc.Bytes()
+	actual, err := decodeGeneric(out)
+	if err != nil {
+		return nil, fmt.Errorf("edited config is not valid TOML: %w", err)
+	}
+	if !reflect.DeepEqual(normalize(expected), actual) {
+		return nil, fmt.Errorf("edited config does not match the requested changes")
+	}
+
+	return out, nil
+}
@@ -0,0 +1,317 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+const productionConfig = `# Managed by hand - do not regenerate
+concurrent = 4
+check_interval = 3
+listen_address = ":9252"
+shutdown_timeout = 30
+
+[session_server]
+  session_timeout = 1800
+
+[[runners]]
+  name = "docker-1"   # primary
+  url = "https://gitlab.example.com"
+  token = "glrt-aaaaaaaa"
+  executor = "docker"
+  [runners.custom_build_dir]
+  [runners.cache]
+    Type = "s3"
+    Shared = true
+    [runners.cache.s3]
+      ServerAddress = "s3.amazonaws.com"
+      BucketName = "runner-cache"
+  [runners.feature_flags]
+    FF_USE_FASTZIP = true
+  [runners.docker]
+    image = "alpine:latest"
+    volumes = [
+      "/cache",   # shared cache
+      "/var/run/docker.sock:/var/run/docker.sock",
+    ]
+
+[[runners]]
+  name = "shell-1"
+  url = "https://gitlab.example.com"
+  token = "glrt-bbbbbbbb"
+  executor = "custom"
+  [runners.custom]
+    run_exec = "/opt/run.sh"
+`
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func loadConfig(t *testing.T, content string) *TOMLConfigManager {
+	t.Helper()
+	cm := NewTOMLConfigManager(writeConfig(t, content))
+	if err := cm.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	return cm
+}
+
+func saveAndRead(t *testing.T, cm *TOMLConfigManager) string {
+	t.Helper()
+	if err := cm.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	data, err := os.ReadFile(cm.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSave_UnchangedConfigIsByteForByte(t *testing.T) {
+	cm := loadConfig(t, productionConfig)
+
+	if got := saveAndRead(t, cm); got != productionConfig {
+		t.Errorf("unchanged save modified the file:\n%s", got)
+	}
+}
+
+func TestSave_OnlyChangedKeysAreTouched(t *testing.T) {
+	cm := loadConfig(t, productionConfig)
+
+	if err := cm.UpdateConcurrency(8); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.UpdateRunnerLimit("shell-1", 2); err != nil {
+		t.Fatal(err)
+	}
+	cm.GetConfig().Runners[0].Docker.Image = "ubuntu:24.04"
+
+	expected := strings.NewReplacer(
+		"concurrent = 4", "concurrent = 8",
+		`image = "alpine:latest"`, `image = "ubuntu:24.04"`,
+		"  executor = \"custom\"\n", "  executor = \"custom\"\n  limit = 2\n",
+	).Replace(productionConfig)
+
+	if got := saveAndRead(t, cm); got != expected {
+		t.Errorf("unexpected result:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestSave_RemoveKeyKeepsNeighbours(t *testing.T) {
+	cm := loadConfig(t, productionConfig)
+
+	cm.GetConfig().Runners[0].Docker.Volumes = nil
+
+	got := saveAndRead(t, cm)
+	if strings.Contains(got, "volumes") || strings.Contains(got, "docker.sock") {
+		t.Errorf("volumes were not removed:\n%s", got)
+	}
+	for _, keep := range []string{"FF_USE_FASTZIP = true", "BucketName = \"runner-cache\"", `image = "alpine:latest"`} {
+		if !strings.Contains(got, keep) {
+			t.Errorf("expected %q to be kept:\n%s", keep, got)
+		}
+	}
+}
+
+func TestSave_MultiLineArrayKeepsLayout(t *testing.T) {
+	cm := loadConfig(t, productionConfig)
+
+	cm.GetConfig().Runners[0].Docker.Volumes = []string{"/cache", "/builds"}
+
+	expected := strings.Replace(productionConfig,
+		"      \"/var/run/docker.sock:/var/run/docker.sock\",\n",
+		"      \"/builds\",\n", 1)
+	got := saveAndRead(t, cm)
+	if got != expected {
+		t.Errorf("unexpected result:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	// Saving what was written again leaves it as it is
+	reloaded := loadConfig(t, got)
+	if again := saveAndRead(t, reloaded); again != got {
+		t.Errorf("round trip modified the file:\n%s", again)
+	}
+}
+
+func TestSave_MultiLineArrayWithoutTrailingComma(t *testing.T) {
+	cm := loadConfig(t, `[[runners]]
+  name = "a"
+  token = "t"
+  [runners.docker]
+    volumes = [
+      # kept for the cache
+      '/cache',
+      "/tmp"
+    ]
+`)
+
+	cm.GetConfig().Runners[0].Docker.Volumes = []string{"/srv", "/cache"}
+
+	expected := `[[runners]]
+  name = "a"
+  token = "t"
+  [runners.docker]
+    volumes = [
+      "/srv",
+      # kept for the cache
+      '/cache'
+    ]
+`
+	if got := saveAndRead(t, cm); got != expected {
+		t.Errorf("unexpected result:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestSave_RefusesArrayItemsSharingALine(t *testing.T) {
+	cm := loadConfig(t, `[[runners]]
+  name = "a"
+  token = "t"
+  [runners.docker]
+    volumes = [
+      "/cache", "/tmp",
+    ]
+`)
+
+	cm.GetConfig().Runners[0].Docker.Volumes = []string{"/cache"}
+	if err := cm.Save(); err == nil {
+		t.Error("expected editing an array with items sharing a line to fail")
+	}
+}
+
+func TestSave_AddAndRemoveRunners(t *testing.T) {
+	cm := loadConfig(t, productionConfig)
+	config := cm.GetConfig()
+
+	config.Runners = append(config.Runners[1:], runner.RunnerConfig{
+		Name:     "k8s-1",
+		URL:      "https://gitlab.example.com",
+		Token:    "glrt-cccccccc",
+		Executor: "kubernetes",
+		Kubernetes: &runner.KubernetesConfig{
+			Image:     "alpine:latest",
+			Namespace: "ci",
+		},
+	})
+
+	got := saveAndRead(t, cm)
+	if strings.Contains(got, "docker-1") || strings.Contains(got, "FF_USE_FASTZIP") {
+		t.Errorf("first runner was not removed completely:\n%s", got)
+	}
+	if !strings.Contains(got, "run_exec = \"/opt/run.sh\"") {
+		t.Errorf("unmodeled settings of the remaining runner were lost:\n%s", got)
+	}
+	if !strings.Contains(got, "\n[[runners]]\n  executor = \"kubernetes\"") ||
+		!strings.Contains(got, "  [runners.kubernetes]\n    image = \"alpine:latest\"\n    namespace = \"ci\"\n") {
+		t.Errorf("new runner not appended as expected:\n%s", got)
+	}
+
+	cm2 := NewTOMLConfigManager(cm.path)
+	if err := cm2.Load(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	runners := cm2.GetConfig().Runners
+	if len(runners) != 2 || runners[0].Name != "shell-1" || runners[1].Name != "k8s-1" {
+		t.Errorf("unexpected runners after reload: %+v", runners)
+	}
+	if runners[1].Kubernetes == nil || runners[1].Kubernetes.Namespace != "ci" {
+		t.Errorf("kubernetes section not saved: %+v", runners[1].Kubernetes)
+	}
+}
+
+func TestSave_CreatesMissingTables(t *testing.T) {
+	cm := loadConfig(t, productionConfig)
+
+	cm.GetConfig().Runners[1].Docker = &runner.DockerConfig{Image: "busybox"}
+
+	got := saveAndRead(t, cm)
+	if !strings.Contains(got, "  [runners.custom]\n    run_exec = \"/opt/run.sh\"\n  [runners.docker]\n    image = \"busybox\"\n") {
+		t.Errorf("docker table not added to the second runner:\n%s", got)
+	}
+}
+
+func TestSave_RefusesInlineTables(t *testing.T) {
+	cm := loadConfig(t, `concurrent = 1
+
+[[runners]]
+  name = "inline"
+  url = "https://gitlab.example.com"
+  token = "t"
+  executor = "docker"
+  docker = { image = "alpine" }
+`)
+
+	cm.GetConfig().Runners[0].Docker.Privileged = true
+	if err := cm.Save(); err == nil {
+		t.Error("expected editing an inline table to fail")
+	}
+}
+
+func TestSave_PreservesCRLF(t *testing.T) {
+	content := "concurrent = 1\r\n\r\n[[runners]]\r\n  name = \"a\"\r\n  token = \"t\"\r\n"
+	cm := loadConfig(t, content)
+
+	if err := cm.UpdateRunnerLimit("a", 3); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := content + "  limit = 3\r\n"
+	if got := saveAndRead(t, cm); got != expected {
+		t.Errorf("unexpected result %q, expected %q", got, expected)
+	}
+}
+
+func TestSave_WithoutLoadedFile(t *testing.T) {
+	cm := NewTOMLConfigManager(filepath.Join(t.TempDir(), "config.toml"))
+	cm.config = &runner.Config{
+		Concurrent: 2,
+		Runners:    []runner.RunnerConfig{{Name: "new", URL: "https://gitlab.com", Token: "t", Executor: "shell"}},
+	}
+
+	got := saveAndRead(t, cm)
+	if !strings.Contains(got, "concurrent = 2\n") || !strings.Contains(got, "\n[[runners]]\n  executor = \"shell\"\n") {
+		t.Errorf("unexpected result:\n%s", got)
+	}
+}
+
+func TestParseDocument_Errors(t *testing.T) {
+	for _, input := range []string{
+		"key = \"unterminated\n",
+		"[table\n",
+		"key\n",
+		"list = [1, 2\n",
+	} {
+		if _, err := parseDocument([]byte(input)); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
+
+func TestRemoveRunner(t *testing.T) {
+	cm := loadConfig(t, productionConfig)
+
+	if err := cm.RemoveRunner("missing"); err == nil {
+		t.Error("expected error for unknown runner")
+	}
+	if err := cm.RemoveRunner("docker-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	got := saveAndRead(t, cm)
+	if strings.Contains(got, "docker-1") || strings.Contains(got, "runner-cache") {
+		t.Errorf("runner not removed:\n%s", got)
+	}
+	if !strings.HasPrefix(got, "# Managed by hand") || !strings.Contains(got, `name = "shell-1"`) {
+		t.Errorf("unrelated content changed:\n%s", got)
+	}
+}
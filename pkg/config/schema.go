@@ -0,0 +1,175 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+// FieldKind is the shape of a config.toml value.
+type FieldKind int
+
+const (
+	KindString FieldKind = iota
+	KindInt
+	KindFloat
+	KindBool
+	KindTime
+	KindList
+	KindMap
+	KindTable
+	KindTableList
+	KindAny
+)
+
+func (k FieldKind) String() string {
+	switch k {
+	case KindString:
+		return "string"
+	case KindInt:
+		return "int"
+	case KindFloat:
+		return "float"
+	case KindBool:
+		return "bool"
+	case KindTime:
+		return "time"
+	case KindList:
+		return "list"
+	case KindMap:
+		return "map"
+	case KindTable:
+		return "table"
+	case KindTableList:
+		return "table list"
+	default:
+		return "any"
+	}
+}
+
+// Field describes one config.toml key and the struct field it maps to.
+type Field struct {
+	// Key is the dotted path of the key, e.g. "runners.docker.image".
+	// Elements of arrays of tables are not indexed.
+	Key string
+	// Name is the key inside its table, e.g. "image".
+	Name string
+	Kind FieldKind
+	// Index is the reflect field index path from the root type.
+	Index     []int
+	Type      reflect.Type
+	OmitEmpty bool
+}
+
+var (
+	configSchema = buildSchema(reflect.TypeOf(runner.Config{}), "")
+	runnerSchema = buildSchema(reflect.TypeOf(runner.RunnerConfig{}), "")
+)
+
+// Schema lists every key of config.toml modeled by runner.Config, tables
+// before the keys they contain.
+func Schema() []Field {
+	return configSchema
+}
+
+// RunnerSchema lists the keys of a single [[runners]] entry, relative to
+// runner.RunnerConfig.
+func RunnerSchema() []Field {
+	return runnerSchema
+}
+
+// Lookup finds a key of config.toml by its dotted path.
+func Lookup(key string) (Field, bool) {
+	for _, f := range configSchema {
+		if f.Key == key {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// fieldKey returns the config.toml key of a struct field and whether zero
+// values are left out when the file is written.
+func fieldKey(f reflect.StructField) (name string, omitEmpty bool) {
+	tag := f.Tag.Get("toml")
+	if tag == "" || tag == "-" {
+		return "", false
+	}
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return parts[0], omitEmpty
+}
+
+func buildSchema(t reflect.Type, prefix string) []Field {
+	var fields []Field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, omitEmpty := fieldKey(sf)
+		if !sf.IsExported() || name == "" {
+			continue
+		}
+
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		field := Field{
+			Key:       key,
+			Name:      name,
+			Kind:      kindOf(sf.Type),
+			Index:     []int{i},
+			Type:      sf.Type,
+			OmitEmpty: omitEmpty,
+		}
+		fields = append(fields, field)
+
+		if field.Kind != KindTable && field.Kind != KindTableList {
+			continue
+		}
+		elem := sf.Type
+		for elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Slice {
+			elem = elem.Elem()
+		}
+		for _, sub := range buildSchema(elem, key) {
+			sub.Index = append([]int{i}, sub.Index...)
+			fields = append(fields, sub)
+		}
+	}
+	return fields
+}
+
+func kindOf(t reflect.Type) FieldKind {
+	if t == reflect.TypeOf(time.Time{}) {
+		return KindTime
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return kindOf(t.Elem())
+	case reflect.String:
+		return KindString
+	case reflect.Bool:
+		return KindBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return KindInt
+	case reflect.Float32, reflect.Float64:
+		return KindFloat
+	case reflect.Struct:
+		return KindTable
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Struct {
+			return KindTableList
+		}
+		return KindList
+	case reflect.Map:
+		return KindMap
+	default:
+		return KindAny
+	}
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+var fixtures = []string{"docker.toml", "kubernetes.toml", "docker-machine.toml", "shell-custom.toml"}
+
+func loadFixture(t *testing.T, name string) *TOMLConfigManager {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loadConfig(t, string(data))
+}
+
+func TestFixtures_EveryKeyIsMapped(t *testing.T) {
+	for _, name := range fixtures {
+		t.Run(name, func(t *testing.T) {
+			var config runner.Config
+			md, err := toml.DecodeFile(filepath.Join("testdata", name), &config)
+			if err != nil {
+				t.Fatalf("decode failed: %v", err)
+			}
+			for _, key := range md.Undecoded() {
+				if !capturedGenerically(key.String()) {
+					t.Errorf("key %q not mapped to runner.Config", key)
+				}
+			}
+		})
+	}
+}
+
+// capturedGenerically reports whether key lives below a field decoded into
+// a map[string]interface{}, which the toml package does not mark as decoded.
+func capturedGenerically(key string) bool {
+	generic := reflect.TypeOf(map[string]interface{}{})
+	for _, f := range Schema() {
+		if f.Type == generic && strings.HasPrefix(key, f.Key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func TestFixtures_UnchangedRoundTrip(t *testing.T) {
+	for _, name := range fixtures {
+		t.Run(name, func(t *testing.T) {
+			cm := loadFixture(t, name)
+			original := string(cm.raw)
+
+			if got := saveAndRead(t, cm); got != original {
+				t.Errorf("unchanged save modified the file:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestFixtures_ModifiedRoundTrip(t *testing.T) {
+	for _, name := range fixtures {
+		t.Run(name, func(t *testing.T) {
+			cm := loadFixture(t, name)
+			config := cm.GetConfig()
+
+			config.Concurrent++
+			config.CheckInterval = 7
+			config.LogLevel = "error"
+			for i := range config.Runners {
+				r := &config.Runners[i]
+				r.Limit += 2
+				r.TagList = append(r.TagList, "added")
+				r.OutputLimit = 8192
+				r.Environment = nil
+			}
+
+			saveAndRead(t, cm)
+
+			reloaded := NewTOMLConfigManager(cm.path)
+			if err := reloaded.Load(); err != nil {
+				t.Fatalf("reload failed: %v", err)
+			}
+			if !reflect.DeepEqual(reloaded.GetConfig(), config) {
+				t.Errorf("reloaded config differs:\n got: %+v\nwant: %+v", reloaded.GetConfig(), config)
+			}
+		})
+	}
+}
+
+func TestFixtures_Values(t *testing.T) {
+	docker := loadFixture(t, "docker.toml").GetConfig()
+	if docker.CheckInterval != 3 || docker.LogLevel != "warning" || docker.LogFormat != "runner" {
+		t.Errorf("global settings not loaded: %+v", docker)
+	}
+	if docker.ListenAddress != ":9252" || docker.ShutdownTimeout != 30 || docker.SessionServer.SessionTimeout != 1800 {
+		t.Errorf("server settings not loaded: %+v", docker)
+	}
+
+	r := docker.Runners[0]
+	if r.ID != 42 || r.TokenObtainedAt.IsZero() || r.TLSCAFile == "" {
+		t.Errorf("runner identity not loaded: %+v", r)
+	}
+	if r.RequestConcurrency != 2 || r.OutputLimit != 16384 || r.MaxBuilds != 100 || !r.RunUntagged {
+		t.Errorf("runner limits not loaded: %+v", r)
+	}
+	if !reflect.DeepEqual(r.TagList, []string{"docker", "linux"}) {
+		t.Errorf("TagList = %v", r.TagList)
+	}
+	if !r.FeatureFlags["FF_USE_FASTZIP"] || r.CustomBuildDir == nil || !r.CustomBuildDir.Enabled {
+		t.Errorf("feature flags/custom_build_dir not loaded: %+v", r)
+	}
+	if r.Cache == nil || r.Cache.S3 == nil || r.Cache.S3.BucketName != "runner-cache" {
+		t.Errorf("cache not loaded: %+v", r.Cache)
+	}
+	if d := r.Docker; d == nil || !reflect.DeepEqual(d.PullPolicy, runner.StringList{"if-not-present"}) ||
+		len(d.Services) != 2 || d.Services[0].Alias != "db" || d.SysCtls["net.ipv4.ip_forward"] != "1" {
+		t.Errorf("docker section not loaded: %+v", r.Docker)
+	}
+
+	k8s := loadFixture(t, "kubernetes.toml").GetConfig().Runners[0].Kubernetes
+	if k8s == nil || k8s.CPULimit != "2" || k8s.MemoryRequest != "1Gi" || k8s.NodeSelector["kubernetes.io/arch"] != "amd64" {
+		t.Errorf("kubernetes section not loaded: %+v", k8s)
+	}
+	if len(k8s.PullPolicy) != 2 || k8s.Volumes["host_path"] == nil {
+		t.Errorf("kubernetes pull_policy/volumes not loaded: %+v", k8s)
+	}
+
+	machine := loadFixture(t, "docker-machine.toml").GetConfig().Runners[0].Machine
+	if machine == nil || machine.IdleScaleFactor != 0.5 || len(machine.Autoscaling) != 2 || machine.Autoscaling[0].Timezone != "Europe/Berlin" {
+		t.Errorf("machine section not loaded: %+v", machine)
+	}
+
+	shell := loadFixture(t, "shell-custom.toml").GetConfig().Runners
+	if shell[1].Custom == nil || shell[1].Custom.RunExec != "/opt/libvirt-driver/run.sh" {
+		t.Errorf("custom section not loaded: %+v", shell[1].Custom)
+	}
+	if shell[2].SSH == nil || shell[2].SSH.DisableStrictHostKeyChecking == nil || *shell[2].SSH.DisableStrictHostKeyChecking {
+		t.Errorf("ssh section not loaded: %+v", shell[2].SSH)
+	}
+}
+
+func TestSchema_DocumentedKeys(t *testing.T) {
+	keys := []string{
+		"concurrent", "log_level", "log_format", "check_interval", "sentry_dsn", "connection_max_age",
+		"listen_address", "shutdown_timeout", "session_server.listen_address", "session_server.session_timeout",
+		"runners.name", "runners.url", "runners.id", "runners.token", "runners.token_obtained_at",
+		"runners.tls-ca-file", "runners.limit", "runners.executor", "runners.shell", "runners.builds_dir",
+		"runners.environment", "runners.request_concurrency", "runners.output_limit",
+		"runners.pre_get_sources_script", "runners.post_build_script", "runners.unhealthy_requests_limit",
+		"runners.feature_flags", "runners.custom_build_dir.enabled", "runners.cache.Type",
+		"runners.cache.s3.BucketName", "runners.cache.gcs.CredentialsFile", "runners.cache.azure.AccountName",
+		"runners.docker.image", "runners.docker.pull_policy", "runners.docker.services.alias",
+		"runners.docker.volumes", "runners.docker.cap_add", "runners.docker.memory", "runners.docker.cpus",
+		"runners.kubernetes.namespace", "runners.kubernetes.cpu_limit", "runners.kubernetes.node_selector",
+		"runners.kubernetes.volumes", "runners.machine.IdleCount", "runners.machine.autoscaling.Periods",
+		"runners.custom.run_exec", "runners.ssh.identity_file", "runners.virtualbox.base_name",
+		"runners.parallels.base_name",
+	}
+
+	for _, key := range keys {
+		if _, ok := Lookup(key); !ok {
+			t.Errorf("key %q missing from schema", key)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, f := range Schema() {
+		if seen[f.Key] {
+			t.Errorf("duplicate schema key %q", f.Key)
+		}
+		seen[f.Key] = true
+	}
+
+	f, _ := Lookup("runners.docker.services")
+	if f.Kind != KindTableList {
+		t.Errorf("runners.docker.services kind = %v, expected table list", f.Kind)
+	}
+	f, _ = Lookup("runners.kubernetes.node_selector")
+	if f.Kind != KindMap {
+		t.Errorf("runners.kubernetes.node_selector kind = %v, expected map", f.Kind)
+	}
+}
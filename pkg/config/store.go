@@ -0,0 +1,189 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+// FileStore reads and writes config files, locally or on a remote host.
+type FileStore interface {
+	ReadFile(path string) ([]byte, error)
+	// WriteFile replaces the file atomically. Unless backup is empty, the
+	// previous content is kept in the file backup.
+	WriteFile(path string, data []byte, backup string) error
+	// List returns the files whose path starts with prefix, sorted.
+	List(prefix string) ([]string, error)
+	Remove(path string) error
+	// ModTime returns when the file was last modified.
+	ModTime(path string) (time.Time, error)
+}
+
+// LocalStore reads and writes files on this machine.
+type LocalStore struct{}
+
+func (LocalStore) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+// WriteFile keeps the current file as the backup by linking or copying it,
+// then renames the new content over it, so the path always holds a
+// complete config.
+func (LocalStore) WriteFile(path string, data []byte, backup string) error {
+	tmpFile := path + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	backedUp := false
+	if _, err := os.Stat(path); err == nil && backup != "" {
+		if err := os.Link(path, backup); err != nil {
+			// Hard links fail across file systems, and on some of them
+			if err := copyFile(path, backup); err != nil {
+				os.Remove(tmpFile)
+				return fmt.Errorf("failed to backup config file: %w", err)
+			}
+		}
+		backedUp = true
+	}
+
+	if err := os.Rename(tmpFile, path); err != nil {
+		if backedUp {
+			_ = os.Remove(backup)
+		}
+		_ = os.Remove(tmpFile)
+		return fmt.Errorf("failed to replace config file: %w", err)
+	}
+
+	return nil
+}
+
+// copyFile copies src to a new file dst with the same permissions.
+func copyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return nil
+}
+
+func (LocalStore) List(prefix string) ([]string, error) {
+	dir := filepath.Dir(prefix)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if path := filepath.Join(dir, entry.Name()); strings.HasPrefix(path, prefix) && !entry.IsDir() {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+func (LocalStore) Remove(path string) error {
+	return os.Remove(path)
+}
+
+func (LocalStore) ModTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// uploadScript writes standard input to a temporary file next to the target
+// and renames it into place, so readers never see a partial config. It runs
+// under sh with the target path as $1 and the backup path, if any, as $2.
+const uploadScript = `set -e
+umask 077
+cat > "$1.tmp"
+if [ -e "$1" ] && [ -n "$2" ]; then cp -p "$1" "$2"; fi
+mv -f "$1.tmp" "$1"`
+
+// listScript prints the files whose path starts with $1, one per line.
+const listScript = `for f in "$1"*; do if [ -f "$f" ]; then printf '%s\n' "$f"; fi; done`
+
+// ExecStore reads and writes files with shell commands run through an
+// Executor, e.g. a runner.SSHExecutor for a config.toml on a remote host.
+type ExecStore struct {
+	exec runner.Executor
+}
+
+func NewExecStore(executor runner.Executor) *ExecStore {
+	return &ExecStore{exec: executor}
+}
+
+func (s *ExecStore) ReadFile(path string) ([]byte, error) {
+	data, err := s.exec.Output("cat", "--", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", path, err)
+	}
+	return data, nil
+}
+
+func (s *ExecStore) WriteFile(path string, data []byte, backup string) error {
+	if output, err := s.exec.Pipe(data, "sh", "-c", uploadScript, "sh", path, backup); err != nil {
+		return fmt.Errorf("failed to upload %s: %w: %s", path, err, output)
+	}
+	return nil
+}
+
+func (s *ExecStore) List(prefix string) ([]string, error) {
+	output, err := s.exec.Output("sh", "-c", listScript, "sh", prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s*: %w", prefix, err)
+	}
+	var paths []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths, nil
+}
+
+// ModTime reads the modification time from stat, which GNU coreutils
+// prints with nanoseconds, as in "2025-01-01 12:00:00.123456789 +0100".
+func (s *ExecStore) ModTime(path string) (time.Time, error) {
+	output, err := s.exec.Output("stat", "-c", "%y", "--", path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return time.Parse("2006-01-02 15:04:05.999999999 -0700", strings.TrimSpace(string(output)))
+}
+
+func (s *ExecStore) Remove(path string) error {
+	if output, err := s.exec.CombinedOutput("rm", "-f", "--", path); err != nil {
+		return fmt.Errorf("failed to remove %s: %w: %s", path, err, output)
+	}
+	return nil
+}
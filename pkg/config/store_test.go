@@ -0,0 +1,131 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+func TestLocalStore_WriteFileKeepsPath(t *testing.T) {
+	path := writeConfig(t, productionConfig)
+
+	// The config file is there throughout, for gitlab-runner reloading it
+	done := make(chan struct{})
+	missing := make(chan error, 1)
+	go func() {
+		for {
+			select {
+			case <-done:
+				close(missing)
+				return
+			default:
+			}
+			if _, err := os.Stat(path); err != nil {
+				missing <- err
+				close(missing)
+				return
+			}
+		}
+	}()
+
+	var store LocalStore
+	for i := 0; i < 50; i++ {
+		backup := fmt.Sprintf("%s.bak.%d", path, i)
+		if err := store.WriteFile(path, []byte(fmt.Sprintf("concurrent = %d\n", i)), backup); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+	close(done)
+	if err := <-missing; err != nil {
+		t.Errorf("config file missing during a write: %v", err)
+	}
+
+	if data, err := os.ReadFile(path + ".bak.0"); err != nil || string(data) != productionConfig {
+		t.Errorf("previous config not kept as backup: %q %v", data, err)
+	}
+	if data, err := os.ReadFile(path + ".bak.49"); err != nil || string(data) != "concurrent = 48\n" {
+		t.Errorf("previous config not kept as backup: %q %v", data, err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "concurrent = 49\n" {
+		t.Errorf("unexpected config %q %v", data, err)
+	}
+}
+
+func TestExecStore_EditRemoteConfig(t *testing.T) {
+	// The local shell plays the remote host: the store only relies on the
+	// commands it runs, not on where they run.
+	path := writeConfig(t, productionConfig)
+	cm := NewTOMLConfigManagerWithStore(path, NewExecStore(runner.NewLocalExecutor()))
+
+	if err := cm.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := cm.UpdateConcurrency(12); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != strings.Replace(productionConfig, "concurrent = 4", "concurrent = 12", 1) {
+		t.Errorf("unexpected uploaded config:\n%s", data)
+	}
+
+	backups, err := cm.Backups()
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("expected one backup, got %v %v", backups, err)
+	}
+	if backup, err := os.ReadFile(backups[0].Path); err != nil || string(backup) != productionConfig {
+		t.Errorf("previous config not kept as backup: %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary upload file left behind: %v", err)
+	}
+}
+
+func TestExecStore_FailedUploadKeepsConfig(t *testing.T) {
+	executor := runner.NewScriptedExecutor().
+		On("cat -- /etc/gitlab-runner/config.toml", runner.ScriptedResponse{Stdout: productionConfig}).
+		On("sh -c "+listScript+" sh /etc/gitlab-runner/config.toml.bak.20250101-120000", runner.ScriptedResponse{}).
+		On("sh -c "+uploadScript+" sh /etc/gitlab-runner/config.toml /etc/gitlab-runner/config.toml.bak.20250101-120000",
+			runner.ScriptedResponse{Stderr: "No space left on device", Err: errors.New("exit status 1")})
+	cm := NewTOMLConfigManagerWithStore("", NewExecStore(executor))
+	cm.now = func() time.Time { return time.Date(2025, 1, 1, 12, 0, 0, 0, time.Local) }
+
+	if err := cm.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	_ = cm.UpdateConcurrency(12)
+
+	err := cm.Save()
+	if err == nil || !strings.Contains(err.Error(), "No space left on device") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	input := executor.Input("sh -c " + uploadScript + " sh /etc/gitlab-runner/config.toml /etc/gitlab-runner/config.toml.bak.20250101-120000")
+	if !strings.Contains(string(input), "concurrent = 12") {
+		t.Errorf("edited config not uploaded: %q", input)
+	}
+
+	// The failed save must not become the new baseline
+	if rendered, _ := cm.Render(); !strings.Contains(string(rendered), "concurrent = 12") {
+		t.Errorf("pending change lost after failed save")
+	}
+}
+
+func TestExecStore_DownloadError(t *testing.T) {
+	cm := NewTOMLConfigManagerWithStore(filepath.Join("/nonexistent", "config.toml"), NewExecStore(runner.NewScriptedExecutor()))
+
+	if err := cm.Load(); err == nil || !strings.Contains(err.Error(), "failed to download") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
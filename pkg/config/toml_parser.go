@@ -1,10 +1,12 @@
 package config
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
-	"os"
+	"io/fs"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
@@ -12,71 +14,143 @@ import (
 
 const DefaultConfigPath = "/etc/gitlab-runner/config.toml"
 
+// TOMLConfigManager loads, edits and saves a config.toml. Its methods may
+// be called from several goroutines, as the TUI loads, saves and watches
+// the file in commands while editing it.
 type TOMLConfigManager struct {
-	path   string
+	path  string
+	store FileStore
+
+	mu     sync.Mutex // guards config, raw, base and version
 	config *runner.Config
+	// raw and base hold the file as last loaded or saved and the config it
+	// decoded to, so Save can rewrite only the keys that changed since.
+	raw     []byte
+	base    map[string]any
+	version fileVersion
+	// keepBackups is the number of backups kept; see SetBackupRetention.
+	keepBackups int
+	now         func() time.Time
 }
 
 func NewTOMLConfigManager(path string) *TOMLConfigManager {
+	return NewTOMLConfigManagerWithStore(path, LocalStore{})
+}
+
+// NewTOMLConfigManagerWithStore returns a manager that loads and saves the
+// config file through store, e.g. an ExecStore for a remote host.
+func NewTOMLConfigManagerWithStore(path string, store FileStore) *TOMLConfigManager {
 	if path == "" {
 		path = DefaultConfigPath
 	}
 	return &TOMLConfigManager{
-		path: path,
+		path:        path,
+		store:       store,
+		keepBackups: DefaultBackupRetention,
+		now:         time.Now,
 	}
 }
 
+// Path returns the path of the config file.
+func (cm *TOMLConfigManager) Path() string {
+	return cm.path
+}
+
 func (cm *TOMLConfigManager) Load() error {
-	config := &runner.Config{}
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	return cm.load()
+}
 
-	if _, err := toml.DecodeFile(cm.path, config); err != nil {
+func (cm *TOMLConfigManager) load() error {
+	data, err := cm.store.ReadFile(cm.path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	config := &runner.Config{}
+	if _, err := toml.Decode(string(data), config); err != nil {
 		return fmt.Errorf("failed to parse TOML config: %w", err)
 	}
 
 	cm.config = config
+	cm.raw = data
+	cm.base = toMap(config)
+	cm.recordVersion(data)
 	return nil
 }
 
-func (cm *TOMLConfigManager) Save() error {
+// Render returns the config file as Save would write it. Only keys whose
+// value changed since the last Load or Save are rewritten; comments,
+// formatting and settings the config types do not model are kept as is.
+func (cm *TOMLConfigManager) Render() ([]byte, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	return cm.render()
+}
+
+func (cm *TOMLConfigManager) render() ([]byte, error) {
 	if cm.config == nil {
-		return fmt.Errorf("no config loaded")
+		return nil, fmt.Errorf("no config loaded")
 	}
 
-	var buf bytes.Buffer
-	encoder := toml.NewEncoder(&buf)
-	encoder.Indent = ""
+	return mergeDocument(cm.raw, cm.base, toMap(cm.config))
+}
+
+// PendingDiff returns a unified diff of the config file as it is now
+// against what Save would write, or "" when saving would not change it.
+func (cm *TOMLConfigManager) PendingDiff() (string, error) {
+	data, err := cm.Render()
+	if err != nil {
+		return "", err
+	}
 
-	if err := encoder.Encode(cm.config); err != nil {
-		return fmt.Errorf("failed to encode config: %w", err)
+	current, err := cm.store.ReadFile(cm.path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("failed to read config file: %w", err)
 	}
+	return UnifiedDiff(cm.path, cm.path+" (pending)", current, data), nil
+}
+
+func (cm *TOMLConfigManager) Save() error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
 
-	tmpFile := cm.path + ".tmp"
-	if err := os.WriteFile(tmpFile, buf.Bytes(), 0600); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+	data, err := cm.render()
+	if err != nil {
+		return err
 	}
 
-	backupFile := cm.path + ".bak"
-	if _, err := os.Stat(cm.path); err == nil {
-		if err := os.Rename(cm.path, backupFile); err != nil {
-			os.Remove(tmpFile)
-			return fmt.Errorf("failed to backup config file: %w", err)
-		}
+	// Optimistic concurrency: the file must still be the one the changes
+	// were made to
+	if changed, err := cm.changed(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to check config file: %w", err)
+	} else if changed {
+		return ErrChangedOnDisk
 	}
 
-	if err := os.Rename(tmpFile, cm.path); err != nil {
-		_ = os.Rename(backupFile, cm.path)
-		_ = os.Remove(tmpFile)
-		return fmt.Errorf("failed to replace config file: %w", err)
+	if err := cm.store.WriteFile(cm.path, data, cm.nextBackup()); err != nil {
+		return err
 	}
+	// A backup that could not be removed is only kept longer
+	_ = cm.pruneBackups()
 
+	cm.raw = data
+	cm.base = toMap(cm.config)
+	cm.recordVersion(data)
 	return nil
 }
 
 func (cm *TOMLConfigManager) GetConfig() *runner.Config {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
 	return cm.config
 }
 
 func (cm *TOMLConfigManager) UpdateConcurrency(concurrent int) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
 	if cm.config == nil {
 		return fmt.Errorf("no config loaded")
 	}
@@ -90,6 +164,9 @@ func (cm *TOMLConfigManager) UpdateConcurrency(concurrent int) error {
 }
 
 func (cm *TOMLConfigManager) UpdateCheckInterval(interval int) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
 	if cm.config == nil {
 		return fmt.Errorf("no config loaded")
 	}
@@ -103,6 +180,9 @@ func (cm *TOMLConfigManager) UpdateCheckInterval(interval int) error {
 }
 
 func (cm *TOMLConfigManager) UpdateLogLevel(level string) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
 	if cm.config == nil {
 		return fmt.Errorf("no config loaded")
 	}
@@ -126,6 +206,12 @@ func (cm *TOMLConfigManager) UpdateLogLevel(level string) error {
 }
 
 func (cm *TOMLConfigManager) GetRunner(name string) (runner *runner.RunnerConfig, index int) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	return cm.getRunner(name)
+}
+
+func (cm *TOMLConfigManager) getRunner(name string) (runner *runner.RunnerConfig, index int) {
 	if cm.config == nil {
 		return nil, -1
 	}
@@ -139,8 +225,25 @@ func (cm *TOMLConfigManager) GetRunner(name string) (runner *runner.RunnerConfig
 	return nil, -1
 }
 
+// RemoveRunner drops the runner's [[runners]] entry.
+func (cm *TOMLConfigManager) RemoveRunner(name string) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	runner, idx := cm.getRunner(name)
+	if runner == nil {
+		return fmt.Errorf("runner %s not found", name)
+	}
+
+	cm.config.Runners = append(cm.config.Runners[:idx], cm.config.Runners[idx+1:]...)
+	return nil
+}
+
 func (cm *TOMLConfigManager) UpdateRunnerLimit(name string, limit int) error {
-	runner, idx := cm.GetRunner(name)
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	runner, idx := cm.getRunner(name)
 	if runner == nil {
 		return fmt.Errorf("runner %s not found", name)
 	}
@@ -154,7 +257,10 @@ func (cm *TOMLConfigManager) UpdateRunnerLimit(name string, limit int) error {
 }
 
 func (cm *TOMLConfigManager) UpdateRunnerTags(name string, tags []string) error {
-	runner, idx := cm.GetRunner(name)
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	runner, idx := cm.getRunner(name)
 	if runner == nil {
 		return fmt.Errorf("runner %s not found", name)
 	}
@@ -164,7 +270,10 @@ func (cm *TOMLConfigManager) UpdateRunnerTags(name string, tags []string) error
 }
 
 func (cm *TOMLConfigManager) UpdateRunnerUntagged(name string, runUntagged bool) error {
-	runner, idx := cm.GetRunner(name)
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	runner, idx := cm.getRunner(name)
 	if runner == nil {
 		return fmt.Errorf("runner %s not found", name)
 	}
@@ -174,7 +283,10 @@ func (cm *TOMLConfigManager) UpdateRunnerUntagged(name string, runUntagged bool)
 }
 
 func (cm *TOMLConfigManager) UpdateRunnerLocked(name string, locked bool) error {
-	runner, idx := cm.GetRunner(name)
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	runner, idx := cm.getRunner(name)
 	if runner == nil {
 		return fmt.Errorf("runner %s not found", name)
 	}
@@ -184,7 +296,10 @@ func (cm *TOMLConfigManager) UpdateRunnerLocked(name string, locked bool) error
 }
 
 func (cm *TOMLConfigManager) UpdateRunnerMaxBuilds(name string, maxBuilds int) error {
-	runner, idx := cm.GetRunner(name)
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	runner, idx := cm.getRunner(name)
 	if runner == nil {
 		return fmt.Errorf("runner %s not found", name)
 	}
@@ -198,7 +313,10 @@ func (cm *TOMLConfigManager) UpdateRunnerMaxBuilds(name string, maxBuilds int) e
 }
 
 func (cm *TOMLConfigManager) UpdateRunnerRequestConcurrency(name string, concurrency int) error {
-	runner, idx := cm.GetRunner(name)
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	runner, idx := cm.getRunner(name)
 	if runner == nil {
 		return fmt.Errorf("runner %s not found", name)
 	}
@@ -212,7 +330,10 @@ func (cm *TOMLConfigManager) UpdateRunnerRequestConcurrency(name string, concurr
 }
 
 func (cm *TOMLConfigManager) UpdateRunnerOutputLimit(name string, limit int) error {
-	runner, idx := cm.GetRunner(name)
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	runner, idx := cm.getRunner(name)
 	if runner == nil {
 		return fmt.Errorf("runner %s not found", name)
 	}
@@ -224,42 +345,3 @@ func (cm *TOMLConfigManager) UpdateRunnerOutputLimit(name string, limit int) err
 	cm.config.Runners[idx].OutputLimit = limit
 	return nil
 }
-
-func (cm *TOMLConfigManager) Validate() error {
-	if cm.config == nil {
-		return fmt.Errorf("no config loaded")
-	}
-
-	if cm.config.Concurrent < 1 {
-		return fmt.Errorf("concurrent must be at least 1")
-	}
-
-	for i := range cm.config.Runners {
-		runner := &cm.config.Runners[i]
-		if runner.Name == "" {
-			return fmt.Errorf("runner %d has no name", i)
-		}
-		if runner.URL == "" {
-			return fmt.Errorf("runner %s has no URL", runner.Name)
-		}
-		if runner.Token == "" {
-			return fmt.Errorf("runner %s has no token", runner.Name)
-		}
-		if runner.Executor == "" {
-			return fmt.Errorf("runner %s has no executor", runner.Name)
-		}
-
-		switch runner.Executor {
-		case "docker", "docker+machine", "docker-ssh", "docker-ssh+machine":
-			if runner.Docker == nil || runner.Docker.Image == "" {
-				return fmt.Errorf("runner %s: docker executor requires image", runner.Name)
-			}
-		case "kubernetes":
-			if runner.Kubernetes == nil || runner.Kubernetes.Image == "" {
-				return fmt.Errorf("runner %s: kubernetes executor requires image", runner.Name)
-			}
-		}
-	}
-
-	return nil
-}
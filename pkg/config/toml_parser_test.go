@@ -247,7 +247,7 @@ func TestTOMLConfigManager_Validate(t *testing.T) {
 				Concurrent: 0,
 			},
 			expectError: true,
-			errorMsg:    "concurrent must be at least 1",
+			errorMsg:    "concurrent: must be at least 1",
 		},
 		{
 			name: "Runner without name",
@@ -0,0 +1,367 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+// Severity tells whether a finding keeps the config from being saved.
+type Severity int
+
+const (
+	// SeverityWarning marks settings that work but are risky or likely
+	// mistakes.
+	SeverityWarning Severity = iota
+	// SeverityError marks settings gitlab-runner rejects, or that make jobs
+	// fail.
+	SeverityError
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Finding is a problem with one key of the config.
+type Finding struct {
+	Severity Severity
+	// Path is the key, with runners numbered from 0 as in
+	// runners[0].docker.memory.
+	Path    string
+	Message string
+}
+
+func (f Finding) Error() string {
+	return f.Path + ": " + f.Message
+}
+
+// RunnerPath returns the path of key, relative to runner.RunnerConfig, in
+// the runner at index i.
+func RunnerPath(i int, key string) string {
+	return fmt.Sprintf("runners[%d].%s", i, key)
+}
+
+// rule checks one aspect of a config.
+type rule func(cfg *runner.Config) []Finding
+
+// rules are the checks Check applies.
+var rules = []rule{
+	requiredKeys,
+	duplicateRunners,
+	runnerURLs,
+	knownExecutors,
+	jobLimits,
+	dockerResources,
+	pullPolicies,
+	privilegedContainers,
+	kubernetesQuantities,
+}
+
+// Check applies every rule to cfg and returns all findings, errors first
+// and otherwise in the order of the file.
+func Check(cfg *runner.Config) []Finding {
+	var findings []Finding
+	for _, rule := range rules {
+		findings = append(findings, rule(cfg)...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Severity > findings[j].Severity
+	})
+	return findings
+}
+
+// Findings returns the problems of the loaded config.
+func (cm *TOMLConfigManager) Findings() []Finding {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if cm.config == nil {
+		return nil
+	}
+	return Check(cm.config)
+}
+
+// Validate returns the findings of error severity as one error, or nil
+// when the config can be saved. Warnings are left to Findings.
+func (cm *TOMLConfigManager) Validate() error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if cm.config == nil {
+		return fmt.Errorf("no config loaded")
+	}
+
+	var errs []error
+	for _, f := range Check(cm.config) {
+		if f.Severity == SeverityError {
+			errs = append(errs, f)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func usesDocker(r *runner.RunnerConfig) bool {
+	return strings.HasPrefix(r.Executor, "docker")
+}
+
+func requiredKeys(cfg *runner.Config) []Finding {
+	var findings []Finding
+	if cfg.Concurrent < 1 {
+		findings = append(findings, Finding{SeverityError, "concurrent", "must be at least 1"})
+	}
+
+	for i := range cfg.Runners {
+		r := &cfg.Runners[i]
+		for _, required := range []struct{ key, value, name string }{
+			{"name", r.Name, "name"},
+			{"url", r.URL, "URL"},
+			{"token", r.Token, "token"},
+			{"executor", r.Executor, "executor"},
+		} {
+			if required.value == "" {
+				findings = append(findings, Finding{SeverityError, RunnerPath(i, required.key), "runner has no " + required.name})
+			}
+		}
+
+		switch {
+		case usesDocker(r) && (r.Docker == nil || r.Docker.Image == ""):
+			findings = append(findings, Finding{SeverityError, RunnerPath(i, "docker.image"), "docker executor requires image"})
+		case r.Executor == "kubernetes" && (r.Kubernetes == nil || r.Kubernetes.Image == ""):
+			findings = append(findings, Finding{SeverityError, RunnerPath(i, "kubernetes.image"), "kubernetes executor requires image"})
+		}
+	}
+	return findings
+}
+
+// duplicateRunners reports runners sharing a name, which makes them hard
+// to tell apart in logs, or a token, which makes gitlab-runner poll for
+// the same runner twice.
+func duplicateRunners(cfg *runner.Config) []Finding {
+	var findings []Finding
+	names := make(map[string]int)
+	tokens := make(map[string]int)
+	for i, r := range cfg.Runners {
+		if first, ok := names[r.Name]; ok && r.Name != "" {
+			findings = append(findings, Finding{SeverityWarning, RunnerPath(i, "name"),
+				fmt.Sprintf("name is also used by runners[%d]", first)})
+		} else {
+			names[r.Name] = i
+		}
+		if first, ok := tokens[r.Token]; ok && r.Token != "" {
+			findings = append(findings, Finding{SeverityError, RunnerPath(i, "token"),
+				fmt.Sprintf("token is also used by runners[%d] (%s)", first, cfg.Runners[first].Name)})
+		} else {
+			tokens[r.Token] = i
+		}
+	}
+	return findings
+}
+
+func runnerURLs(cfg *runner.Config) []Finding {
+	var findings []Finding
+	for i, r := range cfg.Runners {
+		for _, u := range []struct{ key, value string }{
+			{"url", r.URL},
+			{"clone_url", r.CloneURL},
+		} {
+			if err := checkURL(u.value); err != nil {
+				findings = append(findings, Finding{SeverityError, RunnerPath(i, u.key), err.Error()})
+			}
+		}
+	}
+	return findings
+}
+
+func knownExecutors(cfg *runner.Config) []Finding {
+	var findings []Finding
+	for i, r := range cfg.Runners {
+		if err := checkExecutor(r.Executor); err != nil {
+			findings = append(findings, Finding{SeverityError, RunnerPath(i, "executor"), err.Error()})
+		}
+	}
+	return findings
+}
+
+// jobLimits reports negative limits, and runner limits that concurrent,
+// which caps the jobs of all runners together, never lets them reach.
+func jobLimits(cfg *runner.Config) []Finding {
+	var findings []Finding
+	for i, r := range cfg.Runners {
+		for _, limit := range []struct {
+			key   string
+			value int
+		}{
+			{"limit", r.Limit},
+			{"max_builds", r.MaxBuilds},
+			{"request_concurrency", r.RequestConcurrency},
+			{"output_limit", r.OutputLimit},
+		} {
+			if err := checkNonNegative(limit.value); err != nil {
+				findings = append(findings, Finding{SeverityError, RunnerPath(i, limit.key), err.Error()})
+			}
+		}
+		if cfg.Concurrent >= 1 && r.Limit > cfg.Concurrent {
+			findings = append(findings, Finding{SeverityWarning, RunnerPath(i, "limit"),
+				fmt.Sprintf("limit %d is above concurrent %d, so it is never reached", r.Limit, cfg.Concurrent)})
+		}
+	}
+	return findings
+}
+
+var (
+	// dockerSize is the size syntax docker accepts for memory, as in 512m
+	// or 1.5GiB.
+	dockerSize = regexp.MustCompile(`^\d+(\.\d+)? ?[kKmMgGtTpP]?[iI]?[bB]?$`)
+	// cpuSet is a list of CPUs and ranges, as in 0-3,6.
+	cpuSet = regexp.MustCompile(`^\d+(-\d+)?(,\d+(-\d+)?)*$`)
+)
+
+// dockerResources reports memory and CPU settings docker would refuse when
+// creating the job's containers.
+func dockerResources(cfg *runner.Config) []Finding {
+	var findings []Finding
+	for i, r := range cfg.Runners {
+		d := r.Docker
+		if d == nil {
+			continue
+		}
+		for _, size := range []struct{ key, value string }{
+			{"memory", d.Memory},
+			{"memory_swap", d.MemorySwap},
+			{"memory_reservation", d.MemoryReservation},
+			{"service_memory", d.ServiceMemory},
+		} {
+			// -1 lifts the swap limit
+			if size.value == "" || (size.key == "memory_swap" && size.value == "-1") {
+				continue
+			}
+			if !dockerSize.MatchString(size.value) {
+				findings = append(findings, Finding{SeverityError, RunnerPath(i, "docker."+size.key),
+					fmt.Sprintf("%q is not a size such as 512m or 2g", size.value)})
+			}
+		}
+		if d.MemorySwap != "" && d.Memory == "" {
+			findings = append(findings, Finding{SeverityError, RunnerPath(i, "docker.memory_swap"), "requires memory to be set"})
+		}
+
+		for _, cpus := range []struct{ key, value string }{
+			{"cpus", d.Cpus},
+			{"service_cpus", d.ServiceCpus},
+		} {
+			if cpus.value == "" {
+				continue
+			}
+			if n, err := strconv.ParseFloat(cpus.value, 64); err != nil || n <= 0 {
+				findings = append(findings, Finding{SeverityError, RunnerPath(i, "docker."+cpus.key),
+					fmt.Sprintf("%q is not a number of CPUs such as 1.5", cpus.value)})
+			}
+		}
+		if d.CpusetCpus != "" && !cpuSet.MatchString(d.CpusetCpus) {
+			findings = append(findings, Finding{SeverityError, RunnerPath(i, "docker.cpuset_cpus"),
+				fmt.Sprintf("%q is not a CPU list such as 0-3 or 0,2", d.CpusetCpus)})
+		}
+	}
+	return findings
+}
+
+// pullPolicies reports unknown policies, policies docker's
+// allowed_pull_policies forbids, which fail every job, and "never" next to
+// policies that pull.
+func pullPolicies(cfg *runner.Config) []Finding {
+	var findings []Finding
+	check := func(path string, policies runner.StringList, allowed []string) {
+		if err := checkPullPolicy(policies); err != nil {
+			findings = append(findings, Finding{SeverityError, path, err.Error()})
+			return
+		}
+		seen := make(map[string]bool)
+		for _, policy := range policies {
+			if seen[policy] {
+				findings = append(findings, Finding{SeverityWarning, path, fmt.Sprintf("%q is listed twice", policy)})
+			}
+			seen[policy] = true
+			if len(allowed) > 0 && !slices.Contains(allowed, policy) {
+				findings = append(findings, Finding{SeverityError, path,
+					fmt.Sprintf("%q is not in allowed_pull_policies", policy)})
+			}
+		}
+		if seen["never"] && len(seen) > 1 {
+			findings = append(findings, Finding{SeverityWarning, path, `"never" conflicts with the policies that pull`})
+		}
+	}
+
+	for i, r := range cfg.Runners {
+		if r.Docker != nil {
+			check(RunnerPath(i, "docker.pull_policy"), r.Docker.PullPolicy, r.Docker.AllowedPullPolicies)
+		}
+		if r.Kubernetes != nil {
+			check(RunnerPath(i, "kubernetes.pull_policy"), r.Kubernetes.PullPolicy, nil)
+		}
+	}
+	return findings
+}
+
+func privilegedContainers(cfg *runner.Config) []Finding {
+	var findings []Finding
+	for i, r := range cfg.Runners {
+		if r.Docker != nil && r.Docker.Privileged {
+			message := "jobs run privileged containers with root access to the host"
+			if len(r.Docker.AllowedPrivilegedImages) > 0 {
+				message = "jobs using allowed_privileged_images run with root access to the host"
+			}
+			findings = append(findings, Finding{SeverityWarning, RunnerPath(i, "docker.privileged"), message})
+		}
+		if r.Kubernetes != nil && r.Kubernetes.Privileged {
+			findings = append(findings, Finding{SeverityWarning, RunnerPath(i, "kubernetes.privileged"),
+				"jobs run privileged pods with root access to the node"})
+		}
+	}
+	return findings
+}
+
+// quantity is the syntax of Kubernetes resource quantities, as in 500m,
+// 1.5 or 2Gi.
+var quantity = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+|[numkMGTPE]|[KMGTPE]i)?$`)
+
+func kubernetesQuantities(cfg *runner.Config) []Finding {
+	var findings []Finding
+	for i, r := range cfg.Runners {
+		k := r.Kubernetes
+		if k == nil {
+			continue
+		}
+		for _, q := range []struct{ key, value string }{
+			{"cpu_limit", k.CPULimit},
+			{"cpu_request", k.CPURequest},
+			{"memory_limit", k.MemoryLimit},
+			{"memory_request", k.MemoryRequest},
+			{"ephemeral_storage_limit", k.EphemeralStorageLimit},
+			{"ephemeral_storage_request", k.EphemeralStorageRequest},
+			{"service_cpu_limit", k.ServiceCPULimit},
+			{"service_cpu_request", k.ServiceCPURequest},
+			{"service_memory_limit", k.ServiceMemoryLimit},
+			{"service_memory_request", k.ServiceMemoryRequest},
+			{"helper_cpu_limit", k.HelperCPULimit},
+			{"helper_cpu_request", k.HelperCPURequest},
+			{"helper_memory_limit", k.HelperMemoryLimit},
+			{"helper_memory_request", k.HelperMemoryRequest},
+			{"cpu_limit_overwrite_max_allowed", k.CPULimitOverwriteMaxAllowed},
+			{"memory_limit_overwrite_max_allowed", k.MemoryLimitOverwriteMaxAllowed},
+		} {
+			if q.value != "" && !quantity.MatchString(q.value) {
+				findings = append(findings, Finding{SeverityError, RunnerPath(i, "kubernetes."+q.key),
+					fmt.Sprintf("%q is not a quantity such as 500m or 2Gi", q.value)})
+			}
+		}
+	}
+	return findings
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheck_ReportsAllFindings(t *testing.T) {
+	cm := loadConfig(t, `concurrent = 2
+
+[[runners]]
+  name = "build"
+  url = "gitlab.example.com"
+  token = "glrt-a"
+  executor = "docker"
+  limit = 4
+  [runners.docker]
+    image = "alpine"
+    memory = "2 gigs"
+    memory_swap = "4g"
+    cpus = "-1"
+    cpuset_cpus = "0-3"
+    privileged = true
+    pull_policy = ["never", "always"]
+    allowed_pull_policies = ["always", "if-not-present"]
+
+[[runners]]
+  name = "build"
+  url = "https://gitlab.example.com"
+  token = "glrt-a"
+  executor = "kubernetes-pods"
+
+[[runners]]
+  name = "k8s"
+  url = "https://gitlab.example.com"
+  token = "glrt-c"
+  executor = "kubernetes"
+  [runners.kubernetes]
+    image = "alpine"
+    cpu_request = "500m"
+    memory_limit = "2g"
+    helper_memory_limit = "256Mi"
+    pull_policy = ["always", "sometimes"]
+`)
+
+	want := []struct {
+		severity Severity
+		path     string
+		message  string
+	}{
+		{SeverityError, "runners[1].token", "also used by runners[0]"},
+		{SeverityError, "runners[0].url", "not an http or https URL"},
+		{SeverityError, "runners[1].executor", `unknown executor "kubernetes-pods"`},
+		{SeverityError, "runners[0].docker.memory", `"2 gigs" is not a size`},
+		{SeverityError, "runners[0].docker.cpus", "not a number of CPUs"},
+		{SeverityError, "runners[0].docker.pull_policy", `"never" is not in allowed_pull_policies`},
+		{SeverityError, "runners[2].kubernetes.pull_policy", `unknown pull policy "sometimes"`},
+		{SeverityError, "runners[2].kubernetes.memory_limit", `"2g" is not a quantity`},
+		{SeverityWarning, "runners[1].name", "also used by runners[0]"},
+		{SeverityWarning, "runners[0].limit", "above concurrent 2"},
+		{SeverityWarning, "runners[0].docker.pull_policy", `"never" conflicts`},
+		{SeverityWarning, "runners[0].docker.privileged", "root access"},
+	}
+
+	findings := cm.Findings()
+	if len(findings) != len(want) {
+		t.Errorf("expected %d findings, got %d:", len(want), len(findings))
+		for _, f := range findings {
+			t.Logf("  %s %s", f.Severity, f.Error())
+		}
+	}
+	for i, w := range want {
+		if i >= len(findings) {
+			break
+		}
+		f := findings[i]
+		if f.Severity != w.severity || f.Path != w.path || !strings.Contains(f.Message, w.message) {
+			t.Errorf("finding %d = %s %s, expected %s %s: ...%s...", i, f.Severity, f.Error(), w.severity, w.path, w.message)
+		}
+	}
+
+	err := cm.Validate()
+	if err == nil {
+		t.Fatal("expected Validate to fail")
+	}
+	if lines := strings.Split(err.Error(), "\n"); len(lines) != 8 {
+		t.Errorf("expected the 8 errors in one error, got:\n%s", err)
+	}
+}
+
+func TestCheck_AcceptsValidValues(t *testing.T) {
+	cm := loadConfig(t, `concurrent = 4
+
+[[runners]]
+  name = "docker"
+  url = "https://gitlab.example.com"
+  token = "glrt-a"
+  executor = "docker"
+  limit = 4
+  [runners.docker]
+    image = "alpine"
+    memory = "1.5GiB"
+    memory_swap = "-1"
+    memory_reservation = "512m"
+    cpus = "1.5"
+    cpuset_cpus = "0-3,6"
+    pull_policy = ["always", "if-not-present"]
+
+[[runners]]
+  name = "k8s"
+  url = "https://gitlab.example.com"
+  token = "glrt-b"
+  executor = "kubernetes"
+  [runners.kubernetes]
+    image = "alpine"
+    cpu_limit = "2"
+    cpu_request = "500m"
+    memory_limit = "2Gi"
+    ephemeral_storage_request = "1e9"
+    pull_policy = "if-not-present"
+`)
+	if findings := cm.Findings(); len(findings) != 0 {
+		t.Errorf("expected no findings, got %v", findings)
+	}
+}
+
+func TestCheck_Testdata(t *testing.T) {
+	names, err := filepath.Glob(filepath.Join("testdata", "*.toml"))
+	if err != nil || len(names) == 0 {
+		t.Fatalf("no testdata: %v", err)
+	}
+	for _, name := range names {
+		t.Run(filepath.Base(name), func(t *testing.T) {
+			data, err := os.ReadFile(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := loadConfig(t, string(data)).Validate(); err != nil {
+				t.Errorf("expected the sample config to be valid, got:\n%v", err)
+			}
+		})
+	}
+}
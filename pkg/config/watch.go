@@ -0,0 +1,78 @@
+package config
+
+import (
+	"crypto/sha256"
+	"errors"
+	"reflect"
+	"time"
+)
+
+// ErrChangedOnDisk is returned by Save when the config file was changed by
+// someone else, such as gitlab-runner or a configuration management tool,
+// since it was loaded. Load it again to edit the new version.
+var ErrChangedOnDisk = errors.New("config file was changed on disk since it was loaded")
+
+// fileVersion identifies the content of the config file as last loaded or
+// saved. The modification time spares reading the file to tell that it did
+// not change; the hash tells whether a newer file has other content.
+type fileVersion struct {
+	modTime time.Time
+	hash    [sha256.Size]byte
+}
+
+// recordVersion remembers data as the content of the config file.
+func (cm *TOMLConfigManager) recordVersion(data []byte) {
+	modTime, err := cm.store.ModTime(cm.path)
+	if err != nil {
+		modTime = time.Time{}
+	}
+	cm.version = fileVersion{modTime: settled(modTime), hash: sha256.Sum256(data)}
+}
+
+// settled returns the modification time, or the zero time when it is too
+// recent to be relied on: file systems keep coarse timestamps, so a write
+// right after it may leave it unchanged. Without one the file is hashed.
+func settled(modTime time.Time) time.Time {
+	if time.Since(modTime) < time.Second {
+		return time.Time{}
+	}
+	return modTime
+}
+
+// Changed reports whether the config file has other content than when it
+// was last loaded or saved.
+func (cm *TOMLConfigManager) Changed() (bool, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	return cm.changed()
+}
+
+func (cm *TOMLConfigManager) changed() (bool, error) {
+	if cm.config == nil {
+		return false, nil
+	}
+
+	modTime, err := cm.store.ModTime(cm.path)
+	if err == nil && !modTime.IsZero() && modTime.Equal(cm.version.modTime) {
+		return false, nil
+	}
+
+	data, err := cm.store.ReadFile(cm.path)
+	if err != nil {
+		return false, err
+	}
+	if sha256.Sum256(data) != cm.version.hash {
+		return true, nil
+	}
+	// Touched without changes
+	cm.version.modTime = settled(modTime)
+	return false, nil
+}
+
+// Modified reports whether the loaded config has changes that are not
+// saved.
+func (cm *TOMLConfigManager) Modified() bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	return cm.config != nil && !reflect.DeepEqual(toMap(cm.config), cm.base)
+}
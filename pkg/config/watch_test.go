@@ -0,0 +1,130 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+func TestChanged(t *testing.T) {
+	for name, store := range map[string]FileStore{
+		"local":  LocalStore{},
+		"remote": NewExecStore(runner.NewLocalExecutor()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := writeConfig(t, "concurrent = 1\n")
+			cm := NewTOMLConfigManagerWithStore(path, store)
+			if err := cm.Load(); err != nil {
+				t.Fatal(err)
+			}
+
+			if changed, err := cm.Changed(); err != nil || changed {
+				t.Fatalf("expected no change after Load, got %v %v", changed, err)
+			}
+
+			later := time.Now().Add(time.Minute)
+			if err := os.Chtimes(path, later, later); err != nil {
+				t.Fatal(err)
+			}
+			if changed, err := cm.Changed(); err != nil || changed {
+				t.Errorf("expected a touched file to count as unchanged, got %v %v", changed, err)
+			}
+
+			if err := os.WriteFile(path, []byte("concurrent = 8\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if changed, err := cm.Changed(); err != nil || !changed {
+				t.Errorf("expected change to be detected, got %v %v", changed, err)
+			}
+		})
+	}
+}
+
+func TestSave_RefusesToOverwriteExternalChanges(t *testing.T) {
+	path := writeConfig(t, "concurrent = 1\n")
+	cm := NewTOMLConfigManager(path)
+	if err := cm.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.UpdateConcurrency(2); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte("concurrent = 8\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.Save(); !errors.Is(err, ErrChangedOnDisk) {
+		t.Fatalf("expected ErrChangedOnDisk, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "concurrent = 8\n" {
+		t.Errorf("external change overwritten: %q", data)
+	}
+
+	if err := cm.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.UpdateConcurrency(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.Save(); err != nil {
+		t.Fatalf("expected save after reload to succeed, got %v", err)
+	}
+	if changed, _ := cm.Changed(); changed {
+		t.Error("own save counted as an external change")
+	}
+}
+
+func TestModified(t *testing.T) {
+	cm := loadConfig(t, "concurrent = 1\n")
+	if cm.Modified() {
+		t.Error("expected no changes after Load")
+	}
+	if err := cm.UpdateConcurrency(2); err != nil {
+		t.Fatal(err)
+	}
+	if !cm.Modified() {
+		t.Error("expected unsaved change")
+	}
+	if err := cm.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if cm.Modified() {
+		t.Error("expected no changes after Save")
+	}
+}
+
+func TestChanged_WhileSaving(t *testing.T) {
+	// The TUI checks the file for changes in a command while another one
+	// saves it; run with -race
+	path := writeConfig(t, productionConfig)
+	cm := NewTOMLConfigManager(path)
+	cm.SetBackupRetention(0)
+	if err := cm.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			_, _ = cm.Changed()
+			_ = cm.Modified()
+		}
+	}()
+	for i := 1; i <= 20; i++ {
+		if err := cm.UpdateConcurrency(i); err != nil {
+			t.Fatal(err)
+		}
+		if err := cm.Save(); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	<-done
+
+	if changed, err := cm.Changed(); err != nil || changed {
+		t.Errorf("expected the saved file to be current, got %v %v", changed, err)
+	}
+}
@@ -0,0 +1,294 @@
+// Package export writes runner logs and job history to files as plain
+// text, JSON Lines or CSV.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+// Format is the file format of an export.
+type Format string
+
+const (
+	Text  Format = "text"
+	JSONL Format = "jsonl"
+	CSV   Format = "csv"
+)
+
+// Formats are the supported formats in the order they are offered.
+var Formats = []Format{Text, JSONL, CSV}
+
+// Extension is the file extension of the format, with the dot.
+func (f Format) Extension() string {
+	switch f {
+	case JSONL:
+		return ".jsonl"
+	case CSV:
+		return ".csv"
+	}
+	return ".log"
+}
+
+// FormatOf returns the format for a file name by its extension. Names
+// without a known extension are plain text.
+func FormatOf(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		return JSONL
+	case ".csv":
+		return CSV
+	}
+	return Text
+}
+
+// ExpandPath resolves a leading ~ to the home directory.
+func ExpandPath(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
+
+// Exists reports whether there is a file at path, so an export to it would
+// replace it.
+func Exists(path string) bool {
+	path, err := ExpandPath(strings.TrimSpace(path))
+	if err != nil || path == "" {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// ToFile creates the file at path, and its directory if needed, and writes
+// it with write in the format of its extension. It returns the path
+// written. An existing file is only replaced with overwrite; otherwise it
+// is an error matching fs.ErrExist.
+func ToFile(path string, overwrite bool, write func(io.Writer, Format) error) (string, error) {
+	path, err := ExpandPath(strings.TrimSpace(path))
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return "", fmt.Errorf("no file name given")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("failed to create export file: %w", err)
+	}
+	if err := write(f, FormatOf(path)); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// logRecord is a log entry in JSON Lines exports.
+type logRecord struct {
+	Time    *time.Time        `json:"time,omitempty"`
+	Level   string            `json:"level"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+	Raw     string            `json:"raw"`
+}
+
+// WriteLogs writes log entries. Plain text keeps the lines as they were
+// logged.
+func WriteLogs(w io.Writer, format Format, entries []runner.LogEntry) error {
+	switch format {
+	case JSONL:
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		for _, e := range entries {
+			record := logRecord{Level: e.Level, Message: e.Message, Fields: e.Fields, Raw: e.Raw}
+			if !e.Time.IsZero() {
+				record.Time = &e.Time
+			}
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case CSV:
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"time", "level", "message", "fields", "raw"})
+		for _, e := range entries {
+			_ = cw.Write([]string{formatTime(e.Time), e.Level, e.Message, formatFields(e.Fields), e.Raw})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	for _, e := range entries {
+		if _, err := fmt.Fprintln(w, e.Raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jobRecord is a job in JSON Lines exports.
+type jobRecord struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name,omitempty"`
+	Status     string     `json:"status,omitempty"`
+	Stage      string     `json:"stage,omitempty"`
+	Project    string     `json:"project,omitempty"`
+	Pipeline   int        `json:"pipeline,omitempty"`
+	Started    *time.Time `json:"started,omitempty"`
+	Finished   *time.Time `json:"finished,omitempty"`
+	DurationS  float64    `json:"duration_s,omitempty"`
+	RunnerName string     `json:"runner_name,omitempty"`
+	RunnerID   string     `json:"runner_id,omitempty"`
+	ExitCode   int        `json:"exit_code,omitempty"`
+	URL        string     `json:"url,omitempty"`
+}
+
+var jobColumns = []string{"id", "name", "status", "stage", "project", "pipeline", "started", "finished", "duration_s", "runner_name", "runner_id", "exit_code", "url"}
+
+// WriteJobs writes jobs. Plain text is a table like the History tab's.
+func WriteJobs(w io.Writer, format Format, jobs []runner.Job) error {
+	switch format {
+	case JSONL:
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		for _, j := range jobs {
+			record := jobRecord{
+				ID:         j.ID,
+				Name:       j.Name,
+				Status:     j.Status,
+				Stage:      j.Stage,
+				Project:    j.Project,
+				Pipeline:   j.Pipeline,
+				DurationS:  j.Duration.Seconds(),
+				RunnerName: j.RunnerName,
+				RunnerID:   j.RunnerID,
+				ExitCode:   j.ExitCode,
+				URL:        j.URL,
+			}
+			if !j.Started.IsZero() {
+				record.Started = &j.Started
+			}
+			if !j.Finished.IsZero() {
+				record.Finished = &j.Finished
+			}
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case CSV:
+		cw := csv.NewWriter(w)
+		_ = cw.Write(jobColumns)
+		for _, j := range jobs {
+			_ = cw.Write([]string{
+				strconv.Itoa(j.ID),
+				j.Name,
+				j.Status,
+				j.Stage,
+				j.Project,
+				formatInt(j.Pipeline),
+				formatTime(j.Started),
+				formatTime(j.Finished),
+				formatSeconds(j.Duration),
+				j.RunnerName,
+				j.RunnerID,
+				formatInt(j.ExitCode),
+				j.URL,
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "JOB\tSTATUS\tRUNNER\tPROJECT\tSTARTED\tDURATION\tEXIT CODE")
+	for _, j := range jobs {
+		started := "-"
+		if !j.Started.IsZero() {
+			started = j.Started.Format("2006-01-02 15:04:05")
+		}
+		duration := "-"
+		if j.Duration > 0 {
+			duration = j.Duration.Round(time.Second).String()
+		}
+		fmt.Fprintf(tw, "#%d\t%s\t%s\t%s\t%s\t%s\t%s\n", j.ID, dash(j.Status), dash(j.RunnerName), dash(j.Project), started, duration, dash(formatInt(j.ExitCode)))
+	}
+	return tw.Flush()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatSeconds(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
+
+func formatInt(i int) string {
+	if i == 0 {
+		return ""
+	}
+	return strconv.Itoa(i)
+}
+
+// formatFields writes fields as key=value pairs sorted by key.
+func formatFields(fields map[string]string) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := fields[key]
+		if strings.ContainsAny(value, " \"") {
+			value = strconv.Quote(value)
+		}
+		pairs = append(pairs, key+"="+value)
+	}
+	return strings.Join(pairs, " ")
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,173 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+var testEntries = []runner.LogEntry{
+	runner.ParseLogLine(`time="2024-01-02T10:00:00Z" level=info msg="Checking for jobs... received" job=1001 runner=aaaaaaaa`),
+	runner.ParseLogLine(`WARNING: Job failed: exit code 1   error="exit code 1" job=1001 runner=aaaaaaaa`),
+}
+
+var testJobs = []runner.Job{
+	{
+		ID:         1001,
+		Status:     "success",
+		Project:    "web/app",
+		Started:    time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+		Finished:   time.Date(2024, 1, 2, 10, 0, 42, 0, time.UTC),
+		Duration:   42 * time.Second,
+		RunnerName: "docker-1",
+	},
+	{ID: 1002, Status: "failed", ExitCode: 1},
+}
+
+func TestWriteLogs(t *testing.T) {
+	tests := []struct {
+		format   Format
+		expected string
+	}{
+		{
+			format: Text,
+			expected: `time="2024-01-02T10:00:00Z" level=info msg="Checking for jobs... received" job=1001 runner=aaaaaaaa
+WARNING: Job failed: exit code 1   error="exit code 1" job=1001 runner=aaaaaaaa
+`,
+		},
+		{
+			format: JSONL,
+			expected: `{"time":"2024-01-02T10:00:00Z","level":"info","message":"Checking for jobs... received","fields":{"job":"1001","runner":"aaaaaaaa"},"raw":"time=\"2024-01-02T10:00:00Z\" level=info msg=\"Checking for jobs... received\" job=1001 runner=aaaaaaaa"}
+{"level":"warning","message":"Job failed: exit code 1","fields":{"error":"exit code 1","job":"1001","runner":"aaaaaaaa"},"raw":"WARNING: Job failed: exit code 1   error=\"exit code 1\" job=1001 runner=aaaaaaaa"}
+`,
+		},
+		{
+			format: CSV,
+			expected: `time,level,message,fields,raw
+2024-01-02T10:00:00Z,info,Checking for jobs... received,job=1001 runner=aaaaaaaa,"time=""2024-01-02T10:00:00Z"" level=info msg=""Checking for jobs... received"" job=1001 runner=aaaaaaaa"
+,warning,Job failed: exit code 1,"error=""exit code 1"" job=1001 runner=aaaaaaaa","WARNING: Job failed: exit code 1   error=""exit code 1"" job=1001 runner=aaaaaaaa"
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var b bytes.Buffer
+			if err := WriteLogs(&b, tt.format, testEntries); err != nil {
+				t.Fatalf("WriteLogs failed: %v", err)
+			}
+			if b.String() != tt.expected {
+				t.Errorf("unexpected output:\n%s\nexpected:\n%s", b.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteJobs(t *testing.T) {
+	tests := []struct {
+		format   Format
+		expected string
+	}{
+		{
+			format: Text,
+			expected: `JOB    STATUS   RUNNER    PROJECT  STARTED              DURATION  EXIT CODE
+#1001  success  docker-1  web/app  2024-01-02 10:00:00  42s       -
+#1002  failed   -         -        -                    -         1
+`,
+		},
+		{
+			format: JSONL,
+			expected: `{"id":1001,"status":"success","project":"web/app","started":"2024-01-02T10:00:00Z","finished":"2024-01-02T10:00:42Z","duration_s":42,"runner_name":"docker-1"}
+{"id":1002,"status":"failed","exit_code":1}
+`,
+		},
+		{
+			format: CSV,
+			expected: `id,name,status,stage,project,pipeline,started,finished,duration_s,runner_name,runner_id,exit_code,url
+1001,,success,,web/app,,2024-01-02T10:00:00Z,2024-01-02T10:00:42Z,42,docker-1,,,
+1002,,failed,,,,,,,,,1,
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var b bytes.Buffer
+			if err := WriteJobs(&b, tt.format, testJobs); err != nil {
+				t.Fatalf("WriteJobs failed: %v", err)
+			}
+			if b.String() != tt.expected {
+				t.Errorf("unexpected output:\n%s\nexpected:\n%s", b.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	for path, expected := range map[string]Format{
+		"logs.jsonl":     JSONL,
+		"logs.NDJSON":    JSONL,
+		"jobs.csv":       CSV,
+		"logs.log":       Text,
+		"incident-1234":  Text,
+		"dir.csv/export": Text,
+	} {
+		if got := FormatOf(path); got != expected {
+			t.Errorf("FormatOf(%q) = %q, expected %q", path, got, expected)
+		}
+	}
+}
+
+func TestToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "incident", "jobs.csv")
+
+	written, err := ToFile(path, false, func(w io.Writer, format Format) error {
+		return WriteJobs(w, format, testJobs)
+	})
+	if err != nil {
+		t.Fatalf("ToFile failed: %v", err)
+	}
+	if written != path {
+		t.Errorf("ToFile wrote %q, expected %q", written, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "id,name,status") {
+		t.Errorf("expected CSV from the extension, got %q", data)
+	}
+
+	// An existing file is only replaced when asked to
+	if !Exists(path) {
+		t.Error("expected the written file to exist")
+	}
+	empty := func(io.Writer, Format) error { return nil }
+	if _, err := ToFile(path, false, empty); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("expected the existing file to be kept, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); len(data) == 0 {
+		t.Error("expected the existing file to be unchanged")
+	}
+	if _, err := ToFile(path, true, empty); err != nil {
+		t.Fatalf("ToFile failed: %v", err)
+	}
+	if data, _ := os.ReadFile(path); len(data) != 0 {
+		t.Errorf("expected the file to be replaced, got %q", data)
+	}
+}
+
+func TestToFileWithoutName(t *testing.T) {
+	if _, err := ToFile("  ", false, func(io.Writer, Format) error { return nil }); err == nil {
+		t.Error("expected an error without a file name")
+	}
+}
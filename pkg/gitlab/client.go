@@ -0,0 +1,234 @@
+// Package gitlab talks to the GitLab REST API for runner and job details
+// that gitlab-runner itself does not report.
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultTimeout = 15 * time.Second
+
+// Client is a minimal GitLab API v4 client authenticated with a personal,
+// group or project access token.
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+	ctx     context.Context
+}
+
+// NewClient returns a client for the GitLab instance at baseURL, e.g.
+// https://gitlab.example.com. A nil httpClient uses a client with a
+// 15 second timeout.
+func NewClient(baseURL, token string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    httpClient,
+		ctx:     context.Background(),
+	}
+}
+
+// WithContext returns a copy of the client whose requests are bound to
+// ctx, so several of them can share one deadline.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	copied := *c
+	copied.ctx = ctx
+	return &copied
+}
+
+// RunnerDetails is a runner as returned by GET /runners/:id.
+type RunnerDetails struct {
+	ID           int64      `json:"id"`
+	Description  string     `json:"description"`
+	Paused       bool       `json:"paused"`
+	IsShared     bool       `json:"is_shared"`
+	RunnerType   string     `json:"runner_type"`
+	Online       bool       `json:"online"`
+	Status       string     `json:"status"`
+	IPAddress    string     `json:"ip_address"`
+	TagList      []string   `json:"tag_list"`
+	RunUntagged  bool       `json:"run_untagged"`
+	Locked       bool       `json:"locked"`
+	Architecture string     `json:"architecture"`
+	Platform     string     `json:"platform"`
+	Version      string     `json:"version"`
+	ContactedAt  *time.Time `json:"contacted_at"`
+}
+
+// JobDetails is a job as returned by GET /runners/:id/jobs.
+type JobDetails struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	Stage      string     `json:"stage"`
+	Ref        string     `json:"ref"`
+	CreatedAt  *time.Time `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	Duration   float64    `json:"duration"`
+	WebURL     string     `json:"web_url"`
+	Pipeline   struct {
+		ID int64 `json:"id"`
+	} `json:"pipeline"`
+	Project struct {
+		ID                int64  `json:"id"`
+		Name              string `json:"name"`
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+}
+
+// JobListOptions filters GET /runners/:id/jobs.
+type JobListOptions struct {
+	// Status is one of running, success, failed or canceled. Empty lists
+	// jobs in any state.
+	Status  string
+	PerPage int
+	Page    int
+}
+
+// APIError is a non-2xx response from the API.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("gitlab API returned %d", e.StatusCode)
+	}
+	return fmt.Sprintf("gitlab API returned %d: %s", e.StatusCode, e.Message)
+}
+
+// GetRunner loads the details of the runner with the given ID.
+func (c *Client) GetRunner(id int64) (*RunnerDetails, error) {
+	var details RunnerDetails
+	if err := c.get(fmt.Sprintf("/runners/%d", id), nil, &details); err != nil {
+		return nil, fmt.Errorf("failed to get runner %d: %w", id, err)
+	}
+	return &details, nil
+}
+
+// UpdateRunnerOptions are the runner attributes PUT /runners/:id changes.
+// Nil fields are left as they are.
+type UpdateRunnerOptions struct {
+	Paused      *bool
+	Description *string
+}
+
+// UpdateRunner changes attributes of the runner with the given ID.
+func (c *Client) UpdateRunner(id int64, opts UpdateRunnerOptions) (*RunnerDetails, error) {
+	form := url.Values{}
+	if opts.Paused != nil {
+		form.Set("paused", strconv.FormatBool(*opts.Paused))
+	}
+	if opts.Description != nil {
+		form.Set("description", *opts.Description)
+	}
+
+	var details RunnerDetails
+	if err := c.do(http.MethodPut, fmt.Sprintf("/runners/%d", id), form, &details); err != nil {
+		return nil, fmt.Errorf("failed to update runner %d: %w", id, err)
+	}
+	return &details, nil
+}
+
+// ListRunnerJobs lists the jobs processed by the runner with the given ID,
+// newest first.
+func (c *Client) ListRunnerJobs(id int64, opts JobListOptions) ([]JobDetails, error) {
+	query := url.Values{}
+	query.Set("order_by", "id")
+	query.Set("sort", "desc")
+	if opts.Status != "" {
+		query.Set("status", opts.Status)
+	}
+	if opts.PerPage > 0 {
+		query.Set("per_page", strconv.Itoa(opts.PerPage))
+	}
+	if opts.Page > 0 {
+		query.Set("page", strconv.Itoa(opts.Page))
+	}
+
+	var jobs []JobDetails
+	if err := c.get(fmt.Sprintf("/runners/%d/jobs", id), query, &jobs); err != nil {
+		return nil, fmt.Errorf("failed to list jobs of runner %d: %w", id, err)
+	}
+	return jobs, nil
+}
+
+func (c *Client) get(path string, query url.Values, out any) error {
+	return c.do(http.MethodGet, path, query, out)
+}
+
+func (c *Client) do(method, path string, form url.Values, out any) error {
+	endpoint := c.baseURL + "/api/v4" + path
+
+	var body io.Reader
+	if method == http.MethodGet {
+		if len(form) > 0 {
+			endpoint += "?" + form.Encode()
+		}
+	} else if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(c.ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &APIError{StatusCode: resp.StatusCode, Message: errorMessage(resp.Body)}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// errorMessage extracts the message of an API error body, which is
+// {"message": ...} or {"error": ...}.
+func errorMessage(body io.Reader) string {
+	var payload struct {
+		Message any    `json:"message"`
+		Error   string `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(body, 64*1024))
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return strings.TrimSpace(string(data))
+	}
+	if payload.Message != nil {
+		if s, ok := payload.Message.(string); ok {
+			return s
+		}
+		encoded, _ := json.Marshal(payload.Message)
+		return string(encoded)
+	}
+	return payload.Error
+}
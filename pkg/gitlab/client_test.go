@@ -0,0 +1,173 @@
+package gitlab
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testToken = "glpat-test"
+
+// fakeGitLab stands in for the GitLab API. Routes map "METHOD /path" to a
+// fixture file or handler; every request is recorded.
+type fakeGitLab struct {
+	*httptest.Server
+	mu       sync.Mutex
+	routes   map[string]http.HandlerFunc
+	requests []*http.Request
+}
+
+func newFakeGitLab(t *testing.T) *fakeGitLab {
+	t.Helper()
+	f := &fakeGitLab{routes: make(map[string]http.HandlerFunc)}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.requests = append(f.requests, r)
+		handler, ok := f.routes[r.Method+" "+r.URL.Path]
+		f.mu.Unlock()
+
+		if r.Header.Get("PRIVATE-TOKEN") != testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"401 Unauthorized"}`))
+			return
+		}
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"404 Not found"}`))
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// fixture serves a file from testdata.
+func (f *fakeGitLab) fixture(t *testing.T, route, name string) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.handle(route, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	})
+}
+
+func (f *fakeGitLab) handle(route string, handler http.HandlerFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.routes[route] = handler
+}
+
+func (f *fakeGitLab) recorded() []*http.Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*http.Request(nil), f.requests...)
+}
+
+func (f *fakeGitLab) client() *Client {
+	return NewClient(f.URL+"/", testToken, nil)
+}
+
+func TestClient_GetRunner(t *testing.T) {
+	api := newFakeGitLab(t)
+	api.fixture(t, "GET /api/v4/runners/42", "runner.json")
+
+	details, err := api.client().GetRunner(42)
+	if err != nil {
+		t.Fatalf("GetRunner failed: %v", err)
+	}
+
+	if details.ID != 42 || details.Description != "docker-1" || !details.Online || details.Status != "online" {
+		t.Errorf("unexpected runner: %+v", details)
+	}
+	if details.Architecture != "amd64" || details.Platform != "linux" || details.Version != "17.5.1" {
+		t.Errorf("unexpected platform: %+v", details)
+	}
+	expected := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+	if details.ContactedAt == nil || !details.ContactedAt.Equal(expected) {
+		t.Errorf("ContactedAt = %v, expected %v", details.ContactedAt, expected)
+	}
+}
+
+func TestClient_ListRunnerJobs(t *testing.T) {
+	api := newFakeGitLab(t)
+	api.fixture(t, "GET /api/v4/runners/42/jobs", "jobs.json")
+
+	jobs, err := api.client().ListRunnerJobs(42, JobListOptions{Status: "running", PerPage: 5})
+	if err != nil {
+		t.Fatalf("ListRunnerJobs failed: %v", err)
+	}
+	if len(jobs) != 2 || jobs[0].ID != 1002 || jobs[0].Project.PathWithNamespace != "group/app" || jobs[0].Pipeline.ID != 501 {
+		t.Errorf("unexpected jobs: %+v", jobs)
+	}
+	if jobs[0].FinishedAt != nil || jobs[1].Duration != 120 {
+		t.Errorf("unexpected job times: %+v", jobs)
+	}
+
+	req := api.recorded()[0]
+	if query := req.URL.Query(); query.Get("status") != "running" || query.Get("per_page") != "5" || query.Get("sort") != "desc" {
+		t.Errorf("unexpected query %q", req.URL.RawQuery)
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	api := newFakeGitLab(t)
+
+	_, err := api.client().GetRunner(7)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Message != "404 Not found" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	_, err = NewClient(api.URL, "wrong", nil).GetRunner(42)
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	api.handle("GET /api/v4/runners/9", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("<html>maintenance</html>"))
+	})
+	if _, err := api.client().GetRunner(9); err == nil || !strings.Contains(err.Error(), "failed to decode response") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestClient_UpdateRunner(t *testing.T) {
+	api := newFakeGitLab(t)
+	var form url.Values
+	api.handle("PUT /api/v4/runners/42", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		form = r.PostForm
+		_, _ = w.Write([]byte(`{"id": 42, "paused": true, "description": "docker-1"}`))
+	})
+
+	paused := true
+	details, err := api.client().UpdateRunner(42, UpdateRunnerOptions{Paused: &paused})
+	if err != nil {
+		t.Fatalf("UpdateRunner failed: %v", err)
+	}
+	if !details.Paused {
+		t.Errorf("unexpected runner: %+v", details)
+	}
+	if form.Get("paused") != "true" || form.Has("description") {
+		t.Errorf("unexpected form %v", form)
+	}
+
+	description := "docker on ci-01"
+	if _, err := api.client().UpdateRunner(42, UpdateRunnerOptions{Description: &description}); err != nil {
+		t.Fatal(err)
+	}
+	if form.Get("description") != description || form.Has("paused") {
+		t.Errorf("unexpected form %v", form)
+	}
+}
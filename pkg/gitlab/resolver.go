@@ -0,0 +1,42 @@
+package gitlab
+
+import (
+	"sync"
+	"time"
+
+	"github.com/larkinwc/gitlab-runner-tui/pkg/config"
+)
+
+const resolverTTL = 30 * time.Second
+
+// ConfigResolver resolves runner IDs from the id keys gitlab-runner writes
+// to config.toml when a runner is registered. The file is read again at
+// most every 30 seconds, so newly registered runners show up without
+// reading it for every API call.
+func ConfigResolver(cm *config.TOMLConfigManager) Resolver {
+	var mu sync.Mutex
+	var ids map[string]int64
+	var loaded time.Time
+
+	return func() (map[string]int64, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if ids != nil && time.Since(loaded) < resolverTTL {
+			return ids, nil
+		}
+
+		if err := cm.Load(); err != nil {
+			return nil, err
+		}
+
+		ids = make(map[string]int64)
+		for _, r := range cm.GetConfig().Runners {
+			if r.ID != 0 {
+				ids[r.Name] = r.ID
+			}
+		}
+		loaded = time.Now()
+		return ids, nil
+	}
+}
@@ -0,0 +1,320 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+// Resolver maps runner names, as listed by gitlab-runner, to their numeric
+// API IDs.
+type Resolver func() (map[string]int64, error)
+
+// detailsMaxAge is how long GetRunnerStatus reuses the details loaded by
+// ListRunners; the Runners tab asks for the status right after listing.
+const detailsMaxAge = 10 * time.Second
+
+// Service adds details from the GitLab API to a runner.Service. Runners the
+// resolver has no ID for, and API failures, leave the data of the wrapped
+// service as it is, so the TUI keeps working without API access.
+type Service struct {
+	runner.Service
+	client  *Client
+	resolve Resolver
+
+	mu      sync.Mutex
+	details map[string]runnerDetails // by runner name
+}
+
+// runnerDetails is what the API reported for a runner. A nil runner means
+// the API failed, which is kept as well so a refresh waits for it only once.
+type runnerDetails struct {
+	runner *RunnerDetails
+	job    *runner.Job
+	loaded time.Time
+}
+
+func (d runnerDetails) apply(r *runner.Runner) {
+	if d.runner == nil {
+		return
+	}
+	applyRunner(r, d.runner)
+	if d.job != nil {
+		job := *d.job
+		r.CurrentJob = &job
+	}
+}
+
+func NewService(base runner.Service, client *Client, resolve Resolver) *Service {
+	return &Service{
+		Service: base,
+		client:  client,
+		resolve: resolve,
+	}
+}
+
+func (s *Service) ListRunners() ([]runner.Runner, error) {
+	runners, err := s.Service.ListRunners()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(runners))
+	for i := range runners {
+		names[i] = runners[i].Name
+	}
+	details := s.load(names, s.ids())
+	for i := range runners {
+		details[runners[i].Name].apply(&runners[i])
+	}
+	return runners, nil
+}
+
+// GetRunnerStatus reuses the details of the last ListRunners while they are
+// recent, and loads them otherwise.
+func (s *Service) GetRunnerStatus(name string) (*runner.Runner, error) {
+	r, err := s.Service.GetRunnerStatus(name)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	details, ok := s.details[name]
+	s.mu.Unlock()
+	if !ok || time.Since(details.loaded) > detailsMaxAge {
+		details = s.load([]string{name}, s.ids())[name]
+	}
+	details.apply(r)
+	return r, nil
+}
+
+// GetJobHistory returns the latest jobs of all runners with a known ID from
+// the API, newest first. Without any, it falls back to the wrapped service.
+func (s *Service) GetJobHistory(limit int) ([]runner.Job, error) {
+	ids := s.ids()
+	if len(ids) == 0 {
+		return s.Service.GetJobHistory(limit)
+	}
+
+	jobs, err := s.listJobs(ids, JobListOptions{PerPage: limit})
+	if err != nil {
+		return s.Service.GetJobHistory(limit)
+	}
+	if len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs, nil
+}
+
+// GetRunningJobs returns the running jobs of all runners with a known ID
+// from the API, newest first. Without any, or when the API fails, it falls
+// back to the wrapped service.
+func (s *Service) GetRunningJobs() ([]runner.Job, error) {
+	ids := s.ids()
+	if len(ids) == 0 {
+		return s.Service.GetRunningJobs()
+	}
+
+	jobs, err := s.listJobs(ids, JobListOptions{Status: "running", PerPage: 100})
+	if err != nil {
+		return s.Service.GetRunningJobs()
+	}
+	return jobs, nil
+}
+
+// listJobs lists the jobs of every runner in ids, newest first. It fails
+// when the jobs of any runner cannot be listed.
+func (s *Service) listJobs(ids map[string]int64, opts JobListOptions) ([]runner.Job, error) {
+	var (
+		mu       sync.Mutex
+		jobs     []runner.Job
+		firstErr error
+	)
+	s.each(sortedNames(ids), func(client *Client, name string) {
+		details, err := client.ListRunnerJobs(ids[name], opts)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return
+		}
+		for i := range details {
+			jobs = append(jobs, toJob(&details[i], name, ids[name]))
+		}
+	})
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].ID > jobs[j].ID
+	})
+	return jobs, nil
+}
+
+// PauseRunner pauses or resumes the runner in GitLab. A paused runner
+// stays registered but does not pick up new jobs.
+func (s *Service) PauseRunner(name string, paused bool) error {
+	id, err := s.id(name)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.UpdateRunner(id, UpdateRunnerOptions{Paused: &paused})
+	return err
+}
+
+// SetRunnerDescription changes the description GitLab shows for the runner.
+func (s *Service) SetRunnerDescription(name, description string) error {
+	id, err := s.id(name)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.UpdateRunner(id, UpdateRunnerOptions{Description: &description})
+	return err
+}
+
+func (s *Service) id(name string) (int64, error) {
+	ids, err := s.resolve()
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve runner %s: %w", name, err)
+	}
+	id, ok := ids[name]
+	if !ok {
+		return 0, fmt.Errorf("runner %s has no id in config.toml", name)
+	}
+	return id, nil
+}
+
+func (s *Service) ids() map[string]int64 {
+	ids, err := s.resolve()
+	if err != nil {
+		return nil
+	}
+	return ids
+}
+
+// maxRequests is the number of API requests a refresh runs at a time.
+const maxRequests = 8
+
+// each calls fn for every name concurrently, maxRequests at a time. The
+// requests of the client fn gets share one deadline, so a slow or
+// unreachable API delays a refresh by the client timeout at most.
+func (s *Service) each(names []string, fn func(client *Client, name string)) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+	client := s.client.WithContext(ctx)
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, maxRequests)
+	for _, name := range names {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			fn(client, name)
+		}()
+	}
+	wg.Wait()
+}
+
+// load fetches the details of the named runners with a known ID.
+func (s *Service) load(names []string, ids map[string]int64) map[string]runnerDetails {
+	var known []string
+	for _, name := range names {
+		if _, ok := ids[name]; ok {
+			known = append(known, name)
+		}
+	}
+
+	var mu sync.Mutex
+	loaded := make(map[string]runnerDetails)
+	s.each(known, func(client *Client, name string) {
+		details := fetchDetails(client, name, ids[name])
+		mu.Lock()
+		loaded[name] = details
+		mu.Unlock()
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.details == nil {
+		s.details = make(map[string]runnerDetails)
+	}
+	for name, details := range loaded {
+		s.details[name] = details
+	}
+	return loaded
+}
+
+func fetchDetails(client *Client, name string, id int64) runnerDetails {
+	details := runnerDetails{loaded: time.Now()}
+
+	r, err := client.GetRunner(id)
+	if err != nil {
+		return details
+	}
+	details.runner = r
+
+	if jobs, err := client.ListRunnerJobs(id, JobListOptions{Status: "running", PerPage: 1}); err == nil && len(jobs) > 0 {
+		job := toJob(&jobs[0], name, id)
+		details.job = &job
+	}
+	return details
+}
+
+func applyRunner(r *runner.Runner, d *RunnerDetails) {
+	r.Online = d.Online
+	r.Paused = d.Paused
+	r.Status = d.Status
+	if d.Paused {
+		r.Status = "paused"
+	}
+	r.Description = d.Description
+	r.TagList = d.TagList
+	r.Locked = d.Locked
+	r.RunsUntagged = d.RunUntagged
+	r.Architecture = d.Architecture
+	r.Platform = d.Platform
+	if d.ContactedAt != nil {
+		r.LastContact = *d.ContactedAt
+	}
+}
+
+func toJob(d *JobDetails, runnerName string, runnerID int64) runner.Job {
+	job := runner.Job{
+		ID:         int(d.ID),
+		Name:       d.Name,
+		Status:     d.Status,
+		Stage:      d.Stage,
+		Project:    d.Project.PathWithNamespace,
+		Pipeline:   int(d.Pipeline.ID),
+		Duration:   time.Duration(d.Duration * float64(time.Second)),
+		RunnerName: runnerName,
+		RunnerID:   strconv.FormatInt(runnerID, 10),
+		URL:        d.WebURL,
+	}
+	if d.StartedAt != nil {
+		job.Started = *d.StartedAt
+	}
+	if d.FinishedAt != nil {
+		job.Finished = *d.FinishedAt
+	}
+	return job
+}
+
+func sortedNames(ids map[string]int64) []string {
+	names := make([]string, 0, len(ids))
+	for name := range ids {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,272 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/larkinwc/gitlab-runner-tui/pkg/config"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+// baseService reports what gitlab-runner list and verify know.
+type baseService struct {
+	runner.Service
+}
+
+func (baseService) ListRunners() ([]runner.Runner, error) {
+	return []runner.Runner{
+		{Name: "docker-1", ID: "glrt-aaa", Executor: "docker", Status: "unknown"},
+		{Name: "shell-1", ID: "glrt-bbb", Executor: "shell", Status: "unknown"},
+	}, nil
+}
+
+func (b baseService) GetRunnerStatus(name string) (*runner.Runner, error) {
+	runners, _ := b.ListRunners()
+	for i := range runners {
+		if runners[i].Name == name {
+			runners[i].Status = "inactive"
+			return &runners[i], nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (baseService) GetJobHistory(_ int) ([]runner.Job, error) {
+	return []runner.Job{{ID: 1, RunnerName: "from-logs"}}, nil
+}
+
+func (baseService) GetRunningJobs() ([]runner.Job, error) {
+	return []runner.Job{{ID: 2, Status: "running", RunnerName: "from-logs"}}, nil
+}
+
+func staticIDs(ids map[string]int64) Resolver {
+	return func() (map[string]int64, error) { return ids, nil }
+}
+
+func newAPIService(t *testing.T) *Service {
+	t.Helper()
+	api := newFakeGitLab(t)
+	api.fixture(t, "GET /api/v4/runners/42", "runner.json")
+	api.fixture(t, "GET /api/v4/runners/42/jobs", "jobs.json")
+	return NewService(baseService{}, api.client(), staticIDs(map[string]int64{"docker-1": 42}))
+}
+
+func TestService_ListRunners(t *testing.T) {
+	runners, err := newAPIService(t).ListRunners()
+	if err != nil {
+		t.Fatalf("ListRunners failed: %v", err)
+	}
+
+	r := runners[0]
+	if !r.Online || r.Status != "online" || r.Architecture != "amd64" || r.Platform != "linux" {
+		t.Errorf("runner not enriched: %+v", r)
+	}
+	if r.LastContact.IsZero() || len(r.TagList) != 2 || !r.RunsUntagged {
+		t.Errorf("runner details missing: %+v", r)
+	}
+	if r.CurrentJob == nil || r.CurrentJob.ID != 1002 || r.CurrentJob.Project != "group/app" || r.CurrentJob.RunnerID != "42" {
+		t.Errorf("current job missing: %+v", r.CurrentJob)
+	}
+	if r.Executor != "docker" || r.ID != "glrt-aaa" {
+		t.Errorf("local details overwritten: %+v", r)
+	}
+
+	// Runners without an ID in config.toml keep the local data
+	if runners[1].Online || runners[1].Status != "unknown" || runners[1].CurrentJob != nil {
+		t.Errorf("runner without ID changed: %+v", runners[1])
+	}
+}
+
+func TestService_GetRunnerStatus(t *testing.T) {
+	service := newAPIService(t)
+
+	r, err := service.GetRunnerStatus("docker-1")
+	if err != nil || !r.Online || r.Status != "online" {
+		t.Errorf("GetRunnerStatus = %+v, %v", r, err)
+	}
+
+	r, err = service.GetRunnerStatus("shell-1")
+	if err != nil || r.Status != "inactive" {
+		t.Errorf("GetRunnerStatus = %+v, %v", r, err)
+	}
+}
+
+func TestService_ReusesDetails(t *testing.T) {
+	api := newFakeGitLab(t)
+	api.fixture(t, "GET /api/v4/runners/42", "runner.json")
+	api.fixture(t, "GET /api/v4/runners/42/jobs", "jobs.json")
+	service := NewService(baseService{}, api.client(), staticIDs(map[string]int64{"docker-1": 42, "shell-1": 43}))
+
+	if _, err := service.ListRunners(); err != nil {
+		t.Fatalf("ListRunners failed: %v", err)
+	}
+	requests := len(api.recorded())
+	if requests != 3 {
+		t.Fatalf("expected 3 requests, got %d", requests)
+	}
+
+	// The Runners tab asks for the status of each listed runner
+	r, err := service.GetRunnerStatus("docker-1")
+	if err != nil || !r.Online || r.CurrentJob == nil || r.CurrentJob.ID != 1002 {
+		t.Errorf("GetRunnerStatus = %+v, %v", r, err)
+	}
+	if r, err := service.GetRunnerStatus("shell-1"); err != nil || r.Status != "inactive" {
+		t.Errorf("GetRunnerStatus = %+v, %v", r, err)
+	}
+	if got := len(api.recorded()); got != requests {
+		t.Errorf("expected the listed details to be reused, got %d more requests", got-requests)
+	}
+}
+
+func TestService_APIUnavailable(t *testing.T) {
+	api := newFakeGitLab(t)
+	service := NewService(baseService{}, api.client(), staticIDs(map[string]int64{"docker-1": 42}))
+
+	runners, err := service.ListRunners()
+	if err != nil || runners[0].Status != "unknown" {
+		t.Errorf("ListRunners = %+v, %v", runners, err)
+	}
+
+	jobs, err := service.GetJobHistory(10)
+	if err != nil || len(jobs) != 1 || jobs[0].RunnerName != "from-logs" {
+		t.Errorf("expected fallback to the wrapped service, got %+v, %v", jobs, err)
+	}
+
+	jobs, err = service.GetRunningJobs()
+	if err != nil || len(jobs) != 1 || jobs[0].RunnerName != "from-logs" {
+		t.Errorf("expected running jobs from the wrapped service, got %+v, %v", jobs, err)
+	}
+}
+
+func TestService_GetJobHistory(t *testing.T) {
+	jobs, err := newAPIService(t).GetJobHistory(10)
+	if err != nil {
+		t.Fatalf("GetJobHistory failed: %v", err)
+	}
+	if len(jobs) != 2 || jobs[0].ID != 1002 || jobs[1].ID != 1001 {
+		t.Fatalf("unexpected jobs: %+v", jobs)
+	}
+
+	job := jobs[1]
+	if job.Status != "success" || job.Stage != "build" || job.Pipeline != 500 || job.RunnerName != "docker-1" {
+		t.Errorf("unexpected job: %+v", job)
+	}
+	if job.Duration != 2*time.Minute || job.Finished.Sub(job.Started) != 2*time.Minute {
+		t.Errorf("unexpected job times: %+v", job)
+	}
+}
+
+func TestService_GetRunningJobs(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "jobs.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := newFakeGitLab(t)
+	api.handle("GET /api/v4/runners/42/jobs", func(w http.ResponseWriter, r *http.Request) {
+		var jobs, matching []map[string]any
+		_ = json.Unmarshal(data, &jobs)
+		for _, job := range jobs {
+			if job["status"] == r.URL.Query().Get("status") {
+				matching = append(matching, job)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(matching)
+	})
+	service := NewService(baseService{}, api.client(), staticIDs(map[string]int64{"docker-1": 42}))
+
+	jobs, err := service.GetRunningJobs()
+	if err != nil {
+		t.Fatalf("GetRunningJobs failed: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != 1002 || jobs[0].RunnerName != "docker-1" || jobs[0].Project != "group/app" {
+		t.Errorf("unexpected running jobs: %+v", jobs)
+	}
+}
+
+func TestService_ListsJobsConcurrently(t *testing.T) {
+	// Each runner's jobs are only served once the other runner's are asked
+	// for, which requests made one at a time never get to
+	arrived := make(chan struct{}, 2)
+	api := newFakeGitLab(t)
+	for _, id := range []int{42, 43} {
+		api.handle(fmt.Sprintf("GET /api/v4/runners/%d/jobs", id), func(w http.ResponseWriter, _ *http.Request) {
+			arrived <- struct{}{}
+			timeout := time.After(2 * time.Second)
+			for len(arrived) < 2 {
+				select {
+				case <-time.After(10 * time.Millisecond):
+				case <-timeout:
+					w.WriteHeader(http.StatusGatewayTimeout)
+					return
+				}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `[{"id":%d,"status":"running"}]`, id*100)
+		})
+	}
+	service := NewService(baseService{}, api.client(), staticIDs(map[string]int64{"docker-1": 42, "shell-1": 43}))
+
+	jobs, err := service.GetRunningJobs()
+	if err != nil || len(jobs) != 2 || jobs[0].ID != 4300 || jobs[1].RunnerName != "docker-1" {
+		t.Errorf("GetRunningJobs = %+v, %v", jobs, err)
+	}
+}
+
+func TestConfigResolver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := `concurrent = 1
+
+[[runners]]
+  name = "docker-1"
+  id = 42
+  url = "https://gitlab.example.com"
+  token = "glrt-aaa"
+  executor = "docker"
+
+[[runners]]
+  name = "legacy"
+  url = "https://gitlab.example.com"
+  token = "abcdef"
+  executor = "shell"
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := ConfigResolver(config.NewTOMLConfigManager(path))()
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if len(ids) != 1 || ids["docker-1"] != 42 {
+		t.Errorf("unexpected IDs: %v", ids)
+	}
+}
+
+func TestService_PauseRunner(t *testing.T) {
+	api := newFakeGitLab(t)
+	var paused string
+	api.handle("PUT /api/v4/runners/42", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		paused = r.PostForm.Get("paused")
+		_, _ = w.Write([]byte(`{"id": 42}`))
+	})
+	service := NewService(baseService{}, api.client(), staticIDs(map[string]int64{"docker-1": 42}))
+
+	var admin runner.Administrator = service
+	if err := admin.PauseRunner("docker-1", false); err != nil || paused != "false" {
+		t.Errorf("PauseRunner = %v, paused = %q", err, paused)
+	}
+
+	err := admin.SetRunnerDescription("shell-1", "x")
+	if err == nil || !strings.Contains(err.Error(), "has no id in config.toml") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
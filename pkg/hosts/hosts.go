@@ -0,0 +1,97 @@
+// Package hosts loads the profiles of remote runner hosts the TUI can manage
+// over SSH.
+package hosts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+// Host is the profile of one machine running gitlab-runner.
+type Host struct {
+	Name         string `toml:"name"`
+	Address      string `toml:"address"`
+	Port         int    `toml:"port,omitempty"`
+	User         string `toml:"user,omitempty"`
+	IdentityFile string `toml:"identity_file,omitempty"`
+	// ConfigPath is the path of config.toml on the host. Empty means the
+	// default /etc/gitlab-runner/config.toml.
+	ConfigPath string `toml:"config_path,omitempty"`
+	Sudo       bool   `toml:"sudo,omitempty"`
+}
+
+type hostsFile struct {
+	Hosts []Host `toml:"hosts"`
+}
+
+// DefaultPath returns the hosts file location,
+// $XDG_CONFIG_HOME/gitlab-runner-tui/hosts.toml or its ~/.config equivalent.
+func DefaultPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "gitlab-runner-tui", "hosts.toml")
+}
+
+// Load reads the host profiles from path. A missing file means no hosts.
+func Load(path string) ([]Host, error) {
+	var file hostsFile
+	if _, err := toml.DecodeFile(path, &file); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load hosts file: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for i, h := range file.Hosts {
+		if h.Name == "" {
+			return nil, fmt.Errorf("host %d has no name", i)
+		}
+		if h.Address == "" {
+			return nil, fmt.Errorf("host %s has no address", h.Name)
+		}
+		if seen[h.Name] {
+			return nil, fmt.Errorf("duplicate host %s", h.Name)
+		}
+		seen[h.Name] = true
+	}
+
+	return file.Hosts, nil
+}
+
+// Find returns the host called name.
+func Find(hosts []Host, name string) (*Host, error) {
+	for i := range hosts {
+		if hosts[i].Name == name {
+			return &hosts[i], nil
+		}
+	}
+	return nil, fmt.Errorf("host %s not found", name)
+}
+
+// Target returns where commands for the host are run.
+func (h Host) Target() runner.SSHTarget {
+	return runner.SSHTarget{
+		Address:      h.Address,
+		Port:         h.Port,
+		User:         h.User,
+		IdentityFile: h.IdentityFile,
+		Sudo:         h.Sudo,
+	}
+}
+
+// Executor returns an executor running commands on the host over SSH.
+func (h Host) Executor() runner.Executor {
+	return runner.NewSSHExecutor(h.Target(), runner.NewLocalExecutor())
+}
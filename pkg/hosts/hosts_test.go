@@ -0,0 +1,91 @@
+package hosts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+func writeHosts(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "hosts.toml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeHosts(t, `
+[[hosts]]
+  name = "ci-01"
+  address = "ci-01.example.com"
+  user = "ops"
+  identity_file = "~/.ssh/ci"
+  sudo = true
+
+[[hosts]]
+  name = "ci-02"
+  address = "10.0.0.12"
+  port = 2222
+  config_path = "/home/gitlab-runner/.gitlab-runner/config.toml"
+`)
+
+	hosts, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(hosts) != 2 {
+		t.Fatalf("expected 2 hosts, got %d", len(hosts))
+	}
+
+	h, err := Find(hosts, "ci-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Port != 2222 || h.ConfigPath != "/home/gitlab-runner/.gitlab-runner/config.toml" {
+		t.Errorf("unexpected host: %+v", h)
+	}
+
+	target := hosts[0].Target()
+	expected := runner.SSHTarget{Address: "ci-01.example.com", User: "ops", IdentityFile: "~/.ssh/ci", Sudo: true}
+	if target != expected {
+		t.Errorf("Target = %+v, expected %+v", target, expected)
+	}
+
+	if _, err := Find(hosts, "ci-03"); err == nil {
+		t.Error("expected error for unknown host")
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	hosts, err := Load(filepath.Join(t.TempDir(), "hosts.toml"))
+	if err != nil || hosts != nil {
+		t.Errorf("Load = %v, %v, expected no hosts", hosts, err)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		errText string
+	}{
+		{"Missing name", "[[hosts]]\naddress = \"a\"\n", "has no name"},
+		{"Missing address", "[[hosts]]\nname = \"a\"\n", "has no address"},
+		{"Duplicate", "[[hosts]]\nname = \"a\"\naddress = \"a\"\n[[hosts]]\nname = \"a\"\naddress = \"b\"\n", "duplicate host"},
+		{"Syntax", "[[hosts]\n", "failed to load hosts file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeHosts(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.errText) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+// Package jobstats aggregates job history over a time window: success
+// rates and durations per runner and per project, and job counts over time.
+package jobstats
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+// Window is a span of time back from now that statistics cover. Jobs are
+// counted in buckets of Bucket each for charts.
+type Window struct {
+	Name   string
+	Span   time.Duration
+	Bucket time.Duration
+}
+
+// Windows are the windows offered, shortest first.
+var Windows = []Window{
+	{Name: "1h", Span: time.Hour, Bucket: 5 * time.Minute},
+	{Name: "24h", Span: 24 * time.Hour, Bucket: time.Hour},
+	{Name: "7d", Span: 7 * 24 * time.Hour, Bucket: 6 * time.Hour},
+}
+
+// Group is the statistics of the jobs of one runner or project, or of all
+// jobs in the window.
+type Group struct {
+	Name      string
+	Total     int
+	Succeeded int
+	Failed    int
+	P50       time.Duration
+	P95       time.Duration
+
+	durations []time.Duration
+}
+
+// SuccessRate is the share of finished jobs that succeeded, from 0 to 1.
+// It is 0 without finished jobs.
+func (g Group) SuccessRate() float64 {
+	if g.Succeeded+g.Failed == 0 {
+		return 0
+	}
+	return float64(g.Succeeded) / float64(g.Succeeded+g.Failed)
+}
+
+// Summary is the statistics of the jobs in a window.
+type Summary struct {
+	Window Window
+	All    Group
+
+	// JobsPerHour is the average number of jobs started per hour
+	JobsPerHour float64
+
+	// Jobs and Failures count the jobs started in each bucket, oldest first
+	Jobs     []int
+	Failures []int
+
+	// ByRunner and ByProject are ordered by job count, most first
+	ByRunner  []Group
+	ByProject []Group
+
+	// TopFailing are the projects with failed jobs, most failures first
+	TopFailing []Group
+}
+
+// Compute aggregates the jobs that started within window before now. Jobs
+// without a start time are placed by their finish time; jobs without
+// either are left out.
+func Compute(jobs []runner.Job, window Window, now time.Time) Summary {
+	buckets := int(window.Span / window.Bucket)
+	s := Summary{
+		Window:   window,
+		All:      Group{Name: "all"},
+		Jobs:     make([]int, buckets),
+		Failures: make([]int, buckets),
+	}
+	start := now.Add(-window.Span)
+
+	runners := make(map[string]*Group)
+	projects := make(map[string]*Group)
+	for _, job := range jobs {
+		at := job.Started
+		if at.IsZero() {
+			at = job.Finished
+		}
+		if at.IsZero() || at.Before(start) || at.After(now) {
+			continue
+		}
+
+		bucket := min(int(at.Sub(start)/window.Bucket), buckets-1)
+		s.Jobs[bucket]++
+		if Failed(job.Status) {
+			s.Failures[bucket]++
+		}
+
+		s.All.add(job)
+		group(runners, job.RunnerName).add(job)
+		group(projects, job.Project).add(job)
+	}
+
+	s.All.finish()
+	s.JobsPerHour = float64(s.All.Total) / window.Span.Hours()
+	s.ByRunner = sorted(runners)
+	s.ByProject = sorted(projects)
+	for _, g := range s.ByProject {
+		if g.Failed > 0 {
+			s.TopFailing = append(s.TopFailing, g)
+		}
+	}
+	sort.SliceStable(s.TopFailing, func(i, j int) bool {
+		return s.TopFailing[i].Failed > s.TopFailing[j].Failed
+	})
+	return s
+}
+
+// Succeeded reports whether a job status is a success.
+func Succeeded(status string) bool {
+	switch strings.ToLower(status) {
+	case "success", "passed", "completed":
+		return true
+	}
+	return false
+}
+
+// Failed reports whether a job status is a failure.
+func Failed(status string) bool {
+	switch strings.ToLower(status) {
+	case "failed", "error":
+		return true
+	}
+	return false
+}
+
+func group(groups map[string]*Group, name string) *Group {
+	if name == "" {
+		name = "unknown"
+	}
+	g, ok := groups[name]
+	if !ok {
+		g = &Group{Name: name}
+		groups[name] = g
+	}
+	return g
+}
+
+func (g *Group) add(job runner.Job) {
+	g.Total++
+	switch {
+	case Succeeded(job.Status):
+		g.Succeeded++
+	case Failed(job.Status):
+		g.Failed++
+	}
+	if job.Duration > 0 {
+		g.durations = append(g.durations, job.Duration)
+	}
+}
+
+// finish computes the duration percentiles once all jobs are added.
+func (g *Group) finish() {
+	sort.Slice(g.durations, func(i, j int) bool {
+		return g.durations[i] < g.durations[j]
+	})
+	g.P50 = percentile(g.durations, 50)
+	g.P95 = percentile(g.durations, 95)
+	g.durations = nil
+}
+
+// sorted returns the groups by job count, most first, then by name.
+func sorted(groups map[string]*Group) []Group {
+	list := make([]Group, 0, len(groups))
+	for _, g := range groups {
+		g.finish()
+		list = append(list, *g)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Total != list[j].Total {
+			return list[i].Total > list[j].Total
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// percentile returns the nearest-rank percentile p of sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
@@ -0,0 +1,90 @@
+package jobstats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+var now = time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+
+func job(id int, ago time.Duration, status, runnerName, project string, duration time.Duration) runner.Job {
+	return runner.Job{
+		ID:         id,
+		Status:     status,
+		RunnerName: runnerName,
+		Project:    project,
+		Started:    now.Add(-ago),
+		Duration:   duration,
+	}
+}
+
+func TestCompute(t *testing.T) {
+	jobs := []runner.Job{
+		job(1, 10*time.Minute, "success", "docker-1", "web/app", 30*time.Second),
+		job(2, 20*time.Minute, "failed", "docker-1", "web/app", 60*time.Second),
+		job(3, 30*time.Minute, "failed", "shell-1", "api", 90*time.Second),
+		job(4, 40*time.Minute, "success", "docker-1", "api", 120*time.Second),
+		job(5, 55*time.Minute, "failed", "docker-1", "web/app", 600*time.Second),
+		job(6, 2*time.Hour, "success", "docker-1", "web/app", time.Second),
+		{ID: 7, Status: "success"},
+	}
+
+	s := Compute(jobs, Windows[0], now)
+
+	if s.All.Total != 5 || s.All.Succeeded != 2 || s.All.Failed != 3 {
+		t.Errorf("unexpected totals %+v", s.All)
+	}
+	if rate := s.All.SuccessRate(); rate != 0.4 {
+		t.Errorf("SuccessRate = %v, expected 0.4", rate)
+	}
+	if s.All.P50 != 90*time.Second || s.All.P95 != 600*time.Second {
+		t.Errorf("P50/P95 = %v/%v, expected 1m30s/10m0s", s.All.P50, s.All.P95)
+	}
+	if s.JobsPerHour != 5 {
+		t.Errorf("JobsPerHour = %v, expected 5", s.JobsPerHour)
+	}
+
+	if len(s.ByRunner) != 2 || s.ByRunner[0].Name != "docker-1" || s.ByRunner[0].Total != 4 {
+		t.Errorf("unexpected runners %+v", s.ByRunner)
+	}
+	if len(s.TopFailing) != 2 || s.TopFailing[0].Name != "web/app" || s.TopFailing[0].Failed != 2 {
+		t.Errorf("unexpected top failing projects %+v", s.TopFailing)
+	}
+
+	// Buckets of 5 minutes run oldest first: 55m ago falls in the second,
+	// 10m ago in the eleventh of twelve
+	expected := []int{0, 1, 0, 0, 1, 0, 1, 0, 1, 0, 1, 0}
+	for i := range expected {
+		if s.Jobs[i] != expected[i] {
+			t.Fatalf("Jobs = %v, expected %v", s.Jobs, expected)
+		}
+	}
+	if s.Failures[1] != 1 || s.Failures[10] != 0 {
+		t.Errorf("unexpected failures %v", s.Failures)
+	}
+}
+
+func TestCompute_Empty(t *testing.T) {
+	s := Compute(nil, Windows[2], now)
+	if s.All.Total != 0 || s.All.SuccessRate() != 0 || len(s.Jobs) != 28 {
+		t.Errorf("unexpected empty summary %+v", s)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var durations []time.Duration
+	for i := 1; i <= 100; i++ {
+		durations = append(durations, time.Duration(i)*time.Second)
+	}
+	if p := percentile(durations, 50); p != 50*time.Second {
+		t.Errorf("p50 = %v, expected 50s", p)
+	}
+	if p := percentile(durations, 95); p != 95*time.Second {
+		t.Errorf("p95 = %v, expected 95s", p)
+	}
+	if p := percentile(durations[:1], 95); p != time.Second {
+		t.Errorf("p95 of one = %v, expected 1s", p)
+	}
+}
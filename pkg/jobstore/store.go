@@ -0,0 +1,409 @@
+// Package jobstore keeps the job history of runner hosts in local files, so
+// history outlives the journal window and restarts of the TUI.
+package jobstore
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+// IngestLimit is the number of recent jobs read from a host per ingest.
+const IngestLimit = 200
+
+// Retention bounds the jobs a store keeps. Jobs beyond it are dropped when
+// the file is compacted.
+type Retention struct {
+	// MaxJobs is the number of newest jobs kept; 0 keeps all.
+	MaxJobs int
+	// MaxAge drops jobs that finished, or started, longer ago; 0 keeps
+	// jobs of any age.
+	MaxAge time.Duration
+}
+
+// DefaultRetention keeps the newest 10000 jobs of the last 90 days.
+var DefaultRetention = Retention{MaxJobs: 10000, MaxAge: 90 * 24 * time.Hour}
+
+// DefaultDir returns the directory of the job history files,
+// $XDG_STATE_HOME/gitlab-runner-tui/jobs or its ~/.local/state equivalent.
+func DefaultDir() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "gitlab-runner-tui", "jobs")
+}
+
+// Store is the job history of one host. It is kept in memory and in an
+// append-only file of JSON lines, one per new or changed job; when a job
+// is written again, its last line wins.
+type Store struct {
+	path      string
+	retention Retention
+
+	mu    sync.Mutex
+	jobs  map[int]runner.Job
+	lines int
+}
+
+// Open loads the store at path with the default retention.
+func Open(path string) (*Store, error) {
+	return OpenWithRetention(path, DefaultRetention)
+}
+
+// OpenWithRetention loads the store at path, creating its directory if
+// needed. A missing file is an empty store. Files holding mostly
+// superseded lines, or jobs the retention drops, are compacted.
+func OpenWithRetention(path string, retention Retention) (*Store, error) {
+	s := &Store{path: path, retention: retention, jobs: make(map[int]runner.Job)}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create job history directory: %w", err)
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open job history: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var job runner.Job
+		// A line cut short by a crash is skipped rather than failing the
+		// whole history
+		if err := json.Unmarshal(scanner.Bytes(), &job); err != nil || job.ID == 0 {
+			continue
+		}
+		s.jobs[job.ID] = job
+		s.lines++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read job history: %w", err)
+	}
+
+	if s.prune(time.Now()) > 0 || s.overgrown() {
+		if err := s.compact(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Add records jobs, merging what is known about jobs already stored, and
+// returns how many were new or changed.
+func (s *Store) Add(jobs []runner.Job) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var changed []runner.Job
+	for _, job := range jobs {
+		if job.ID == 0 {
+			continue
+		}
+		merged := job
+		if existing, ok := s.jobs[job.ID]; ok {
+			merged = merge(existing, job)
+			if same(merged, existing) {
+				continue
+			}
+		}
+		s.jobs[job.ID] = merged
+		changed = append(changed, merged)
+	}
+	if len(changed) == 0 {
+		return 0, nil
+	}
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open job history: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	encoder := json.NewEncoder(w)
+	for _, job := range changed {
+		if err := encoder.Encode(job); err != nil {
+			return 0, fmt.Errorf("failed to write job history: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return 0, fmt.Errorf("failed to write job history: %w", err)
+	}
+	s.lines += len(changed)
+
+	if s.overgrown() {
+		s.prune(time.Now())
+		if err := s.compact(); err != nil {
+			return len(changed), err
+		}
+	}
+	return len(changed), nil
+}
+
+// Query selects a page of jobs.
+type Query struct {
+	// Match selects the jobs; nil selects all of them.
+	Match func(runner.Job) bool
+	// Sort orders the selected jobs in place; nil lists the newest first.
+	Sort func([]runner.Job)
+	// Offset and Limit are the page. A Limit of 0 returns every selected
+	// job from Offset on.
+	Offset, Limit int
+}
+
+// Select returns the page of jobs the query selects and how many jobs it
+// selects in all.
+func (q Query) Select(jobs []runner.Job) ([]runner.Job, int) {
+	var selected []runner.Job
+	for _, job := range jobs {
+		if q.Match == nil || q.Match(job) {
+			selected = append(selected, job)
+		}
+	}
+	return q.page(selected), len(selected)
+}
+
+func (q Query) page(jobs []runner.Job) []runner.Job {
+	if q.Sort != nil {
+		q.Sort(jobs)
+	} else {
+		newestFirst(jobs)
+	}
+
+	if q.Offset >= len(jobs) {
+		return nil
+	}
+	end := len(jobs)
+	if q.Limit > 0 {
+		end = min(q.Offset+q.Limit, end)
+	}
+	return jobs[q.Offset:end]
+}
+
+// Query returns the page of stored jobs the query selects, how many jobs it
+// selects in all and how many are stored.
+func (s *Store) Query(q Query) ([]runner.Job, int, int) {
+	s.mu.Lock()
+	var selected []runner.Job
+	for _, job := range s.jobs {
+		if q.Match == nil || q.Match(job) {
+			selected = append(selected, job)
+		}
+	}
+	total := len(s.jobs)
+	s.mu.Unlock()
+
+	return q.page(selected), len(selected), total
+}
+
+// Page returns up to limit jobs from offset on, newest first, and the
+// number of jobs stored.
+func (s *Store) Page(offset, limit int) ([]runner.Job, int) {
+	jobs, _, total := s.Query(Query{Offset: offset, Limit: limit})
+	return jobs, total
+}
+
+// Len returns the number of jobs stored.
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.jobs)
+}
+
+// Ingest records the recent jobs of service.
+func (s *Store) Ingest(service runner.Service) error {
+	jobs, err := service.GetJobHistory(IngestLimit)
+	if err != nil {
+		return err
+	}
+	_, err = s.Add(jobs)
+	return err
+}
+
+// Watch ingests the jobs of service every interval until stop is closed.
+// Errors are retried at the next interval.
+func (s *Store) Watch(service runner.Service, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		_ = s.Ingest(service)
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// overgrown reports whether the file holds mostly superseded lines, or the
+// store holds well over the jobs the retention keeps.
+func (s *Store) overgrown() bool {
+	if s.lines > 2*len(s.jobs)+100 {
+		return true
+	}
+	return s.retention.MaxJobs > 0 && len(s.jobs) > s.retention.MaxJobs+s.retention.MaxJobs/10
+}
+
+// prune drops the jobs the retention does not keep from memory and returns
+// how many were dropped. The file keeps them until it is compacted.
+func (s *Store) prune(now time.Time) int {
+	dropped := 0
+	if s.retention.MaxAge > 0 {
+		cutoff := now.Add(-s.retention.MaxAge)
+		for id, job := range s.jobs {
+			// Jobs without times cannot be aged and are left to MaxJobs
+			if seen := lastSeen(job); !seen.IsZero() && seen.Before(cutoff) {
+				delete(s.jobs, id)
+				dropped++
+			}
+		}
+	}
+
+	if s.retention.MaxJobs > 0 && len(s.jobs) > s.retention.MaxJobs {
+		ids := make([]int, 0, len(s.jobs))
+		for id := range s.jobs {
+			ids = append(ids, id)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(ids)))
+		for _, id := range ids[s.retention.MaxJobs:] {
+			delete(s.jobs, id)
+			dropped++
+		}
+	}
+	return dropped
+}
+
+// lastSeen is when the job finished, or started while it runs.
+func lastSeen(job runner.Job) time.Time {
+	if !job.Finished.IsZero() {
+		return job.Finished
+	}
+	return job.Started
+}
+
+// newestFirst sorts jobs by ID, descending. GitLab job IDs grow over time,
+// so they order jobs by age.
+func newestFirst(jobs []runner.Job) {
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].ID > jobs[j].ID
+	})
+}
+
+// compact rewrites the file with one line per job. The caller holds the
+// lock or has the store to itself.
+func (s *Store) compact() error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".jobs-*")
+	if err != nil {
+		return fmt.Errorf("failed to compact job history: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(w)
+	jobs := make([]runner.Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	newestFirst(jobs)
+	for _, job := range jobs {
+		if err := encoder.Encode(job); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to compact job history: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to compact job history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to compact job history: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to compact job history: %w", err)
+	}
+	s.lines = len(jobs)
+	return nil
+}
+
+// merge returns the stored job updated with what a newer sighting of it
+// knows. Empty fields do not erase known values.
+func merge(stored, seen runner.Job) runner.Job {
+	merged := stored
+	if seen.Name != "" {
+		merged.Name = seen.Name
+	}
+	// A finished job keeps its result; a stale sighting of it as running
+	// must not bring it back
+	if seen.Status != "" && !finished(stored.Status) {
+		merged.Status = seen.Status
+	}
+	if seen.Stage != "" {
+		merged.Stage = seen.Stage
+	}
+	if seen.Project != "" {
+		merged.Project = seen.Project
+	}
+	if seen.Pipeline != 0 {
+		merged.Pipeline = seen.Pipeline
+	}
+	// A job starts and finishes once, so its times are kept from the first
+	// sighting that has them
+	if merged.Started.IsZero() {
+		merged.Started = seen.Started
+	}
+	if merged.Finished.IsZero() {
+		merged.Finished = seen.Finished
+	}
+	if merged.Duration == 0 {
+		merged.Duration = seen.Duration
+	}
+	if seen.RunnerName != "" {
+		merged.RunnerName = seen.RunnerName
+	}
+	if seen.RunnerID != "" {
+		merged.RunnerID = seen.RunnerID
+	}
+	if seen.ExitCode != 0 {
+		merged.ExitCode = seen.ExitCode
+	}
+	if seen.URL != "" {
+		merged.URL = seen.URL
+	}
+	return merged
+}
+
+// finished reports whether status is the result of a job.
+func finished(status string) bool {
+	switch status {
+	case "success", "failed", "canceled":
+		return true
+	}
+	return false
+}
+
+// same reports whether two records of a job are equal. Times are compared
+// as instants, as their locations differ once read back from the file.
+func same(a, b runner.Job) bool {
+	if !a.Started.Equal(b.Started) || !a.Finished.Equal(b.Finished) {
+		return false
+	}
+	a.Started, a.Finished = time.Time{}, time.Time{}
+	b.Started, b.Finished = time.Time{}, time.Time{}
+	return a == b
+}
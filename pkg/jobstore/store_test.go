@@ -0,0 +1,264 @@
+package jobstore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+// jobService serves a fixed job history.
+type jobService struct {
+	runner.Service
+	jobs []runner.Job
+	err  error
+}
+
+func (s *jobService) GetJobHistory(_ int) ([]runner.Job, error) {
+	return s.jobs, s.err
+}
+
+func openTemp(t *testing.T) (*Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "state", "jobs", "local.jsonl")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	return s, path
+}
+
+func TestStore_AddAndReopen(t *testing.T) {
+	s, path := openTemp(t)
+	started := time.Now().Add(-time.Hour).Truncate(time.Second).UTC()
+
+	n, err := s.Add([]runner.Job{
+		{ID: 1001, Status: "running", Started: started, RunnerName: "docker-1"},
+		{ID: 1002, Status: "success"},
+	})
+	if err != nil || n != 2 {
+		t.Fatalf("Add = %d, %v, expected 2 new jobs", n, err)
+	}
+
+	// The same sighting again changes nothing
+	if n, _ := s.Add([]runner.Job{{ID: 1002, Status: "success"}}); n != 0 {
+		t.Errorf("expected a repeated job to be skipped, %d written", n)
+	}
+
+	// A later sighting updates the job without erasing what was known
+	n, err = s.Add([]runner.Job{{ID: 1001, Status: "success", Finished: started.Add(time.Minute), Duration: time.Minute}})
+	if err != nil || n != 1 {
+		t.Fatalf("Add = %d, %v, expected 1 changed job", n, err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	jobs, total := reopened.Page(0, 10)
+	if total != 2 || len(jobs) != 2 {
+		t.Fatalf("expected 2 jobs after reopening, got %d of %d", len(jobs), total)
+	}
+	job := jobs[1]
+	if job.ID != 1001 || job.Status != "success" || job.RunnerName != "docker-1" ||
+		!job.Started.Equal(started) || job.Duration != time.Minute {
+		t.Errorf("unexpected merged job %+v", job)
+	}
+}
+
+func TestStore_AddKeepsResult(t *testing.T) {
+	s, path := openTemp(t)
+
+	if _, err := s.Add([]runner.Job{{ID: 1001, Status: "failed", ExitCode: 1}}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	// An older sighting of the job, such as from a lagging journal read
+	if n, _ := s.Add([]runner.Job{{ID: 1001, Status: "running", RunnerName: "docker-1"}}); n != 1 {
+		t.Errorf("expected the new runner name to be recorded, %d written", n)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	jobs, _ := reopened.Page(0, 10)
+	if len(jobs) != 1 || jobs[0].Status != "failed" || jobs[0].RunnerName != "docker-1" {
+		t.Errorf("expected the failed result to be kept, got %+v", jobs)
+	}
+}
+
+func TestStore_Page(t *testing.T) {
+	s, _ := openTemp(t)
+	var jobs []runner.Job
+	for id := 1; id <= 7; id++ {
+		jobs = append(jobs, runner.Job{ID: id, Status: "success"})
+	}
+	if _, err := s.Add(jobs); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		offset, limit int
+		expected      []int
+	}{
+		{0, 3, []int{7, 6, 5}},
+		{6, 3, []int{1}},
+		{9, 3, nil},
+	}
+	for _, tt := range tests {
+		page, total := s.Page(tt.offset, tt.limit)
+		if total != 7 {
+			t.Errorf("Page(%d, %d) total = %d, expected 7", tt.offset, tt.limit, total)
+		}
+		var ids []int
+		for _, job := range page {
+			ids = append(ids, job.ID)
+		}
+		if len(ids) != len(tt.expected) {
+			t.Errorf("Page(%d, %d) = %v, expected %v", tt.offset, tt.limit, ids, tt.expected)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != tt.expected[i] {
+				t.Errorf("Page(%d, %d) = %v, expected %v", tt.offset, tt.limit, ids, tt.expected)
+				break
+			}
+		}
+	}
+}
+
+func TestStore_Query(t *testing.T) {
+	s, _ := openTemp(t)
+	if _, err := s.Add([]runner.Job{
+		{ID: 1, Status: "failed", Duration: 3 * time.Minute},
+		{ID: 2, Status: "success", Duration: time.Minute},
+		{ID: 3, Status: "failed", Duration: 2 * time.Minute},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, matched, total := s.Query(Query{
+		Match: func(job runner.Job) bool { return job.Status == "failed" },
+		Sort: func(jobs []runner.Job) {
+			sort.Slice(jobs, func(i, j int) bool { return jobs[i].Duration < jobs[j].Duration })
+		},
+		Limit: 1,
+	})
+	if matched != 2 || total != 3 || len(jobs) != 1 || jobs[0].ID != 3 {
+		t.Errorf("Query = %+v, %d of %d, expected job 3 of 2 failed", jobs, matched, total)
+	}
+}
+
+func TestOpen_AppliesRetention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "local.jsonl")
+	old := time.Now().Add(-48 * time.Hour)
+	data := fmt.Sprintf(`{"id":1,"status":"success","finished":%q}
+{"id":2,"status":"success"}
+{"id":3,"status":"success"}
+{"id":4,"status":"success"}
+`, old.Format(time.RFC3339))
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := OpenWithRetention(path, Retention{MaxJobs: 2, MaxAge: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if jobs, total := s.Page(0, 10); total != 2 || jobs[0].ID != 4 || jobs[1].ID != 3 {
+		t.Errorf("expected the newest 2 jobs, got %+v", jobs)
+	}
+	compacted, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(compacted), "\n"); lines != 2 {
+		t.Errorf("expected the dropped jobs removed from the file, got %q", compacted)
+	}
+
+	// Adding well over the limit compacts again
+	if _, err := s.Add([]runner.Job{{ID: 5}, {ID: 6}, {ID: 7}}); err != nil {
+		t.Fatal(err)
+	}
+	if s.Len() != 2 {
+		t.Errorf("expected 2 jobs kept, got %d", s.Len())
+	}
+}
+
+func TestOpen_SkipsDamagedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "local.jsonl")
+	data := `{"id":1001,"status":"running"}
+not json
+{"id":1001,"status":"success"}
+{"id":1002,"sta`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	jobs, total := s.Page(0, 10)
+	if total != 1 || jobs[0].Status != "success" {
+		t.Errorf("expected the last line of job 1001 only, got %+v", jobs)
+	}
+}
+
+func TestOpen_Compacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "local.jsonl")
+	data := strings.Repeat(`{"id":1001,"status":"running"}`+"\n", 150) + `{"id":1001,"status":"success"}` + "\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	compacted, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(compacted), "\n"); lines != 1 || !strings.Contains(string(compacted), "success") {
+		t.Errorf("expected the file compacted to the last line, got %q", compacted)
+	}
+
+	// Appends continue after compaction
+	if _, err := s.Add([]runner.Job{{ID: 1002}}); err != nil {
+		t.Fatal(err)
+	}
+	if reopened, err := Open(path); err != nil || reopened.Len() != 2 {
+		t.Errorf("expected 2 jobs after reopening, got %v", err)
+	}
+}
+
+func TestStore_Ingest(t *testing.T) {
+	s, _ := openTemp(t)
+	service := &jobService{jobs: []runner.Job{{ID: 1001}, {ID: 1002}}}
+
+	if err := s.Ingest(service); err != nil {
+		t.Fatalf("Ingest failed: %v", err)
+	}
+	service.jobs = []runner.Job{{ID: 1003}}
+	if err := s.Ingest(service); err != nil {
+		t.Fatalf("Ingest failed: %v", err)
+	}
+	if s.Len() != 3 {
+		t.Errorf("expected jobs to accumulate across ingests, got %d", s.Len())
+	}
+
+	service.err = errors.New("ssh: connect to host ci-01: Connection refused")
+	if err := s.Ingest(service); err == nil {
+		t.Error("expected the service error")
+	}
+	if s.Len() != 3 {
+		t.Errorf("expected stored jobs to be kept, got %d", s.Len())
+	}
+}
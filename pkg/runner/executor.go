@@ -0,0 +1,218 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Executor runs the commands a Service needs. Implementations decide where
+// they run: on this machine, on a remote host or inside a container.
+type Executor interface {
+	// Output runs a command and returns its standard output.
+	Output(name string, args ...string) ([]byte, error)
+	// CombinedOutput runs a command and returns its standard output and
+	// standard error.
+	CombinedOutput(name string, args ...string) ([]byte, error)
+	// Stream starts a long-running command and returns its standard output.
+	// Closing the reader stops the command.
+	Stream(name string, args ...string) (io.ReadCloser, error)
+	// Pipe runs a command with input on its standard input and returns its
+	// standard output and standard error.
+	Pipe(input []byte, name string, args ...string) ([]byte, error)
+}
+
+// LocalExecutor runs commands on this machine.
+type LocalExecutor struct{}
+
+func NewLocalExecutor() *LocalExecutor {
+	return &LocalExecutor{}
+}
+
+func (e *LocalExecutor) Output(name string, args ...string) ([]byte, error) {
+	// #nosec G204 -- commands and arguments are built by Service methods
+	return exec.Command(name, args...).Output()
+}
+
+func (e *LocalExecutor) CombinedOutput(name string, args ...string) ([]byte, error) {
+	// #nosec G204 -- commands and arguments are built by Service methods
+	return exec.Command(name, args...).CombinedOutput()
+}
+
+func (e *LocalExecutor) Stream(name string, args ...string) (io.ReadCloser, error) {
+	// #nosec G204 -- commands and arguments are built by Service methods
+	cmd := exec.Command(name, args...)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	return &commandStream{ReadCloser: stdout, cmd: cmd}, nil
+}
+
+func (e *LocalExecutor) Pipe(input []byte, name string, args ...string) ([]byte, error) {
+	// #nosec G204 -- commands and arguments are built by Service methods
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = bytes.NewReader(input)
+	return cmd.CombinedOutput()
+}
+
+// commandStream is the output of a running command. Closing it kills the
+// command and reaps the process.
+type commandStream struct {
+	io.ReadCloser
+	cmd  *exec.Cmd
+	once sync.Once
+}
+
+func (s *commandStream) Close() error {
+	s.once.Do(func() {
+		_ = s.cmd.Process.Kill()
+		_ = s.ReadCloser.Close()
+		_ = s.cmd.Wait()
+	})
+	return nil
+}
+
+// ScriptedResponse is the recorded result of one command.
+type ScriptedResponse struct {
+	Stdout string
+	Stderr string
+	Err    error
+}
+
+// ScriptedExecutor replays recorded command output instead of running
+// anything, so Service can be tested without gitlab-runner, journalctl or
+// systemctl installed. Commands are matched by their full command line;
+// commands without a recording fail.
+type ScriptedExecutor struct {
+	mu        sync.Mutex
+	responses map[string][]ScriptedResponse
+	calls     []string
+	inputs    map[string][]byte
+}
+
+func NewScriptedExecutor() *ScriptedExecutor {
+	return &ScriptedExecutor{
+		responses: make(map[string][]ScriptedResponse),
+		inputs:    make(map[string][]byte),
+	}
+}
+
+// On records the responses for a command line such as
+// "systemctl is-active gitlab-runner". Responses are replayed in order and
+// the last one is repeated once the others are used up.
+func (e *ScriptedExecutor) On(command string, responses ...ScriptedResponse) *ScriptedExecutor {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.responses[command] = append(e.responses[command], responses...)
+	return e
+}
+
+// Load reads recorded commands from a script. Each command starts with a
+// "$ " line holding the command line; the lines up to the next command are
+// its standard output. A line starting with "! " makes the command fail
+// with the rest of the line as error message.
+func (e *ScriptedExecutor) Load(r io.Reader) error {
+	var command string
+	var resp *ScriptedResponse
+	var stdout []string
+
+	flush := func() {
+		if resp != nil {
+			resp.Stdout = strings.Join(stdout, "\n")
+			e.On(command, *resp)
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "$ "):
+			flush()
+			command = strings.TrimSpace(line[2:])
+			resp = &ScriptedResponse{}
+			stdout = nil
+		case resp == nil:
+			if strings.TrimSpace(line) != "" && !strings.HasPrefix(line, "#") {
+				return fmt.Errorf("output before first command: %q", line)
+			}
+		case strings.HasPrefix(line, "! "):
+			resp.Err = fmt.Errorf("%s", line[2:])
+		default:
+			stdout = append(stdout, line)
+		}
+	}
+	flush()
+
+	return scanner.Err()
+}
+
+// Calls returns the command lines run so far.
+func (e *ScriptedExecutor) Calls() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.calls...)
+}
+
+// Input returns what was last piped into command.
+func (e *ScriptedExecutor) Input(command string) []byte {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.inputs[command]
+}
+
+func (e *ScriptedExecutor) next(name string, args []string) ScriptedResponse {
+	command := strings.Join(append([]string{name}, args...), " ")
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls = append(e.calls, command)
+
+	queue := e.responses[command]
+	if len(queue) == 0 {
+		return ScriptedResponse{Err: fmt.Errorf("unscripted command: %s", command)}
+	}
+	if len(queue) > 1 {
+		e.responses[command] = queue[1:]
+	}
+	return queue[0]
+}
+
+func (e *ScriptedExecutor) Output(name string, args ...string) ([]byte, error) {
+	resp := e.next(name, args)
+	return []byte(resp.Stdout), resp.Err
+}
+
+func (e *ScriptedExecutor) CombinedOutput(name string, args ...string) ([]byte, error) {
+	resp := e.next(name, args)
+	return []byte(resp.Stdout + resp.Stderr), resp.Err
+}
+
+func (e *ScriptedExecutor) Stream(name string, args ...string) (io.ReadCloser, error) {
+	resp := e.next(name, args)
+	if resp.Err != nil {
+		return nil, resp.Err
+	}
+	return io.NopCloser(strings.NewReader(resp.Stdout)), nil
+}
+
+func (e *ScriptedExecutor) Pipe(input []byte, name string, args ...string) ([]byte, error) {
+	command := strings.Join(append([]string{name}, args...), " ")
+	e.mu.Lock()
+	e.inputs[command] = append([]byte(nil), input...)
+	e.mu.Unlock()
+
+	return e.CombinedOutput(name, args...)
+}
@@ -0,0 +1,99 @@
+package runner
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestScriptedExecutor_Load(t *testing.T) {
+	script := `# comment before the first command
+$ systemctl is-active gitlab-runner
+active
+
+$ gitlab-runner verify --name a
+Verifying runner... is alive
+! exit status 1
+`
+	e := NewScriptedExecutor()
+	if err := e.Load(strings.NewReader(script)); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	output, err := e.Output("systemctl", "is-active", "gitlab-runner")
+	if err != nil || strings.TrimSpace(string(output)) != "active" {
+		t.Errorf("Output = %q, %v", output, err)
+	}
+
+	output, err = e.CombinedOutput("gitlab-runner", "verify", "--name", "a")
+	if err == nil || err.Error() != "exit status 1" {
+		t.Errorf("expected recorded error, got %v", err)
+	}
+	if !strings.Contains(string(output), "is alive") {
+		t.Errorf("CombinedOutput = %q", output)
+	}
+}
+
+func TestScriptedExecutor_LoadRejectsOutputWithoutCommand(t *testing.T) {
+	if err := NewScriptedExecutor().Load(strings.NewReader("stray output\n")); err == nil {
+		t.Error("expected error for output before the first command")
+	}
+}
+
+func TestScriptedExecutor_ResponsesAreQueued(t *testing.T) {
+	e := NewScriptedExecutor().On("pgrep -c gitlab-runner",
+		ScriptedResponse{Stdout: "1"},
+		ScriptedResponse{Stdout: "2"},
+	)
+
+	for _, expected := range []string{"1", "2", "2"} {
+		output, _ := e.Output("pgrep", "-c", "gitlab-runner")
+		if string(output) != expected {
+			t.Errorf("Output = %q, expected %q", output, expected)
+		}
+	}
+}
+
+func TestScriptedExecutor_Unscripted(t *testing.T) {
+	e := NewScriptedExecutor()
+
+	if _, err := e.Output("rm", "-rf", "/"); err == nil || !strings.Contains(err.Error(), "unscripted command: rm -rf /") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := e.Stream("journalctl", "-f"); err == nil {
+		t.Error("expected error for unscripted stream")
+	}
+	if calls := e.Calls(); len(calls) != 2 {
+		t.Errorf("expected 2 recorded calls, got %v", calls)
+	}
+}
+
+func TestScriptedExecutor_Stream(t *testing.T) {
+	e := NewScriptedExecutor().On("journalctl -f", ScriptedResponse{Stdout: "line 1\nline 2\n"})
+
+	stream, err := e.Stream("journalctl", "-f")
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	defer stream.Close()
+
+	data, _ := io.ReadAll(stream)
+	if string(data) != "line 1\nline 2\n" {
+		t.Errorf("unexpected stream output %q", data)
+	}
+}
+
+func TestLocalExecutor_StreamClose(t *testing.T) {
+	stream, err := NewLocalExecutor().Stream("sleep", "60")
+	if err != nil {
+		t.Skipf("sleep not available: %v", err)
+	}
+
+	// Closing must stop the command instead of waiting for it to finish
+	if err := stream.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Errorf("second Close failed: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// journalRecord is an entry of journalctl's json output.
+type journalRecord struct {
+	cursor     string
+	time       time.Time
+	priority   int
+	pid        int
+	host       string
+	identifier string
+	message    string
+}
+
+// parseJournalRecord parses a line of journalctl's json output. It reports
+// false for JSON that is not a journal entry, such as gitlab-runner's own
+// json log format.
+func parseJournalRecord(line string) (journalRecord, bool) {
+	var raw struct {
+		Cursor     string          `json:"__CURSOR"`
+		Realtime   string          `json:"__REALTIME_TIMESTAMP"`
+		Priority   string          `json:"PRIORITY"`
+		PID        string          `json:"_PID"`
+		Host       string          `json:"_HOSTNAME"`
+		Identifier string          `json:"SYSLOG_IDENTIFIER"`
+		Message    json.RawMessage `json:"MESSAGE"`
+	}
+	if err := json.Unmarshal([]byte(line), &raw); err != nil || raw.Realtime == "" || raw.Message == nil {
+		return journalRecord{}, false
+	}
+
+	record := journalRecord{cursor: raw.Cursor, host: raw.Host, identifier: raw.Identifier, priority: -1}
+	if usec, err := strconv.ParseInt(raw.Realtime, 10, 64); err == nil {
+		record.time = time.UnixMicro(usec)
+	}
+	if priority, err := strconv.Atoi(raw.Priority); err == nil {
+		record.priority = priority
+	}
+	record.pid, _ = strconv.Atoi(raw.PID)
+	// Messages that are not valid UTF-8 are written as an array of bytes
+	if err := json.Unmarshal(raw.Message, &record.message); err != nil {
+		var values []int
+		var data []byte
+		if json.Unmarshal(raw.Message, &values) == nil {
+			for _, v := range values {
+				data = append(data, byte(v))
+			}
+		}
+		record.message = string(data)
+	}
+	return record, true
+}
+
+// level is the log level of the record's syslog priority, or "" when it
+// has none.
+func (r journalRecord) level() string {
+	switch {
+	case r.priority < 0:
+		return ""
+	case r.priority == 0:
+		return "panic"
+	case r.priority <= 2:
+		return "fatal"
+	case r.priority == 3:
+		return "error"
+	case r.priority == 4:
+		return "warning"
+	case r.priority <= 6:
+		return "info"
+	default:
+		return "debug"
+	}
+}
+
+// String formats the record the way journalctl's short-iso output does,
+// as in "2024-01-02T10:00:00+0100 host gitlab-runner[812]: message".
+func (r journalRecord) String() string {
+	identifier := r.identifier
+	if identifier == "" {
+		identifier = "gitlab-runner"
+	}
+	if r.pid != 0 {
+		identifier += fmt.Sprintf("[%d]", r.pid)
+	}
+	host := r.host
+	if host == "" {
+		host = "localhost"
+	}
+	return fmt.Sprintf("%s %s %s: %s", r.time.Format("2006-01-02T15:04:05-0700"), host, identifier, r.message)
+}
+
+// readJournal runs journalctl for the gitlab-runner unit with args in its
+// json output. It returns the entries, oldest first, and the cursor of the
+// last one, from which a later read can resume with --after-cursor.
+func readJournal(exec Executor, args ...string) ([]string, string, error) {
+	args = append(append([]string{"-u", "gitlab-runner"}, args...), "--no-pager", "-o", "json")
+	output, err := exec.Output("journalctl", args...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var lines []string
+	var cursor string
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines = append(lines, line)
+		if record, ok := parseJournalRecord(line); ok && record.cursor != "" {
+			cursor = record.cursor
+		}
+	}
+	return lines, cursor, nil
+}
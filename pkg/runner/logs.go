@@ -0,0 +1,305 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogLevels are the levels of gitlab-runner log entries, least severe
+// first.
+var LogLevels = []string{"trace", "debug", "info", "warning", "error", "fatal", "panic"}
+
+// LogEntry is one line of the gitlab-runner log. Fields holds the
+// key=value pairs of the line, without the time, level and msg keys that
+// are hoisted into their own fields. Raw is the line as shown to users:
+// entries read from journalctl's json output are shown the way its
+// short-iso output would show them. PID is the process that wrote an
+// entry read from the journal.
+type LogEntry struct {
+	Time    time.Time
+	Level   string
+	Message string
+	Fields  map[string]string
+	Raw     string
+	PID     int
+}
+
+// Severity is the index of the entry's level in LogLevels.
+func (e LogEntry) Severity() int {
+	return LevelSeverity(e.Level)
+}
+
+// LevelSeverity is the index of level in LogLevels. Unknown levels count
+// as info.
+func LevelSeverity(level string) int {
+	for i, l := range LogLevels {
+		if l == level {
+			return i
+		}
+	}
+	return LevelSeverity("info")
+}
+
+var (
+	// journalPrefix matches the prefix journalctl's short output adds, as in
+	// "Jan 02 10:00:00 host gitlab-runner[812]: ", and isoJournalPrefix that
+	// of its short-iso and short-iso-precise output, as in
+	// "2024-01-02T10:00:00+01:00 host gitlab-runner[812]: ".
+	journalPrefix    = regexp.MustCompile(`^([A-Z][a-z]{2} [ \d]\d \d\d:\d\d:\d\d) \S+ [^\s:\[]+(?:\[\d+\])?: `)
+	isoJournalPrefix = regexp.MustCompile(`^(\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d(?:\.\d+)?(?:Z|[+-]\d\d:?\d\d)) \S+ [^\s:\[]+(?:\[\d+\])?: `)
+	ansiEscape       = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	fieldKey         = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*=`)
+)
+
+// runnerLevelPrefixes are the level prefixes of the "runner" log format.
+// Info entries have none.
+var runnerLevelPrefixes = map[string]string{
+	"DEBUG: ":   "debug",
+	"WARNING: ": "warning",
+	"ERROR: ":   "error",
+	"FATAL: ":   "fatal",
+	"PANIC: ":   "panic",
+}
+
+// ParseLogLine parses a line of the gitlab-runner log in any of its
+// log_format settings: "runner" (the default), "text" or "json". Lines read
+// through journalctl may be in its short, short-iso or json output, which
+// gives the time for formats without one.
+func ParseLogLine(line string) LogEntry {
+	entry := LogEntry{Level: "info", Fields: map[string]string{}, Raw: line}
+
+	payload := line
+	if strings.HasPrefix(payload, "{") {
+		if record, ok := parseJournalRecord(payload); ok {
+			entry.Time = record.time
+			entry.PID = record.pid
+			// The message's own level, if it has one, overrides the priority
+			if level := record.level(); level != "" {
+				entry.Level = level
+			}
+			entry.Raw = record.String()
+			payload = record.message
+		}
+	}
+	if strings.Contains(payload, "\x1b[") {
+		payload = ansiEscape.ReplaceAllString(payload, "")
+	}
+	if m := journalPrefix.FindStringSubmatch(payload); m != nil {
+		entry.Time = parseJournalTime(m[1], time.Now())
+		payload = payload[len(m[0]):]
+	} else if m := isoJournalPrefix.FindStringSubmatch(payload); m != nil {
+		entry.Time = parseISOTime(m[1])
+		payload = payload[len(m[0]):]
+	}
+	payload = strings.TrimSpace(payload)
+
+	if !strings.HasPrefix(payload, "{") || !parseJSONEntry(payload, &entry) {
+		parseTextEntry(payload, &entry)
+	}
+	return entry
+}
+
+// parseJournalTime parses journalctl's yearless timestamp as the latest
+// such time not after now.
+func parseJournalTime(stamp string, now time.Time) time.Time {
+	t, err := time.ParseInLocation("Jan _2 15:04:05", stamp, now.Location())
+	if err != nil {
+		return time.Time{}
+	}
+	t = t.AddDate(now.Year()-t.Year(), 0, 0)
+	if t.After(now.Add(24 * time.Hour)) {
+		t = t.AddDate(-1, 0, 0)
+	}
+	return t
+}
+
+// parseISOTime parses journalctl's short-iso timestamps, whose zone offset
+// may lack the colon.
+func parseISOTime(stamp string) time.Time {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999Z0700"} {
+		if t, err := time.Parse(layout, stamp); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// parseJSONEntry parses the "json" log format. It reports false for
+// payloads that are not a JSON object.
+func parseJSONEntry(payload string, entry *LogEntry) bool {
+	decoder := json.NewDecoder(strings.NewReader(payload))
+	decoder.UseNumber()
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return false
+	}
+
+	for key, value := range fields {
+		switch v := value.(type) {
+		case string:
+			entry.Fields[key] = v
+		case json.Number:
+			entry.Fields[key] = v.String()
+		case nil:
+			entry.Fields[key] = ""
+		default:
+			var b bytes.Buffer
+			encoder := json.NewEncoder(&b)
+			encoder.SetEscapeHTML(false)
+			if err := encoder.Encode(v); err != nil {
+				entry.Fields[key] = fmt.Sprint(v)
+			} else {
+				entry.Fields[key] = strings.TrimSpace(b.String())
+			}
+		}
+	}
+	hoistFields(entry)
+	return true
+}
+
+// parseTextEntry parses the "text" format, which is key=value pairs only,
+// and the "runner" format, which is an optional level prefix and a message
+// followed by key=value pairs.
+func parseTextEntry(payload string, entry *LogEntry) {
+	tokens := splitTokens(payload)
+
+	// Fields are the key=value tokens at the end of the line
+	first := len(tokens)
+	for first > 0 && fieldKey.MatchString(tokens[first-1].text) {
+		first--
+	}
+	for _, token := range tokens[first:] {
+		key, value, _ := strings.Cut(token.text, "=")
+		if strings.HasPrefix(value, `"`) {
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			}
+		}
+		entry.Fields[key] = value
+	}
+
+	message := payload
+	if first < len(tokens) {
+		message = payload[:tokens[first].start]
+	}
+	message = strings.TrimSpace(message)
+	for prefix, level := range runnerLevelPrefixes {
+		if strings.HasPrefix(message, prefix) {
+			entry.Level = level
+			message = strings.TrimSpace(strings.TrimPrefix(message, prefix))
+			break
+		}
+	}
+	entry.Message = message
+
+	hoistFields(entry)
+}
+
+// hoistFields moves the time, level and msg keys into their LogEntry
+// fields.
+func hoistFields(entry *LogEntry) {
+	if level, ok := entry.Fields["level"]; ok {
+		entry.Level = normalizeLevel(level)
+		delete(entry.Fields, "level")
+	}
+	if msg, ok := entry.Fields["msg"]; ok {
+		entry.Message = msg
+		delete(entry.Fields, "msg")
+	}
+	if stamp, ok := entry.Fields["time"]; ok {
+		if t, err := time.Parse(time.RFC3339Nano, stamp); err == nil {
+			entry.Time = t
+			delete(entry.Fields, "time")
+		}
+	}
+}
+
+func normalizeLevel(level string) string {
+	level = strings.ToLower(level)
+	if level == "warn" {
+		return "warning"
+	}
+	return level
+}
+
+type token struct {
+	start int
+	text  string
+}
+
+// splitTokens splits s at spaces outside of double quotes.
+func splitTokens(s string) []token {
+	var tokens []token
+	start := -1
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !quoted && (c == ' ' || c == '\t') {
+			if start >= 0 {
+				tokens = append(tokens, token{start: start, text: s[start:i]})
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+		switch {
+		case quoted && c == '\\':
+			i++
+		case c == '"':
+			quoted = !quoted
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{start: start, text: s[start:]})
+	}
+	return tokens
+}
+
+// tokenPrefixes are the prefixes of GitLab token types that gitlab-runner
+// leaves out of the short token it logs.
+var tokenPrefixes = []string{"glrt-", "GR1348941"}
+
+// ShortTokens returns the forms of the runner's short token that
+// gitlab-runner writes to its log as runner=: the first 8 characters of the
+// token, as in Runner.ID, and of the token without its type prefix.
+func (r Runner) ShortTokens() []string {
+	tokens := []string{r.ID}
+	for _, prefix := range tokenPrefixes {
+		if token, ok := strings.CutPrefix(r.Token, prefix); ok {
+			tokens = append(tokens, token[:min(8, len(token))])
+		}
+	}
+	return tokens
+}
+
+// logFilter returns whether a log line belongs to the runner: lines with a
+// runner= field must carry one of its short tokens. Lines without one,
+// such as messages about the runner's configuration, are matched by name.
+func (r Runner) logFilter() func(line string) bool {
+	tokens := r.ShortTokens()
+	return func(line string) bool {
+		if !strings.Contains(line, "runner=") && !strings.Contains(line, `"runner"`) {
+			return r.Name != "" && mentions(line, r.Name)
+		}
+		entry := ParseLogLine(line)
+		if token, ok := entry.Fields["runner"]; ok {
+			return slices.Contains(tokens, token)
+		}
+		return r.Name != "" && strings.Contains(entry.Raw, r.Name)
+	}
+}
+
+// mentions reports whether a log line shows name. Entries of journalctl's
+// json output are matched as shown, not by the journal fields around the
+// message.
+func mentions(line, name string) bool {
+	return strings.Contains(line, name) && strings.Contains(ParseLogLine(line).Raw, name)
+}
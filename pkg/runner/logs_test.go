@@ -0,0 +1,176 @@
+package runner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLogLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		level   string
+		message string
+		fields  map[string]string
+		time    time.Time
+		pid     int
+		raw     string // the end of Raw, when it is not the line
+	}{
+		{
+			name:    "Runner format",
+			line:    `Checking for jobs... received                       job=1001 repo_url=https://gitlab.example.com/group/app.git runner=aaaaaaaa`,
+			level:   "info",
+			message: "Checking for jobs... received",
+			fields:  map[string]string{"job": "1001", "repo_url": "https://gitlab.example.com/group/app.git", "runner": "aaaaaaaa"},
+		},
+		{
+			name:    "Runner format with level prefix and quoted value",
+			line:    `WARNING: Job failed: exit code 1                  duration_s=5.2 job=1002 error="exit code 1" runner=bbbbbbbb`,
+			level:   "warning",
+			message: "Job failed: exit code 1",
+			fields:  map[string]string{"duration_s": "5.2", "job": "1002", "error": "exit code 1", "runner": "bbbbbbbb"},
+		},
+		{
+			name:    "Runner format with colors",
+			line:    "\x1b[31;1mERROR: Checking for jobs... forbidden       \x1b[0;m  runner=aaaaaaaa status=403",
+			level:   "error",
+			message: "Checking for jobs... forbidden",
+			fields:  map[string]string{"runner": "aaaaaaaa", "status": "403"},
+		},
+		{
+			name:    "Text format",
+			line:    `time="2024-01-02T10:00:00Z" level=warning msg="Appending trace to coordinator... failed" job=1003 runner=aaaaaaaa`,
+			level:   "warning",
+			message: "Appending trace to coordinator... failed",
+			fields:  map[string]string{"job": "1003", "runner": "aaaaaaaa"},
+			time:    time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "JSON format",
+			line:    `{"job":1004,"level":"error","msg":"Job failed","runner":"aaaaaaaa","time":"2024-01-02T10:00:05Z","labels":{"a":"b"}}`,
+			level:   "error",
+			message: "Job failed",
+			fields:  map[string]string{"job": "1004", "runner": "aaaaaaaa", "labels": `{"a":"b"}`},
+			time:    time.Date(2024, 1, 2, 10, 0, 5, 0, time.UTC),
+		},
+		{
+			name:    "Journal prefix",
+			line:    `Jan 02 10:00:09 host gitlab-runner[812]: Configuration loaded                                builds=2`,
+			level:   "info",
+			message: "Configuration loaded",
+			fields:  map[string]string{"builds": "2"},
+		},
+		{
+			name:    "Journal short-iso prefix",
+			line:    `2025-01-01T00:03:52+0100 ci-01 gitlab-runner[1422]: WARNING: Job failed: exit code 137   duration_s=40.1 job=6002`,
+			level:   "warning",
+			message: "Job failed: exit code 137",
+			fields:  map[string]string{"duration_s": "40.1", "job": "6002"},
+			time:    time.Date(2024, 12, 31, 23, 3, 52, 0, time.UTC),
+		},
+		{
+			name:    "Journal short-iso-precise prefix",
+			line:    `2025-01-01T00:03:52.123456+01:00 ci-01 gitlab-runner[1422]: Job succeeded   job=6001`,
+			level:   "info",
+			message: "Job succeeded",
+			fields:  map[string]string{"job": "6001"},
+			time:    time.Date(2024, 12, 31, 23, 3, 52, 123456000, time.UTC),
+		},
+		{
+			name:    "Journal JSON output",
+			line:    `{"__CURSOR":"s=7c1f;i=1b3ca","__REALTIME_TIMESTAMP":"1735686232000000","PRIORITY":"6","SYSLOG_IDENTIFIER":"gitlab-runner","_PID":"1422","_HOSTNAME":"ci-01","MESSAGE":"WARNING: Job failed: exit code 137   duration_s=40.1 job=6002"}`,
+			level:   "warning",
+			message: "Job failed: exit code 137",
+			fields:  map[string]string{"duration_s": "40.1", "job": "6002"},
+			time:    time.Date(2024, 12, 31, 23, 3, 52, 0, time.UTC),
+			pid:     1422,
+			raw:     " ci-01 gitlab-runner[1422]: WARNING: Job failed: exit code 137   duration_s=40.1 job=6002",
+		},
+		{
+			name:    "Journal JSON output with the level in the priority",
+			line:    `{"__REALTIME_TIMESTAMP":"1735686232000000","PRIORITY":"3","SYSLOG_IDENTIFIER":"systemd","_PID":"1","_HOSTNAME":"ci-01","MESSAGE":"gitlab-runner.service: Failed with result 'exit-code'."}`,
+			level:   "error",
+			message: "gitlab-runner.service: Failed with result 'exit-code'.",
+			fields:  map[string]string{},
+			time:    time.Date(2024, 12, 31, 23, 3, 52, 0, time.UTC),
+			pid:     1,
+			raw:     " ci-01 systemd[1]: gitlab-runner.service: Failed with result 'exit-code'.",
+		},
+		{
+			name:    "Journal JSON output with the json log format",
+			line:    `{"__REALTIME_TIMESTAMP":"1735686232000000","MESSAGE":"{\"job\":6002,\"level\":\"info\",\"msg\":\"Job succeeded\",\"time\":\"2025-01-01T00:03:52.5+01:00\"}"}`,
+			level:   "info",
+			message: "Job succeeded",
+			fields:  map[string]string{"job": "6002"},
+			time:    time.Date(2024, 12, 31, 23, 3, 52, 500000000, time.UTC),
+			raw:     `: {"job":6002,"level":"info","msg":"Job succeeded","time":"2025-01-01T00:03:52.5+01:00"}`,
+		},
+		{
+			name:    "Journal JSON output with a binary message",
+			line:    `{"__REALTIME_TIMESTAMP":"1735686232000000","MESSAGE":[74,111,98,32,115,117,99,99,101,101,100,101,100]}`,
+			level:   "info",
+			message: "Job succeeded",
+			fields:  map[string]string{},
+			time:    time.Date(2024, 12, 31, 23, 3, 52, 0, time.UTC),
+			raw:     " localhost gitlab-runner: Job succeeded",
+		},
+		{
+			name:    "Plain message",
+			line:    "Started GitLab Runner.",
+			level:   "info",
+			message: "Started GitLab Runner.",
+			fields:  map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := ParseLogLine(tt.line)
+
+			if entry.Level != tt.level {
+				t.Errorf("Level = %q, expected %q", entry.Level, tt.level)
+			}
+			if entry.Message != tt.message {
+				t.Errorf("Message = %q, expected %q", entry.Message, tt.message)
+			}
+			if !reflect.DeepEqual(entry.Fields, tt.fields) {
+				t.Errorf("Fields = %v, expected %v", entry.Fields, tt.fields)
+			}
+			if !tt.time.IsZero() && !entry.Time.Equal(tt.time) {
+				t.Errorf("Time = %v, expected %v", entry.Time, tt.time)
+			}
+			if entry.PID != tt.pid {
+				t.Errorf("PID = %d, expected %d", entry.PID, tt.pid)
+			}
+			if tt.raw != "" {
+				if !strings.HasSuffix(entry.Raw, tt.raw) {
+					t.Errorf("Raw = %q, expected it to end in %q", entry.Raw, tt.raw)
+				}
+			} else if entry.Raw != tt.line {
+				t.Errorf("Raw = %q, expected the line", entry.Raw)
+			}
+		})
+	}
+}
+
+func TestParseJournalTime(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 30, 0, 0, time.UTC)
+
+	if got := parseJournalTime("Jan 01 00:10:00", now); !got.Equal(time.Date(2025, 1, 1, 0, 10, 0, 0, time.UTC)) {
+		t.Errorf("expected this year, got %v", got)
+	}
+	if got := parseJournalTime("Dec 31 23:50:00", now); !got.Equal(time.Date(2024, 12, 31, 23, 50, 0, 0, time.UTC)) {
+		t.Errorf("expected last year, got %v", got)
+	}
+}
+
+func TestLevelSeverity(t *testing.T) {
+	if LevelSeverity("error") <= LevelSeverity("warning") || LevelSeverity("debug") >= LevelSeverity("info") {
+		t.Error("expected levels ordered by severity")
+	}
+	if LevelSeverity("unknown") != LevelSeverity("info") {
+		t.Error("expected unknown levels to count as info")
+	}
+}
@@ -0,0 +1,117 @@
+package runner
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Executors lists the executors gitlab-runner register accepts.
+var Executors = []string{
+	"shell",
+	"docker",
+	"docker-windows",
+	"docker+machine",
+	"docker-autoscaler",
+	"instance",
+	"kubernetes",
+	"ssh",
+	"parallels",
+	"virtualbox",
+	"custom",
+}
+
+// RegisterOptions are the settings of a runner to register.
+type RegisterOptions struct {
+	URL string
+	// Token is a runner authentication token (glrt-...) created in GitLab,
+	// or a legacy registration token.
+	Token string
+	// Executor is one of Executors.
+	Executor string
+	// Description becomes the name of the runner in config.toml. Empty uses
+	// the hostname.
+	Description string
+	// TagList is only accepted with registration tokens; runners created
+	// with an authentication token get their tags in GitLab.
+	TagList []string
+	// DockerImage is the default image of the docker executors.
+	DockerImage string
+	// KubernetesNamespace is the namespace job pods run in.
+	KubernetesNamespace string
+}
+
+// AuthenticationToken reports whether Token is a runner authentication
+// token rather than a legacy registration token.
+func (o *RegisterOptions) AuthenticationToken() bool {
+	return strings.HasPrefix(o.Token, "glrt-")
+}
+
+// UsesDockerImage reports whether the executor needs DockerImage.
+func (o *RegisterOptions) UsesDockerImage() bool {
+	return strings.HasPrefix(o.Executor, "docker")
+}
+
+// UsesKubernetesNamespace reports whether the executor takes
+// KubernetesNamespace.
+func (o *RegisterOptions) UsesKubernetesNamespace() bool {
+	return o.Executor == "kubernetes"
+}
+
+// Validate checks the options before gitlab-runner is run, so mistakes are
+// reported without a round trip to GitLab.
+func (o *RegisterOptions) Validate() error {
+	u, err := url.Parse(o.URL)
+	if o.URL == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("GitLab URL must be an http or https URL")
+	}
+	if o.Token == "" {
+		return fmt.Errorf("token is required")
+	}
+	if !isExecutor(o.Executor) {
+		return fmt.Errorf("unknown executor %q", o.Executor)
+	}
+	if o.UsesDockerImage() && o.DockerImage == "" {
+		return fmt.Errorf("executor %s requires a default docker image", o.Executor)
+	}
+	if o.AuthenticationToken() && len(o.TagList) > 0 {
+		return fmt.Errorf("tags of runners with an authentication token are set in GitLab")
+	}
+	return nil
+}
+
+// args builds the arguments of gitlab-runner register.
+func (o *RegisterOptions) args(configPath string) []string {
+	args := []string{"register", "--non-interactive", "--config", configPath, "--url", o.URL}
+
+	if o.AuthenticationToken() {
+		args = append(args, "--token", o.Token)
+	} else {
+		args = append(args, "--registration-token", o.Token)
+	}
+
+	args = append(args, "--executor", o.Executor)
+	if o.Description != "" {
+		args = append(args, "--description", o.Description)
+	}
+	if len(o.TagList) > 0 {
+		args = append(args, "--tag-list", strings.Join(o.TagList, ","))
+	}
+	if o.UsesDockerImage() {
+		args = append(args, "--docker-image", o.DockerImage)
+	}
+	if o.UsesKubernetesNamespace() && o.KubernetesNamespace != "" {
+		args = append(args, "--kubernetes-namespace", o.KubernetesNamespace)
+	}
+
+	return args
+}
+
+func isExecutor(name string) bool {
+	for _, executor := range Executors {
+		if executor == name {
+			return true
+		}
+	}
+	return false
+}
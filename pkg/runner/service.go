@@ -4,10 +4,11 @@ import (
 	"bufio"
 	"fmt"
 	"io"
-	"os/exec"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -17,11 +18,22 @@ type Service interface {
 	GetRunnerLogs(name string, lines int) ([]string, error)
 	StreamRunnerLogs(name string) (io.ReadCloser, error)
 	RestartRunner() error
+	RegisterRunner(opts RegisterOptions) error
+	UnregisterRunner(name string) error
 	GetSystemStatus() (*SystemStatus, error)
 	GetJobHistory(limit int) ([]Job, error)
+	GetJobLogs(id int) ([]string, error)
+	GetRunningJobs() ([]Job, error)
 	SetDebugMode(enabled bool)
 }
 
+// Administrator is implemented by services that can change runners on the
+// GitLab side. Callers check for it with a type assertion.
+type Administrator interface {
+	PauseRunner(name string, paused bool) error
+	SetRunnerDescription(name, description string) error
+}
+
 type SystemStatus struct {
 	ServiceActive  bool
 	ServiceEnabled bool
@@ -34,23 +46,41 @@ type SystemStatus struct {
 type gitlabRunnerService struct {
 	configPath string
 	debugMode  bool
+	exec       Executor
+	history    jobJournal
 }
 
+// jobJournal is the job history read from the journal so far. Later reads
+// resume after its cursor rather than reading the recent log again.
+type jobJournal struct {
+	mu     sync.Mutex
+	cursor string
+	window int // how many entries back the first read went
+	jobs   map[int]*Job
+}
+
+// maxJournalJobs bounds the jobs kept between reads of the journal.
+const maxJournalJobs = 1000
+
 const defaultConfigPath = "/etc/gitlab-runner/config.toml"
 
-func NewService(configPath string) Service {
+// NewService returns a Service that runs its commands through executor.
+// A nil executor runs them on this machine.
+func NewService(configPath string, executor Executor) Service {
 	if configPath == "" {
 		configPath = defaultConfigPath
 	}
+	if executor == nil {
+		executor = NewLocalExecutor()
+	}
 	return &gitlabRunnerService{
 		configPath: configPath,
+		exec:       executor,
 	}
 }
 
 func (s *gitlabRunnerService) ListRunners() ([]Runner, error) {
-	// #nosec G204 -- configPath is validated in NewService
-	cmd := exec.Command("gitlab-runner", "list", "--config", s.configPath)
-	output, err := cmd.Output()
+	output, err := s.exec.Output("gitlab-runner", "list", "--config", s.configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list runners: %w", err)
 	}
@@ -119,9 +149,7 @@ func (s *gitlabRunnerService) GetRunnerStatus(name string) (*Runner, error) {
 	for i := range runners {
 		runner := &runners[i]
 		if runner.Name == name {
-			// #nosec G204 -- configPath is validated in NewService, name comes from listed runners
-			cmd := exec.Command("gitlab-runner", "verify", "--name", name, "--config", s.configPath)
-			output, _ := cmd.CombinedOutput()
+			output, _ := s.exec.CombinedOutput("gitlab-runner", "verify", "--name", name, "--config", s.configPath)
 
 			if strings.Contains(string(output), "is alive") {
 				runner.Status = "active"
@@ -138,28 +166,36 @@ func (s *gitlabRunnerService) GetRunnerStatus(name string) (*Runner, error) {
 	return nil, fmt.Errorf("runner %s not found", name)
 }
 
+// GetRunnerLogs returns the last lines of the log that belong to the named
+// runner, or all of them without a name. In debug mode the journal is read
+// in its verbose format, which lists every field of each entry.
 func (s *gitlabRunnerService) GetRunnerLogs(name string, lines int) ([]string, error) {
-	args := []string{"-u", "gitlab-runner", "-n", fmt.Sprintf("%d", lines), "--no-pager"}
+	var logLines []string
+	var err error
 	if s.debugMode {
-		args = append(args, "-o", "verbose")
+		logLines, err = s.readVerboseJournal(lines)
+	} else {
+		logLines, _, err = readJournal(s.exec, "-n", strconv.Itoa(lines))
 	}
-
-	cmd := exec.Command("journalctl", args...)
-	output, err := cmd.Output()
 	if err != nil {
-		// #nosec G204 -- lines is an integer parameter
-		cmd = exec.Command("tail", "-n", fmt.Sprintf("%d", lines), "/var/log/gitlab-runner.log")
-		output, err = cmd.Output()
+		output, err := s.exec.Output("tail", "-n", fmt.Sprintf("%d", lines), "/var/log/gitlab-runner.log")
 		if err != nil {
 			return nil, fmt.Errorf("failed to get logs: %w", err)
 		}
+		logLines = strings.Split(string(output), "\n")
 	}
 
-	logLines := strings.Split(string(output), "\n")
-	var filteredLogs []string
+	if name == "" {
+		return logLines, nil
+	}
 
+	belongs := s.runnerLogFilter(name)
+	if s.debugMode {
+		return verboseRecords(logLines, belongs), nil
+	}
+	var filteredLogs []string
 	for _, line := range logLines {
-		if name == "" || strings.Contains(line, name) {
+		if belongs(line) {
 			filteredLogs = append(filteredLogs, line)
 		}
 	}
@@ -167,66 +203,147 @@ func (s *gitlabRunnerService) GetRunnerLogs(name string, lines int) ([]string, e
 	return filteredLogs, nil
 }
 
-func (s *gitlabRunnerService) StreamRunnerLogs(name string) (io.ReadCloser, error) {
-	cmd := exec.Command("journalctl", "-u", "gitlab-runner", "-f", "--no-pager")
-
-	stdout, err := cmd.StdoutPipe()
+// readVerboseJournal reads the last entries of the journal in its verbose
+// format: a line with the time and cursor of each entry, followed by one
+// indented line per field.
+func (s *gitlabRunnerService) readVerboseJournal(entries int) ([]string, error) {
+	output, err := s.exec.Output("journalctl", "-u", "gitlab-runner", "-n", strconv.Itoa(entries), "--no-pager", "-o", "verbose")
 	if err != nil {
-		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+		return nil, err
+	}
+	return strings.Split(strings.TrimRight(string(output), "\n"), "\n"), nil
+}
+
+// verboseRecords keeps the entries of verbose journal output with a line
+// that belongs to the runner, with all of their lines.
+func verboseRecords(lines []string, belongs func(line string) bool) []string {
+	var kept, record []string
+	keep := false
+	flush := func() {
+		if keep {
+			kept = append(kept, record...)
+		}
+		record, keep = nil, false
+	}
+	for _, line := range lines {
+		if line != "" && line[0] != ' ' && line[0] != '\t' {
+			flush()
+		}
+		record = append(record, line)
+		keep = keep || belongs(line)
 	}
+	flush()
+	return kept
+}
 
-	if err := cmd.Start(); err != nil {
+// runnerLogFilter returns whether a log line belongs to the named runner.
+// gitlab-runner identifies runners in its log by their short token, so the
+// token is looked up in config.toml; if it cannot be, lines are matched by
+// name.
+func (s *gitlabRunnerService) runnerLogFilter(name string) func(line string) bool {
+	runners, _ := s.ListRunners()
+	for i := range runners {
+		if runners[i].Name == name && runners[i].Token != "" {
+			return runners[i].logFilter()
+		}
+	}
+	return func(line string) bool {
+		return mentions(line, name)
+	}
+}
+
+// StreamRunnerLogs follows the log from now on, in journalctl's json
+// output. Closing the stream stops journalctl.
+func (s *gitlabRunnerService) StreamRunnerLogs(name string) (io.ReadCloser, error) {
+	var belongs func(string) bool
+	if name != "" {
+		belongs = s.runnerLogFilter(name)
+	}
+
+	stdout, err := s.exec.Stream("journalctl", "-u", "gitlab-runner", "-f", "-n", "0", "--no-pager", "-o", "json")
+	if err != nil {
 		return nil, fmt.Errorf("failed to start log streaming: %w", err)
 	}
 
-	if name == "" {
+	if belongs == nil {
 		return stdout, nil
 	}
 
 	pr, pw := io.Pipe()
 
 	go func() {
-		defer pw.Close()
 		scanner := bufio.NewScanner(stdout)
 		for scanner.Scan() {
 			line := scanner.Text()
-			if strings.Contains(line, name) {
-				fmt.Fprintln(pw, line)
+			if belongs(line) {
+				if _, err := fmt.Fprintln(pw, line); err != nil {
+					break
+				}
 			}
 		}
+		pw.CloseWithError(scanner.Err())
 	}()
 
-	return pr, nil
+	return &filteredStream{PipeReader: pr, source: stdout}, nil
+}
+
+// filteredStream is the filtered output of a stream. Closing it closes the
+// stream it reads from as well.
+type filteredStream struct {
+	*io.PipeReader
+	source io.ReadCloser
+}
+
+func (s *filteredStream) Close() error {
+	_ = s.PipeReader.Close()
+	return s.source.Close()
 }
 
 func (s *gitlabRunnerService) RestartRunner() error {
-	cmd := exec.Command("systemctl", "restart", "gitlab-runner")
-	if err := cmd.Run(); err != nil {
-		cmd = exec.Command("service", "gitlab-runner", "restart")
-		if err := cmd.Run(); err != nil {
+	if _, err := s.exec.Output("systemctl", "restart", "gitlab-runner"); err != nil {
+		if _, err := s.exec.Output("service", "gitlab-runner", "restart"); err != nil {
 			return fmt.Errorf("failed to restart gitlab-runner service: %w", err)
 		}
 	}
 	return nil
 }
 
+// RegisterRunner registers a new runner with GitLab and adds it to
+// config.toml.
+func (s *gitlabRunnerService) RegisterRunner(opts RegisterOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	output, err := s.exec.CombinedOutput("gitlab-runner", opts.args(s.configPath)...)
+	if err != nil {
+		return fmt.Errorf("failed to register runner: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// UnregisterRunner removes the runner from GitLab and from config.toml.
+func (s *gitlabRunnerService) UnregisterRunner(name string) error {
+	output, err := s.exec.CombinedOutput("gitlab-runner", "unregister", "--name", name, "--config", s.configPath)
+	if err != nil {
+		return fmt.Errorf("failed to unregister runner %s: %w: %s", name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 func (s *gitlabRunnerService) GetSystemStatus() (*SystemStatus, error) {
 	status := &SystemStatus{}
 
-	cmd := exec.Command("systemctl", "is-active", "gitlab-runner")
-	output, _ := cmd.Output()
+	output, _ := s.exec.Output("systemctl", "is-active", "gitlab-runner")
 	status.ServiceActive = strings.TrimSpace(string(output)) == "active"
 
-	cmd = exec.Command("systemctl", "is-enabled", "gitlab-runner")
-	output, _ = cmd.Output()
+	output, _ = s.exec.Output("systemctl", "is-enabled", "gitlab-runner")
 	status.ServiceEnabled = strings.TrimSpace(string(output)) == "enabled"
 
-	cmd = exec.Command("pgrep", "-c", "gitlab-runner")
-	output, _ = cmd.Output()
+	output, _ = s.exec.Output("pgrep", "-c", "gitlab-runner")
 	_, _ = fmt.Sscanf(string(output), "%d", &status.ProcessCount)
 
-	cmd = exec.Command("ps", "aux")
-	output, _ = cmd.Output()
+	output, _ = s.exec.Output("ps", "aux")
 	lines := strings.Split(string(output), "\n")
 	for _, line := range lines {
 		if strings.Contains(line, "gitlab-runner") && !strings.Contains(line, "grep") {
@@ -243,8 +360,7 @@ func (s *gitlabRunnerService) GetSystemStatus() (*SystemStatus, error) {
 		}
 	}
 
-	cmd = exec.Command("systemctl", "show", "gitlab-runner", "--property=ActiveEnterTimestamp")
-	output, _ = cmd.Output()
+	output, _ = s.exec.Output("systemctl", "show", "gitlab-runner", "--property=ActiveEnterTimestamp")
 	if timestamp := extractTimestamp(string(output)); timestamp != "" {
 		if t, err := time.Parse("Mon 2006-01-02 15:04:05 MST", timestamp); err == nil {
 			status.Uptime = time.Since(t)
@@ -262,13 +378,15 @@ func extractTimestamp(output string) string {
 	return ""
 }
 
+// GetJobHistory returns the most recent jobs, newest first. The first
+// call reads the last limit*10 journal entries; later calls add what was
+// logged since to the jobs read before.
 func (s *gitlabRunnerService) GetJobHistory(limit int) ([]Job, error) {
-	output, err := s.getJobLogs(limit)
+	jobMap, err := s.recentJobs(limit * 10)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to get job history: %w", err)
 	}
 
-	jobMap := s.parseJobLogs(output, limit)
 	jobs := s.convertJobMapToSlice(jobMap)
 	s.sortJobsByStartTime(jobs)
 
@@ -279,62 +397,223 @@ func (s *gitlabRunnerService) GetJobHistory(limit int) ([]Job, error) {
 	return jobs, nil
 }
 
-func (s *gitlabRunnerService) getJobLogs(limit int) ([]byte, error) {
-	// Try to get job history from journalctl logs
-	// #nosec G204 -- limit is an integer parameter
-	cmd := exec.Command("journalctl", "-u", "gitlab-runner", "-n", fmt.Sprintf("%d", limit*10), "--no-pager", "-r")
-	output, err := cmd.Output()
+// runningJobsWindow is how many journal entries back jobs in progress are
+// looked for.
+const runningJobsWindow = 2000
+
+// GetRunningJobs returns the jobs in progress, newest first: those whose
+// start was logged but not their result. The log names runners by short
+// token, which is replaced by the runner's name where config.toml has it.
+func (s *gitlabRunnerService) GetRunningJobs() ([]Job, error) {
+	jobMap, err := s.recentJobs(runningJobsWindow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get running jobs: %w", err)
+	}
+
+	names := make(map[string]string)
+	if runners, err := s.ListRunners(); err == nil {
+		for _, r := range runners {
+			for _, token := range r.ShortTokens() {
+				names[token] = r.Name
+			}
+		}
+	}
+
+	var jobs []Job
+	for _, job := range jobMap {
+		if job.Status != "running" {
+			continue
+		}
+		if name, ok := names[job.RunnerName]; ok {
+			job.RunnerName = name
+		}
+		jobs = append(jobs, *job)
+	}
+	s.sortJobsByStartTime(jobs)
+	return jobs, nil
+}
+
+// recentJobs returns the jobs of the last window entries of the journal,
+// or of as many lines of the log file without one.
+func (s *gitlabRunnerService) recentJobs(window int) (map[int]*Job, error) {
+	jobMap, err := s.readJobJournal(window)
+	if err == nil {
+		return jobMap, nil
+	}
+
+	// Fallback to log file
+	output, err := s.exec.Output("tail", "-n", strconv.Itoa(window), "/var/log/gitlab-runner.log")
+	if err != nil {
+		return nil, err
+	}
+	return s.parseJobLogs(output), nil
+}
+
+// readJobJournal brings the jobs read from the journal up to date and
+// returns a copy of them. It starts over from the last window entries when
+// there is no cursor to resume from, when the cursor is no longer in the
+// journal, or when more entries than before are asked for.
+func (s *gitlabRunnerService) readJobJournal(window int) (map[int]*Job, error) {
+	h := &s.history
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var lines []string
+	var cursor string
+	var err error
+	if h.cursor != "" && window <= h.window {
+		lines, cursor, err = readJournal(s.exec, "--after-cursor", h.cursor)
+	}
+	if h.cursor == "" || window > h.window || err != nil {
+		lines, cursor, err = readJournal(s.exec, "-n", strconv.Itoa(window))
+		if err != nil {
+			h.cursor, h.jobs = "", nil
+			return nil, err
+		}
+		h.jobs = make(map[int]*Job)
+		h.window = window
+	}
+	if cursor != "" {
+		h.cursor = cursor
+	}
+
+	s.collectJobs(h.jobs, lines)
+	s.pruneJobs(h.jobs)
+
+	jobMap := make(map[int]*Job, len(h.jobs))
+	for id, job := range h.jobs {
+		copied := *job
+		jobMap[id] = &copied
+	}
+	return jobMap, nil
+}
+
+// pruneJobs drops the oldest jobs beyond maxJournalJobs.
+func (s *gitlabRunnerService) pruneJobs(jobMap map[int]*Job) {
+	if len(jobMap) <= maxJournalJobs {
+		return
+	}
+	jobs := s.convertJobMapToSlice(jobMap)
+	s.sortJobsByStartTime(jobs)
+	for _, job := range jobs[maxJournalJobs:] {
+		delete(jobMap, job.ID)
+	}
+}
+
+// jobLogLines is how far back in the log the lines of a job are looked for
+// when the journal cannot be searched for them.
+const jobLogLines = 20000
+
+// GetJobLogs returns the lines gitlab-runner logged for a job, oldest
+// first. The journal is searched for them in full; where journalctl cannot
+// search, or there is no journal, only the recent log is read, and finding
+// nothing there is an error saying so.
+func (s *gitlabRunnerService) GetJobLogs(id int) ([]string, error) {
+	job := strconv.Itoa(id)
+	// Matches job=ID in text logs and "job":ID in JSON ones
+	if logLines, _, err := readJournal(s.exec, "--grep", `\bjob\W{1,2}`+job+`\b`); err == nil {
+		return jobLines(logLines, job), nil
+	}
+
+	n := strconv.Itoa(jobLogLines)
+	logLines, _, err := readJournal(s.exec, "-n", n)
 	if err != nil {
-		// Fallback to log file
-		// #nosec G204 -- limit is an integer parameter
-		cmd = exec.Command("tail", "-n", fmt.Sprintf("%d", limit*10), "/var/log/gitlab-runner.log")
-		output, err = cmd.Output()
+		output, err := s.exec.Output("tail", "-n", n, "/var/log/gitlab-runner.log")
 		if err != nil {
-			return nil, fmt.Errorf("failed to get job history: %w", err)
+			return nil, fmt.Errorf("failed to get job logs: %w", err)
 		}
+		logLines = strings.Split(string(output), "\n")
 	}
-	return output, nil
+
+	lines := jobLines(logLines, job)
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("no lines of job %d in the last %d lines of the log", id, jobLogLines)
+	}
+	return lines, nil
 }
 
-func (s *gitlabRunnerService) parseJobLogs(output []byte, limit int) map[int]*Job {
-	lines := strings.Split(string(output), "\n")
+// jobLines returns the log lines of the job with the given ID.
+func jobLines(logLines []string, job string) []string {
+	var lines []string
+	for _, line := range logLines {
+		// Most lines are not the job's, so they are ruled out before parsing
+		if !strings.Contains(line, job) {
+			continue
+		}
+		if ParseLogLine(line).Fields["job"] == job {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// parseJobLogs collects the jobs of log lines, oldest first, so that later
+// lines update what earlier ones said about a job.
+func (s *gitlabRunnerService) parseJobLogs(output []byte) map[int]*Job {
 	jobMap := make(map[int]*Job)
+	s.collectJobs(jobMap, strings.Split(string(output), "\n"))
+	return jobMap
+}
 
+// collectJobs adds what log lines, oldest first, say about jobs to jobMap.
+func (s *gitlabRunnerService) collectJobs(jobMap map[int]*Job, lines []string) {
 	for _, line := range lines {
 		job := s.parseJobFromLog(line)
 		if job != nil {
 			s.updateOrAddJob(jobMap, job)
 		}
+	}
 
-		if len(jobMap) >= limit {
-			break
+	for _, job := range jobMap {
+		// Without a logged duration, the times give it
+		if job.Duration == 0 && !job.Started.IsZero() && job.Finished.After(job.Started) {
+			job.Duration = job.Finished.Sub(job.Started)
 		}
 	}
+}
 
-	return jobMap
+// finished reports whether status is the result of a job.
+func finished(status string) bool {
+	switch status {
+	case "success", "failed", "canceled":
+		return true
+	}
+	return false
 }
 
+// updateOrAddJob merges a later sighting of a job into what is known. A
+// job starts once, so its first start time is kept.
 func (s *gitlabRunnerService) updateOrAddJob(jobMap map[int]*Job, job *Job) {
-	if existing, ok := jobMap[job.ID]; ok {
-		// Update existing job with new info
-		if job.Status != "" {
-			existing.Status = job.Status
-		}
-		if !job.Started.IsZero() {
-			existing.Started = job.Started
-		}
-		if !job.Finished.IsZero() {
-			existing.Finished = job.Finished
-			existing.Duration = job.Finished.Sub(existing.Started)
-		}
-		if job.RunnerName != "" {
-			existing.RunnerName = job.RunnerName
-		}
-		if job.ExitCode != 0 {
-			existing.ExitCode = job.ExitCode
-		}
-	} else {
+	existing, ok := jobMap[job.ID]
+	if !ok {
 		jobMap[job.ID] = job
+		return
+	}
+
+	// A finished job keeps its result and end time; later lines, such as
+	// the one removing it from the processing list, only add details
+	done := finished(existing.Status)
+	if job.Status != "" && !done {
+		existing.Status = job.Status
+	}
+	if existing.Started.IsZero() {
+		existing.Started = job.Started
+	}
+	if !job.Finished.IsZero() && !done {
+		existing.Finished = job.Finished
+	}
+	if job.Duration != 0 {
+		existing.Duration = job.Duration
+	}
+	// The repository path names the project better than its numeric ID
+	if job.Project != "" && (existing.Project == "" || isNumber(existing.Project)) {
+		existing.Project = job.Project
+	}
+	if job.RunnerName != "" {
+		existing.RunnerName = job.RunnerName
+	}
+	if job.ExitCode != 0 {
+		existing.ExitCode = job.ExitCode
 	}
 }
 
@@ -346,67 +625,95 @@ func (s *gitlabRunnerService) convertJobMapToSlice(jobMap map[int]*Job) []Job {
 	return jobs
 }
 
+// sortJobsByStartTime orders jobs newest first. Jobs without a start time
+// are ordered by ID, which grows over time, after those with one.
 func (s *gitlabRunnerService) sortJobsByStartTime(jobs []Job) {
-	// Sort by started time (newest first)
-	for i := 0; i < len(jobs)-1; i++ {
-		for j := i + 1; j < len(jobs); j++ {
-			if jobs[i].Started.Before(jobs[j].Started) {
-				jobs[i], jobs[j] = jobs[j], jobs[i]
-			}
+	sort.Slice(jobs, func(i, j int) bool {
+		a, b := jobs[i], jobs[j]
+		if !a.Started.Equal(b.Started) {
+			return a.Started.After(b.Started)
 		}
-	}
+		return a.ID > b.ID
+	})
 }
 
-func (s *gitlabRunnerService) parseJobFromLog(line string) *Job {
-	// Parse GitLab Runner log lines for job information
-	jobStartRegex := regexp.MustCompile(`job=(\d+).*project=(\d+).*runner=([a-zA-Z0-9_-]+)`)
-	jobStatusRegex := regexp.MustCompile(`job=(\d+).*status=(\w+)`)
-	jobFinishRegex := regexp.MustCompile(`job=(\d+).*duration=([0-9.]+)s`)
-
-	job := &Job{}
-
-	// Check for job start
-	if matches := jobStartRegex.FindStringSubmatch(line); len(matches) > 3 {
-		jobID, _ := strconv.Atoi(matches[1])
-		job.ID = jobID
-		job.Project = matches[2]
-		job.RunnerName = matches[3]
-		job.Status = "running"
-
-		// Try to extract timestamp
-		if idx := strings.Index(line, " "); idx > 0 {
-			timeStr := line[:idx]
-			if t, err := time.Parse("Jan 02 15:04:05", timeStr); err == nil {
-				job.Started = t
-			}
-		}
+var (
+	exitCodeRegex = regexp.MustCompile(`exit code (\d+)`)
+	repoPathRegex = regexp.MustCompile(`^[a-z]+://[^/]+/(.+?)(?:\.git)?/?$`)
+)
 
-		return job
+// parseJobFromLog returns what a log line says about a job, or nil for
+// lines about no job. The time of the line is when the job started for
+// the line that picks it up, and when it finished for the line reporting
+// its result; durations are taken from the duration_s gitlab-runner logs.
+func (s *gitlabRunnerService) parseJobFromLog(line string) *Job {
+	if !strings.Contains(line, "job") {
+		return nil
 	}
-
-	// Check for job status update
-	if matches := jobStatusRegex.FindStringSubmatch(line); len(matches) > 2 {
-		jobID, _ := strconv.Atoi(matches[1])
-		job.ID = jobID
-		job.Status = matches[2]
-		return job
+	entry := ParseLogLine(line)
+	id, err := strconv.Atoi(entry.Fields["job"])
+	if err != nil || id <= 0 {
+		return nil
 	}
 
-	// Check for job completion
-	if matches := jobFinishRegex.FindStringSubmatch(line); len(matches) > 2 {
-		jobID, _ := strconv.Atoi(matches[1])
-		job.ID = jobID
-		job.Status = "completed"
+	job := &Job{ID: id}
+	if project := entry.Fields["project"]; project != "" {
+		job.Project = project
+	}
+	if m := repoPathRegex.FindStringSubmatch(entry.Fields["repo_url"]); m != nil {
+		job.Project = m[1]
+	}
+	if runnerName := entry.Fields["runner"]; runnerName != "" {
+		job.RunnerName = runnerName
+	}
+	if seconds, err := strconv.ParseFloat(entry.Fields["duration_s"], 64); err == nil {
+		job.Duration = time.Duration(seconds * float64(time.Second))
+	} else if d, err := time.ParseDuration(entry.Fields["duration"]); err == nil {
+		job.Duration = d
+	}
 
-		if duration, err := strconv.ParseFloat(matches[2], 64); err == nil {
-			job.Duration = time.Duration(duration * float64(time.Second))
-			job.Finished = time.Now()
+	message := entry.Message
+	switch {
+	case strings.HasPrefix(message, "Checking for jobs... received"),
+		strings.HasPrefix(message, "Added job to processing list"):
+		job.Status = "running"
+		job.Started = entry.Time
+	case strings.HasPrefix(message, "Job succeeded"):
+		job.Status = "success"
+		job.Finished = entry.Time
+	case strings.HasPrefix(message, "Job failed"):
+		job.Status = "failed"
+		if strings.Contains(message, "canceled") {
+			job.Status = "canceled"
+		}
+		if m := exitCodeRegex.FindStringSubmatch(message); m != nil {
+			job.ExitCode, _ = strconv.Atoi(m[1])
+		}
+		job.Finished = entry.Time
+	case entry.Fields["job-status"] != "":
+		job.Status = entry.Fields["job-status"]
+	default:
+		// Other lines may carry a status or a duration in their fields, or
+		// only name the job's project. HTTP statuses of requests have
+		// spaces. Only the lines picking a job up say it is running.
+		switch status := entry.Fields["status"]; {
+		case status != "" && !strings.Contains(status, " "):
+			job.Status = status
+		case job.Duration != 0:
+			job.Status = "completed"
+			job.Finished = entry.Time
+		case job.Project == "":
+			return nil
 		}
-
-		return job
 	}
 
-	return nil
+	return job
+}
+
+// isNumber reports whether s is a decimal number.
+func isNumber(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
 }
 
 func (s *gitlabRunnerService) SetDebugMode(enabled bool) {
@@ -0,0 +1,108 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SSHTarget is the remote host an SSHExecutor runs commands on.
+type SSHTarget struct {
+	Address      string
+	Port         int
+	User         string
+	IdentityFile string
+	// Sudo runs every command through "sudo -n", for hosts where the SSH
+	// user may not read config.toml or manage the service directly.
+	Sudo bool
+}
+
+// Destination returns the target in user@host form.
+func (t SSHTarget) Destination() string {
+	if t.User == "" {
+		return t.Address
+	}
+	return t.User + "@" + t.Address
+}
+
+// SSHExecutor runs commands on a remote host through the ssh client. The
+// ssh client itself is started through another Executor, normally a
+// LocalExecutor, so the remote side can be replaced in tests.
+type SSHExecutor struct {
+	target SSHTarget
+	local  Executor
+}
+
+func NewSSHExecutor(target SSHTarget, local Executor) *SSHExecutor {
+	if local == nil {
+		local = NewLocalExecutor()
+	}
+	return &SSHExecutor{
+		target: target,
+		local:  local,
+	}
+}
+
+func (e *SSHExecutor) Output(name string, args ...string) ([]byte, error) {
+	return e.local.Output("ssh", e.sshArgs(name, args)...)
+}
+
+func (e *SSHExecutor) CombinedOutput(name string, args ...string) ([]byte, error) {
+	return e.local.CombinedOutput("ssh", e.sshArgs(name, args)...)
+}
+
+// Stream runs the remote command on a pseudo-terminal. Closing the stream
+// kills only the local ssh client; the terminal then hangs up, which ends a
+// remote command such as "journalctl -f" that would otherwise keep running
+// until it next writes a line. The terminal ends lines with "\r\n", which
+// bufio.ScanLines reads like "\n".
+func (e *SSHExecutor) Stream(name string, args ...string) (io.ReadCloser, error) {
+	return e.local.Stream("ssh", append([]string{"-tt"}, e.sshArgs(name, args)...)...)
+}
+
+func (e *SSHExecutor) Pipe(input []byte, name string, args ...string) ([]byte, error) {
+	return e.local.Pipe(input, "ssh", e.sshArgs(name, args)...)
+}
+
+// sshArgs builds the ssh client arguments for one remote command. The
+// remote shell joins and re-splits the command, so every argument is quoted.
+func (e *SSHExecutor) sshArgs(name string, args []string) []string {
+	sshArgs := []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=10"}
+	if e.target.Port != 0 {
+		sshArgs = append(sshArgs, "-p", fmt.Sprintf("%d", e.target.Port))
+	}
+	if e.target.IdentityFile != "" {
+		sshArgs = append(sshArgs, "-i", e.target.IdentityFile)
+	}
+	sshArgs = append(sshArgs, e.target.Destination(), "--")
+
+	words := append([]string{name}, args...)
+	if e.target.Sudo {
+		words = append([]string{"sudo", "-n"}, words...)
+	}
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = ShellQuote(w)
+	}
+
+	return append(sshArgs, strings.Join(quoted, " "))
+}
+
+// ShellQuote quotes s for a POSIX shell. Words made only of safe characters
+// are returned unchanged.
+func ShellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	safe := true
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:,@%+", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,241 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// sshStandIn stands in for the ssh client and the remote sshd. It checks the
+// client arguments, splits the remote command line the way the remote shell
+// would and runs the command on remote.
+type sshStandIn struct {
+	t           *testing.T
+	destination string
+	remote      Executor
+}
+
+func (s *sshStandIn) command(name string, args []string) (string, []string) {
+	s.t.Helper()
+	if name != "ssh" {
+		s.t.Fatalf("expected ssh to be run, got %q", name)
+	}
+	sep := -1
+	for i, arg := range args {
+		if arg == "--" {
+			sep = i
+			break
+		}
+	}
+	if sep < 1 || sep != len(args)-2 || args[sep-1] != s.destination {
+		s.t.Fatalf("unexpected ssh arguments: %q", args)
+	}
+	words, err := shellSplit(args[len(args)-1])
+	if err != nil || len(words) == 0 {
+		s.t.Fatalf("bad remote command %q: %v", args[len(args)-1], err)
+	}
+	return words[0], words[1:]
+}
+
+func (s *sshStandIn) Output(name string, args ...string) ([]byte, error) {
+	name, args = s.command(name, args)
+	return s.remote.Output(name, args...)
+}
+
+func (s *sshStandIn) CombinedOutput(name string, args ...string) ([]byte, error) {
+	name, args = s.command(name, args)
+	return s.remote.CombinedOutput(name, args...)
+}
+
+func (s *sshStandIn) Stream(name string, args ...string) (io.ReadCloser, error) {
+	// Without a terminal to hang up, the remote command outlives the client
+	if len(args) == 0 || args[0] != "-tt" {
+		s.t.Fatalf("expected a stream to force a terminal, got %q", args)
+	}
+	name, args = s.command(name, args[1:])
+	return s.remote.Stream(name, args...)
+}
+
+func (s *sshStandIn) Pipe(input []byte, name string, args ...string) ([]byte, error) {
+	name, args = s.command(name, args)
+	return s.remote.Pipe(input, name, args...)
+}
+
+// shellSplit splits a command line quoted by ShellQuote.
+func shellSplit(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord, quoted := false, false
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quoted:
+			if c == '\'' {
+				quoted = false
+			} else {
+				word.WriteByte(c)
+			}
+		case c == '\'':
+			quoted, inWord = true, true
+		case c == '\\' && i+1 < len(line):
+			i++
+			word.WriteByte(line[i])
+			inWord = true
+		case c == ' ':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"gitlab-runner", "gitlab-runner"},
+		{"/etc/gitlab-runner/config.toml", "/etc/gitlab-runner/config.toml"},
+		{"--property=ActiveEnterTimestamp", "--property=ActiveEnterTimestamp"},
+		{"", "''"},
+		{"my runner", "'my runner'"},
+		{"it's", `'it'\''s'`},
+		{"$(reboot)", "'$(reboot)'"},
+	}
+
+	for _, tt := range tests {
+		if got := ShellQuote(tt.input); got != tt.expected {
+			t.Errorf("ShellQuote(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+		if words, err := shellSplit(ShellQuote(tt.input)); err != nil || len(words) != 1 || words[0] != tt.input {
+			t.Errorf("ShellQuote(%q) does not split back: %q, %v", tt.input, words, err)
+		}
+	}
+}
+
+func TestSSHExecutor_Arguments(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   SSHTarget
+		expected string
+	}{
+		{
+			name:     "Address only",
+			target:   SSHTarget{Address: "ci-01.example.com"},
+			expected: "ssh -o BatchMode=yes -o ConnectTimeout=10 ci-01.example.com -- systemctl is-active gitlab-runner",
+		},
+		{
+			name:     "Full profile",
+			target:   SSHTarget{Address: "10.0.0.5", Port: 2222, User: "ops", IdentityFile: "/home/ops/.ssh/ci", Sudo: true},
+			expected: "ssh -o BatchMode=yes -o ConnectTimeout=10 -p 2222 -i /home/ops/.ssh/ci ops@10.0.0.5 -- sudo -n systemctl is-active gitlab-runner",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			local := NewScriptedExecutor()
+			_, _ = NewSSHExecutor(tt.target, local).Output("systemctl", "is-active", "gitlab-runner")
+
+			if calls := local.Calls(); len(calls) != 1 || calls[0] != tt.expected {
+				t.Errorf("unexpected calls: %q", calls)
+			}
+		})
+	}
+}
+
+func TestSSHExecutor_StreamArguments(t *testing.T) {
+	local := NewScriptedExecutor()
+	executor := NewSSHExecutor(SSHTarget{Address: "ci-01", Sudo: true}, local)
+	if stream, err := executor.Stream("journalctl", "-u", "gitlab-runner", "-f"); err == nil {
+		stream.Close()
+	}
+
+	expected := "ssh -tt -o BatchMode=yes -o ConnectTimeout=10 ci-01 -- sudo -n journalctl -u gitlab-runner -f"
+	if calls := local.Calls(); len(calls) != 1 || calls[0] != expected {
+		t.Errorf("unexpected calls: %q", calls)
+	}
+}
+
+func newRemoteService(t *testing.T) (Service, *ScriptedExecutor) {
+	t.Helper()
+	_, remote := newScriptedService(t, "healthy.script")
+	standIn := &sshStandIn{t: t, destination: "gitlab-runner@ci-01", remote: remote}
+	executor := NewSSHExecutor(SSHTarget{Address: "ci-01", User: "gitlab-runner"}, standIn)
+	return NewService("", executor), remote
+}
+
+func TestSSHExecutor_Service(t *testing.T) {
+	service, remote := newRemoteService(t)
+
+	runners, err := service.ListRunners()
+	if err != nil || len(runners) != 2 {
+		t.Fatalf("ListRunners = %+v, %v", runners, err)
+	}
+
+	r, err := service.GetRunnerStatus("docker-1")
+	if err != nil || !r.Online {
+		t.Errorf("GetRunnerStatus = %+v, %v", r, err)
+	}
+
+	logs, err := service.GetRunnerLogs("shell-1", 5)
+	if err != nil || len(logs) != 1 {
+		t.Errorf("GetRunnerLogs = %q, %v", logs, err)
+	}
+
+	stream, err := service.StreamRunnerLogs("")
+	if err != nil {
+		t.Fatalf("StreamRunnerLogs failed: %v", err)
+	}
+	data, _ := io.ReadAll(stream)
+	stream.Close()
+	if !strings.Contains(string(data), "job=1004") {
+		t.Errorf("unexpected stream output %q", data)
+	}
+
+	if err := service.RestartRunner(); err != nil {
+		t.Errorf("RestartRunner failed: %v", err)
+	}
+
+	status, err := service.GetSystemStatus()
+	if err != nil || !status.ServiceActive || status.ProcessCount != 2 {
+		t.Errorf("GetSystemStatus = %+v, %v", status, err)
+	}
+
+	jobs, err := service.GetJobHistory(2)
+	if err != nil || len(jobs) != 2 {
+		t.Errorf("GetJobHistory = %+v, %v", jobs, err)
+	}
+
+	// The remote side saw the same commands a local service would run
+	calls := remote.Calls()
+	if calls[0] != "gitlab-runner list --config /etc/gitlab-runner/config.toml" {
+		t.Errorf("unexpected first remote call %q", calls[0])
+	}
+}
+
+func TestSSHExecutor_Pipe(t *testing.T) {
+	remote := NewScriptedExecutor().On(`sh -c cat > "$1" sh /etc/gitlab-runner/config.toml`, ScriptedResponse{})
+	standIn := &sshStandIn{t: t, destination: "ci-01", remote: remote}
+	executor := NewSSHExecutor(SSHTarget{Address: "ci-01"}, standIn)
+
+	if _, err := executor.Pipe([]byte("concurrent = 2\n"), "sh", "-c", `cat > "$1"`, "sh", "/etc/gitlab-runner/config.toml"); err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	if got := string(remote.Input(`sh -c cat > "$1" sh /etc/gitlab-runner/config.toml`)); got != "concurrent = 2\n" {
+		t.Errorf("remote received %q", got)
+	}
+}
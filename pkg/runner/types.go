@@ -1,6 +1,9 @@
 package runner
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type Runner struct {
 	ID           string
@@ -8,6 +11,7 @@ type Runner struct {
 	Token        string
 	Status       string
 	Online       bool
+	Paused       bool
 	Description  string
 	TagList      []string
 	Locked       bool
@@ -35,123 +39,330 @@ type Job struct {
 	URL        string
 }
 
+// Config mirrors gitlab-runner's config.toml. Field tags carry the key
+// names gitlab-runner documents; keys that are not modeled here are kept
+// untouched by the config package when the file is saved.
 type Config struct {
-	Concurrent    int                 `yaml:"concurrent"`
-	CheckInterval int                 `yaml:"check_interval"`
-	LogLevel      string              `yaml:"log_level"`
-	LogFormat     string              `yaml:"log_format"`
-	SessionServer SessionServerConfig `yaml:"session_server,omitempty"`
-	Runners       []RunnerConfig      `yaml:"runners"`
+	Concurrent       int                 `toml:"concurrent"`
+	CheckInterval    int                 `toml:"check_interval,omitempty"`
+	LogLevel         string              `toml:"log_level,omitempty"`
+	LogFormat        string              `toml:"log_format,omitempty"`
+	SentryDSN        string              `toml:"sentry_dsn,omitempty"`
+	ConnectionMaxAge string              `toml:"connection_max_age,omitempty"`
+	ListenAddress    string              `toml:"listen_address,omitempty"`
+	ShutdownTimeout  int                 `toml:"shutdown_timeout,omitempty"`
+	SessionServer    SessionServerConfig `toml:"session_server,omitempty"`
+	Runners          []RunnerConfig      `toml:"runners"`
 }
 
 type SessionServerConfig struct {
-	ListenAddress  string `yaml:"listen_address"`
-	SessionTimeout int    `yaml:"session_timeout"`
+	ListenAddress    string `toml:"listen_address,omitempty"`
+	AdvertiseAddress string `toml:"advertise_address,omitempty"`
+	SessionTimeout   int    `toml:"session_timeout,omitempty"`
 }
 
 type RunnerConfig struct {
-	Name               string            `yaml:"name"`
-	URL                string            `yaml:"url"`
-	Token              string            `yaml:"token"`
-	Executor           string            `yaml:"executor"`
-	Shell              string            `yaml:"shell,omitempty"`
-	BuildsDir          string            `yaml:"builds_dir,omitempty"`
-	CacheDir           string            `yaml:"cache_dir,omitempty"`
-	Environment        []string          `yaml:"environment,omitempty"`
-	RequestConcurrency int               `yaml:"request_concurrency,omitempty"`
-	OutputLimit        int               `yaml:"output_limit,omitempty"`
-	PreCloneScript     string            `yaml:"pre_clone_script,omitempty"`
-	PreBuildScript     string            `yaml:"pre_build_script,omitempty"`
-	PostBuildScript    string            `yaml:"post_build_script,omitempty"`
-	CloneURL           string            `yaml:"clone_url,omitempty"`
-	DebugTraceDisabled bool              `yaml:"debug_trace_disabled,omitempty"`
-	TagList            []string          `yaml:"tag_list,omitempty"`
-	RunUntagged        bool              `yaml:"run_untagged,omitempty"`
-	Locked             bool              `yaml:"locked,omitempty"`
-	Limit              int               `yaml:"limit,omitempty"`
-	MaxBuilds          int               `yaml:"max_builds,omitempty"`
-	Docker             *DockerConfig     `yaml:"docker,omitempty"`
-	Machine            *MachineConfig    `yaml:"machine,omitempty"`
-	Kubernetes         *KubernetesConfig `yaml:"kubernetes,omitempty"`
+	ID                             int64                 `toml:"id,omitempty"`
+	Name                           string                `toml:"name"`
+	URL                            string                `toml:"url"`
+	Token                          string                `toml:"token"`
+	TokenObtainedAt                time.Time             `toml:"token_obtained_at,omitempty"`
+	TokenExpiresAt                 time.Time             `toml:"token_expires_at,omitempty"`
+	TLSCAFile                      string                `toml:"tls-ca-file,omitempty"`
+	TLSCertFile                    string                `toml:"tls-cert-file,omitempty"`
+	TLSKeyFile                     string                `toml:"tls-key-file,omitempty"`
+	Executor                       string                `toml:"executor"`
+	Shell                          string                `toml:"shell,omitempty"`
+	BuildsDir                      string                `toml:"builds_dir,omitempty"`
+	CacheDir                       string                `toml:"cache_dir,omitempty"`
+	Environment                    []string              `toml:"environment,omitempty"`
+	RequestConcurrency             int                   `toml:"request_concurrency,omitempty"`
+	OutputLimit                    int                   `toml:"output_limit,omitempty"`
+	PreCloneScript                 string                `toml:"pre_clone_script,omitempty"`
+	PreGetSourcesScript            string                `toml:"pre_get_sources_script,omitempty"`
+	PostGetSourcesScript           string                `toml:"post_get_sources_script,omitempty"`
+	PreBuildScript                 string                `toml:"pre_build_script,omitempty"`
+	PostBuildScript                string                `toml:"post_build_script,omitempty"`
+	CloneURL                       string                `toml:"clone_url,omitempty"`
+	DebugTraceDisabled             bool                  `toml:"debug_trace_disabled,omitempty"`
+	UnhealthyRequestsLimit         int                   `toml:"unhealthy_requests_limit,omitempty"`
+	UnhealthyInterval              string                `toml:"unhealthy_interval,omitempty"`
+	JobStatusFinalUpdateRetryLimit int                   `toml:"job_status_final_update_retry_limit,omitempty"`
+	TagList                        []string              `toml:"tag_list,omitempty"`
+	RunUntagged                    bool                  `toml:"run_untagged,omitempty"`
+	Locked                         bool                  `toml:"locked,omitempty"`
+	Limit                          int                   `toml:"limit,omitempty"`
+	MaxBuilds                      int                   `toml:"max_builds,omitempty"`
+	FeatureFlags                   map[string]bool       `toml:"feature_flags,omitempty"`
+	CustomBuildDir                 *CustomBuildDirConfig `toml:"custom_build_dir,omitempty"`
+	Cache                          *CacheConfig          `toml:"cache,omitempty"`
+	Docker                         *DockerConfig         `toml:"docker,omitempty"`
+	Machine                        *MachineConfig        `toml:"machine,omitempty"`
+	Kubernetes                     *KubernetesConfig     `toml:"kubernetes,omitempty"`
+	Custom                         *CustomConfig         `toml:"custom,omitempty"`
+	SSH                            *SSHConfig            `toml:"ssh,omitempty"`
+	Parallels                      *ParallelsConfig      `toml:"parallels,omitempty"`
+	VirtualBox                     *VirtualBoxConfig     `toml:"virtualbox,omitempty"`
+}
+
+// StringList is a list of strings that may also be written as a single
+// string in config.toml, as gitlab-runner allows for e.g. pull_policy.
+type StringList []string
+
+func (l *StringList) UnmarshalTOML(data interface{}) error {
+	switch v := data.(type) {
+	case string:
+		*l = StringList{v}
+	case []interface{}:
+		list := make(StringList, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("expected string, got %T", item)
+			}
+			list = append(list, s)
+		}
+		*l = list
+	default:
+		return fmt.Errorf("expected string or array of strings, got %T", data)
+	}
+	return nil
+}
+
+type CustomBuildDirConfig struct {
+	Enabled bool `toml:"enabled"`
+}
+
+type CacheConfig struct {
+	Type                   string            `toml:"Type,omitempty"`
+	Path                   string            `toml:"Path,omitempty"`
+	Shared                 bool              `toml:"Shared,omitempty"`
+	MaxUploadedArchiveSize int64             `toml:"MaxUploadedArchiveSize,omitempty"`
+	S3                     *CacheS3Config    `toml:"s3,omitempty"`
+	GCS                    *CacheGCSConfig   `toml:"gcs,omitempty"`
+	Azure                  *CacheAzureConfig `toml:"azure,omitempty"`
+}
+
+type CacheS3Config struct {
+	ServerAddress             string `toml:"ServerAddress,omitempty"`
+	AccessKey                 string `toml:"AccessKey,omitempty"`
+	SecretKey                 string `toml:"SecretKey,omitempty"`
+	SessionToken              string `toml:"SessionToken,omitempty"`
+	BucketName                string `toml:"BucketName,omitempty"`
+	BucketLocation            string `toml:"BucketLocation,omitempty"`
+	Insecure                  bool   `toml:"Insecure,omitempty"`
+	AuthenticationType        string `toml:"AuthenticationType,omitempty"`
+	ServerSideEncryption      string `toml:"ServerSideEncryption,omitempty"`
+	ServerSideEncryptionKeyID string `toml:"ServerSideEncryptionKeyID,omitempty"`
+	DualStack                 *bool  `toml:"DualStack,omitempty"`
+	Accelerate                bool   `toml:"Accelerate,omitempty"`
+	PathStyle                 *bool  `toml:"PathStyle,omitempty"`
+	RoleARN                   string `toml:"RoleARN,omitempty"`
+	UploadRoleARN             string `toml:"UploadRoleARN,omitempty"`
+}
+
+type CacheGCSConfig struct {
+	CredentialsFile string `toml:"CredentialsFile,omitempty"`
+	AccessID        string `toml:"AccessID,omitempty"`
+	PrivateKey      string `toml:"PrivateKey,omitempty"`
+	BucketName      string `toml:"BucketName,omitempty"`
+}
+
+type CacheAzureConfig struct {
+	AccountName   string `toml:"AccountName,omitempty"`
+	AccountKey    string `toml:"AccountKey,omitempty"`
+	ContainerName string `toml:"ContainerName,omitempty"`
+	StorageDomain string `toml:"StorageDomain,omitempty"`
 }
 
 type DockerConfig struct {
-	Host                       string            `yaml:"host,omitempty"`
-	Hostname                   string            `yaml:"hostname,omitempty"`
-	Image                      string            `yaml:"image"`
-	Runtime                    string            `yaml:"runtime,omitempty"`
-	Memory                     string            `yaml:"memory,omitempty"`
-	MemorySwap                 string            `yaml:"memory_swap,omitempty"`
-	MemoryReservation          string            `yaml:"memory_reservation,omitempty"`
-	CpusetCpus                 string            `yaml:"cpuset_cpus,omitempty"`
-	Cpus                       string            `yaml:"cpus,omitempty"`
-	DNSSearch                  []string          `yaml:"dns_search,omitempty"`
-	DNS                        []string          `yaml:"dns,omitempty"`
-	Privileged                 bool              `yaml:"privileged,omitempty"`
-	DisableEntrypointOverwrite bool              `yaml:"disable_entrypoint_overwrite,omitempty"`
-	Userns                     string            `yaml:"userns_mode,omitempty"`
-	CapAdd                     []string          `yaml:"cap_add,omitempty"`
-	CapDrop                    []string          `yaml:"cap_drop,omitempty"`
-	OomKillDisable             bool              `yaml:"oom_kill_disable,omitempty"`
-	OomScoreAdjust             int               `yaml:"oom_score_adjust,omitempty"`
-	SecurityOpt                []string          `yaml:"security_opt,omitempty"`
-	Devices                    []string          `yaml:"devices,omitempty"`
-	DisableCache               bool              `yaml:"disable_cache,omitempty"`
-	Volumes                    []string          `yaml:"volumes,omitempty"`
-	VolumeDriver               string            `yaml:"volume_driver,omitempty"`
-	CacheDir                   string            `yaml:"cache_dir,omitempty"`
-	ExtraHosts                 []string          `yaml:"extra_hosts,omitempty"`
-	Isolation                  string            `yaml:"isolation,omitempty"`
-	NetworkMode                string            `yaml:"network_mode,omitempty"`
-	Links                      []string          `yaml:"links,omitempty"`
-	Services                   []string          `yaml:"services,omitempty"`
-	WaitForServicesTimeout     int               `yaml:"wait_for_services_timeout,omitempty"`
-	AllowedImages              []string          `yaml:"allowed_images,omitempty"`
-	AllowedServices            []string          `yaml:"allowed_services,omitempty"`
-	PullPolicy                 []string          `yaml:"pull_policy,omitempty"`
-	ShmSize                    int64             `yaml:"shm_size,omitempty"`
-	Tmpfs                      map[string]string `yaml:"tmpfs,omitempty"`
-	ServicesTmpfs              map[string]string `yaml:"services_tmpfs,omitempty"`
-	SysCtls                    map[string]string `yaml:"sysctls,omitempty"`
-	HelperImage                string            `yaml:"helper_image,omitempty"`
-	HelperImageFlavor          string            `yaml:"helper_image_flavor,omitempty"`
+	Host                       string            `toml:"host,omitempty"`
+	Hostname                   string            `toml:"hostname,omitempty"`
+	TLSCertPath                string            `toml:"tls_cert_path,omitempty"`
+	TLSVerify                  bool              `toml:"tls_verify,omitempty"`
+	Image                      string            `toml:"image"`
+	Runtime                    string            `toml:"runtime,omitempty"`
+	GPUs                       string            `toml:"gpus,omitempty"`
+	Memory                     string            `toml:"memory,omitempty"`
+	MemorySwap                 string            `toml:"memory_swap,omitempty"`
+	MemoryReservation          string            `toml:"memory_reservation,omitempty"`
+	CpusetCpus                 string            `toml:"cpuset_cpus,omitempty"`
+	Cpus                       string            `toml:"cpus,omitempty"`
+	CPUShares                  int64             `toml:"cpu_shares,omitempty"`
+	DNSSearch                  []string          `toml:"dns_search,omitempty"`
+	DNS                        []string          `toml:"dns,omitempty"`
+	Privileged                 bool              `toml:"privileged,omitempty"`
+	DisableEntrypointOverwrite bool              `toml:"disable_entrypoint_overwrite,omitempty"`
+	User                       string            `toml:"user,omitempty"`
+	GroupAdd                   []string          `toml:"group_add,omitempty"`
+	Userns                     string            `toml:"userns_mode,omitempty"`
+	CapAdd                     []string          `toml:"cap_add,omitempty"`
+	CapDrop                    []string          `toml:"cap_drop,omitempty"`
+	OomKillDisable             bool              `toml:"oom_kill_disable,omitempty"`
+	OomScoreAdjust             int               `toml:"oom_score_adjust,omitempty"`
+	SecurityOpt                []string          `toml:"security_opt,omitempty"`
+	Devices                    []string          `toml:"devices,omitempty"`
+	DeviceCgroupRules          []string          `toml:"device_cgroup_rules,omitempty"`
+	DisableCache               bool              `toml:"disable_cache,omitempty"`
+	Volumes                    []string          `toml:"volumes,omitempty"`
+	VolumesFrom                []string          `toml:"volumes_from,omitempty"`
+	VolumeDriver               string            `toml:"volume_driver,omitempty"`
+	CacheDir                   string            `toml:"cache_dir,omitempty"`
+	ExtraHosts                 []string          `toml:"extra_hosts,omitempty"`
+	Isolation                  string            `toml:"isolation,omitempty"`
+	MacAddress                 string            `toml:"mac_address,omitempty"`
+	NetworkMode                string            `toml:"network_mode,omitempty"`
+	NetworkMTU                 int               `toml:"network_mtu,omitempty"`
+	EnableIPv6                 bool              `toml:"enable_ipv6,omitempty"`
+	Links                      []string          `toml:"links,omitempty"`
+	Services                   []DockerService   `toml:"services,omitempty"`
+	ServicesLimit              int               `toml:"services_limit,omitempty"`
+	ServiceMemory              string            `toml:"service_memory,omitempty"`
+	ServiceCpus                string            `toml:"service_cpus,omitempty"`
+	WaitForServicesTimeout     int               `toml:"wait_for_services_timeout,omitempty"`
+	AllowedImages              []string          `toml:"allowed_images,omitempty"`
+	AllowedServices            []string          `toml:"allowed_services,omitempty"`
+	AllowedPrivilegedImages    []string          `toml:"allowed_privileged_images,omitempty"`
+	AllowedPrivilegedServices  []string          `toml:"allowed_privileged_services,omitempty"`
+	AllowedPullPolicies        []string          `toml:"allowed_pull_policies,omitempty"`
+	AllowedUsers               []string          `toml:"allowed_users,omitempty"`
+	PullPolicy                 StringList        `toml:"pull_policy,omitempty"`
+	ShmSize                    int64             `toml:"shm_size,omitempty"`
+	Tmpfs                      map[string]string `toml:"tmpfs,omitempty"`
+	ServicesTmpfs              map[string]string `toml:"services_tmpfs,omitempty"`
+	SysCtls                    map[string]string `toml:"sysctls,omitempty"`
+	Ulimit                     map[string]string `toml:"ulimit,omitempty"`
+	ContainerLabels            map[string]string `toml:"container_labels,omitempty"`
+	LogOptions                 map[string]string `toml:"log_options,omitempty"`
+	HelperImage                string            `toml:"helper_image,omitempty"`
+	HelperImageFlavor          string            `toml:"helper_image_flavor,omitempty"`
+}
+
+type DockerService struct {
+	Name        string   `toml:"name"`
+	Alias       string   `toml:"alias,omitempty"`
+	Entrypoint  []string `toml:"entrypoint,omitempty"`
+	Command     []string `toml:"command,omitempty"`
+	Environment []string `toml:"environment,omitempty"`
 }
 
 type MachineConfig struct {
-	IdleCount      int      `yaml:"IdleCount,omitempty"`
-	IdleTime       int      `yaml:"IdleTime,omitempty"`
-	MaxBuilds      int      `yaml:"MaxBuilds,omitempty"`
-	MachineDriver  string   `yaml:"MachineDriver,omitempty"`
-	MachineName    string   `yaml:"MachineName,omitempty"`
-	MachineOptions []string `yaml:"MachineOptions,omitempty"`
+	IdleCount        int                        `toml:"IdleCount,omitempty"`
+	IdleScaleFactor  float64                    `toml:"IdleScaleFactor,omitempty"`
+	IdleCountMin     int                        `toml:"IdleCountMin,omitempty"`
+	IdleTime         int                        `toml:"IdleTime,omitempty"`
+	MaxGrowthRate    int                        `toml:"MaxGrowthRate,omitempty"`
+	MaxBuilds        int                        `toml:"MaxBuilds,omitempty"`
+	MachineDriver    string                     `toml:"MachineDriver,omitempty"`
+	MachineName      string                     `toml:"MachineName,omitempty"`
+	MachineOptions   []string                   `toml:"MachineOptions,omitempty"`
+	OffPeakPeriods   []string                   `toml:"OffPeakPeriods,omitempty"`
+	OffPeakTimezone  string                     `toml:"OffPeakTimezone,omitempty"`
+	OffPeakIdleCount int                        `toml:"OffPeakIdleCount,omitempty"`
+	OffPeakIdleTime  int                        `toml:"OffPeakIdleTime,omitempty"`
+	Autoscaling      []MachineAutoscalingConfig `toml:"autoscaling,omitempty"`
+}
+
+type MachineAutoscalingConfig struct {
+	Periods         []string `toml:"Periods,omitempty"`
+	IdleCount       int      `toml:"IdleCount,omitempty"`
+	IdleScaleFactor float64  `toml:"IdleScaleFactor,omitempty"`
+	IdleCountMin    int      `toml:"IdleCountMin,omitempty"`
+	IdleTime        int      `toml:"IdleTime,omitempty"`
+	Timezone        string   `toml:"Timezone,omitempty"`
 }
 
 type KubernetesConfig struct {
-	Host                           string                 `yaml:"host,omitempty"`
-	BearerToken                    string                 `yaml:"bearer_token,omitempty"`
-	BearerTokenOverwriteAllowed    bool                   `yaml:"bearer_token_overwrite_allowed,omitempty"`
-	Image                          string                 `yaml:"image"`
-	Namespace                      string                 `yaml:"namespace,omitempty"`
-	NamespaceOverwriteAllowed      string                 `yaml:"namespace_overwrite_allowed,omitempty"`
-	Privileged                     bool                   `yaml:"privileged,omitempty"`
-	RuntimeClassName               string                 `yaml:"runtime_class_name,omitempty"`
-	AllowPrivilegeEscalation       *bool                  `yaml:"allow_privilege_escalation,omitempty"`
-	NodeSelector                   map[string]string      `yaml:"node_selector,omitempty"`
-	NodeTolerations                map[string]string      `yaml:"node_tolerations,omitempty"`
-	ImagePullSecrets               []string               `yaml:"image_pull_secrets,omitempty"`
-	HelperImage                    string                 `yaml:"helper_image,omitempty"`
-	HelperImageFlavor              string                 `yaml:"helper_image_flavor,omitempty"`
-	TerminationGracePeriodSeconds  int64                  `yaml:"terminationGracePeriodSeconds,omitempty"`
-	PollInterval                   int                    `yaml:"poll_interval,omitempty"`
-	PollTimeout                    int                    `yaml:"poll_timeout,omitempty"`
-	PodLabels                      map[string]string      `yaml:"pod_labels,omitempty"`
-	ServiceAccount                 string                 `yaml:"service_account,omitempty"`
-	ServiceAccountOverwriteAllowed string                 `yaml:"service_account_overwrite_allowed,omitempty"`
-	PodAnnotations                 map[string]string      `yaml:"pod_annotations,omitempty"`
-	PodAnnotationsOverwriteAllowed string                 `yaml:"pod_annotations_overwrite_allowed,omitempty"`
-	PodSecurityContext             map[string]interface{} `yaml:"pod_security_context,omitempty"`
-	ResourcesLimits                map[string]string      `yaml:"resource_limits,omitempty"`
-	ResourcesRequests              map[string]string      `yaml:"resource_requests,omitempty"`
-	Affinity                       map[string]interface{} `yaml:"affinity,omitempty"`
-	Volumes                        map[string]interface{} `yaml:"volumes,omitempty"`
+	Host                           string                 `toml:"host,omitempty"`
+	CertFile                       string                 `toml:"cert_file,omitempty"`
+	KeyFile                        string                 `toml:"key_file,omitempty"`
+	CAFile                         string                 `toml:"ca_file,omitempty"`
+	BearerToken                    string                 `toml:"bearer_token,omitempty"`
+	BearerTokenOverwriteAllowed    bool                   `toml:"bearer_token_overwrite_allowed,omitempty"`
+	Image                          string                 `toml:"image"`
+	Namespace                      string                 `toml:"namespace,omitempty"`
+	NamespaceOverwriteAllowed      string                 `toml:"namespace_overwrite_allowed,omitempty"`
+	Privileged                     bool                   `toml:"privileged,omitempty"`
+	RuntimeClassName               string                 `toml:"runtime_class_name,omitempty"`
+	AllowPrivilegeEscalation       *bool                  `toml:"allow_privilege_escalation,omitempty"`
+	CPULimit                       string                 `toml:"cpu_limit,omitempty"`
+	CPURequest                     string                 `toml:"cpu_request,omitempty"`
+	MemoryLimit                    string                 `toml:"memory_limit,omitempty"`
+	MemoryRequest                  string                 `toml:"memory_request,omitempty"`
+	EphemeralStorageLimit          string                 `toml:"ephemeral_storage_limit,omitempty"`
+	EphemeralStorageRequest        string                 `toml:"ephemeral_storage_request,omitempty"`
+	ServiceCPULimit                string                 `toml:"service_cpu_limit,omitempty"`
+	ServiceCPURequest              string                 `toml:"service_cpu_request,omitempty"`
+	ServiceMemoryLimit             string                 `toml:"service_memory_limit,omitempty"`
+	ServiceMemoryRequest           string                 `toml:"service_memory_request,omitempty"`
+	HelperCPULimit                 string                 `toml:"helper_cpu_limit,omitempty"`
+	HelperCPURequest               string                 `toml:"helper_cpu_request,omitempty"`
+	HelperMemoryLimit              string                 `toml:"helper_memory_limit,omitempty"`
+	HelperMemoryRequest            string                 `toml:"helper_memory_request,omitempty"`
+	CPULimitOverwriteMaxAllowed    string                 `toml:"cpu_limit_overwrite_max_allowed,omitempty"`
+	MemoryLimitOverwriteMaxAllowed string                 `toml:"memory_limit_overwrite_max_allowed,omitempty"`
+	PullPolicy                     StringList             `toml:"pull_policy,omitempty"`
+	NodeSelector                   map[string]string      `toml:"node_selector,omitempty"`
+	NodeTolerations                map[string]string      `toml:"node_tolerations,omitempty"`
+	ImagePullSecrets               []string               `toml:"image_pull_secrets,omitempty"`
+	HelperImage                    string                 `toml:"helper_image,omitempty"`
+	HelperImageFlavor              string                 `toml:"helper_image_flavor,omitempty"`
+	TerminationGracePeriodSeconds  int64                  `toml:"terminationGracePeriodSeconds,omitempty"`
+	PodTerminationGracePeriod      int64                  `toml:"pod_termination_grace_period_seconds,omitempty"`
+	PollInterval                   int                    `toml:"poll_interval,omitempty"`
+	PollTimeout                    int                    `toml:"poll_timeout,omitempty"`
+	PodLabels                      map[string]string      `toml:"pod_labels,omitempty"`
+	ServiceAccount                 string                 `toml:"service_account,omitempty"`
+	ServiceAccountOverwriteAllowed string                 `toml:"service_account_overwrite_allowed,omitempty"`
+	PodAnnotations                 map[string]string      `toml:"pod_annotations,omitempty"`
+	PodAnnotationsOverwriteAllowed string                 `toml:"pod_annotations_overwrite_allowed,omitempty"`
+	PriorityClassName              string                 `toml:"priority_class_name,omitempty"`
+	SchedulerName                  string                 `toml:"scheduler_name,omitempty"`
+	DNSPolicy                      string                 `toml:"dns_policy,omitempty"`
+	PodSecurityContext             map[string]interface{} `toml:"pod_security_context,omitempty"`
+	Affinity                       map[string]interface{} `toml:"affinity,omitempty"`
+	Volumes                        map[string]interface{} `toml:"volumes,omitempty"`
+}
+
+type CustomConfig struct {
+	ConfigExec          string   `toml:"config_exec,omitempty"`
+	ConfigArgs          []string `toml:"config_args,omitempty"`
+	ConfigExecTimeout   int      `toml:"config_exec_timeout,omitempty"`
+	PrepareExec         string   `toml:"prepare_exec,omitempty"`
+	PrepareArgs         []string `toml:"prepare_args,omitempty"`
+	PrepareExecTimeout  int      `toml:"prepare_exec_timeout,omitempty"`
+	RunExec             string   `toml:"run_exec"`
+	RunArgs             []string `toml:"run_args,omitempty"`
+	CleanupExec         string   `toml:"cleanup_exec,omitempty"`
+	CleanupArgs         []string `toml:"cleanup_args,omitempty"`
+	CleanupExecTimeout  int      `toml:"cleanup_exec_timeout,omitempty"`
+	GracefulKillTimeout int      `toml:"graceful_kill_timeout,omitempty"`
+	ForceKillTimeout    int      `toml:"force_kill_timeout,omitempty"`
+}
+
+type SSHConfig struct {
+	User                         string `toml:"user,omitempty"`
+	Password                     string `toml:"password,omitempty"`
+	Host                         string `toml:"host,omitempty"`
+	Port                         string `toml:"port,omitempty"`
+	IdentityFile                 string `toml:"identity_file,omitempty"`
+	DisableStrictHostKeyChecking *bool  `toml:"disable_strict_host_key_checking,omitempty"`
+	KnownHostsFile               string `toml:"known_hosts_file,omitempty"`
+}
+
+type ParallelsConfig struct {
+	BaseName         string   `toml:"base_name"`
+	TemplateName     string   `toml:"template_name,omitempty"`
+	DisableSnapshots bool     `toml:"disable_snapshots,omitempty"`
+	TimeServer       string   `toml:"time_server,omitempty"`
+	AllowedImages    []string `toml:"allowed_images,omitempty"`
+}
+
+type VirtualBoxConfig struct {
+	BaseName         string   `toml:"base_name"`
+	BaseSnapshot     string   `toml:"base_snapshot,omitempty"`
+	BaseFolder       string   `toml:"base_folder,omitempty"`
+	DisableSnapshots bool     `toml:"disable_snapshots,omitempty"`
+	AllowedImages    []string `toml:"allowed_images,omitempty"`
+	StartType        string   `toml:"start_type,omitempty"`
 }
@@ -0,0 +1,187 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/config"
+)
+
+// backupsPanel lists the backups of config.toml. Enter shows how the
+// selected backup differs from the current file and r restores it after a
+// confirmation.
+type backupsPanel struct {
+	configMgr *config.TOMLConfigManager
+	backups   []config.Backup
+	selected  int
+	loading   bool
+	err       error
+	// diff is the backup whose diff the viewport shows, or nil
+	diff     *config.Backup
+	viewport viewport.Model
+	confirm  *confirmModal
+	width    int
+}
+
+func newBackupsPanel(configMgr *config.TOMLConfigManager, width, height int) *backupsPanel {
+	p := &backupsPanel{configMgr: configMgr, loading: true, viewport: viewport.New(80, 10)}
+	p.setSize(width, height)
+	return p
+}
+
+func (p *backupsPanel) setSize(width, height int) {
+	p.width = width
+	p.viewport.Width = max(width-2, 20)
+	p.viewport.Height = max(height-14, 5)
+}
+
+type backupsLoadedMsg struct {
+	backups []config.Backup
+	err     error
+}
+
+type backupDiffMsg struct {
+	backup config.Backup
+	diff   string
+	err    error
+}
+
+type backupRestoredMsg struct {
+	backup config.Backup
+	err    error
+}
+
+func (p *backupsPanel) load() tea.Msg {
+	backups, err := p.configMgr.Backups()
+	return backupsLoadedMsg{backups: backups, err: err}
+}
+
+func (p *backupsPanel) loadDiff(backup config.Backup) tea.Cmd {
+	configMgr := p.configMgr
+	return func() tea.Msg {
+		diff, err := configMgr.BackupDiff(backup)
+		return backupDiffMsg{backup: backup, diff: diff, err: err}
+	}
+}
+
+func (p *backupsPanel) restore(backup config.Backup) tea.Cmd {
+	configMgr := p.configMgr
+	return func() tea.Msg {
+		return backupRestoredMsg{backup: backup, err: configMgr.Restore(backup)}
+	}
+}
+
+func (p *backupsPanel) setBackups(msg backupsLoadedMsg) {
+	p.loading = false
+	p.err = msg.err
+	p.backups = msg.backups
+	p.selected = min(p.selected, max(len(p.backups)-1, 0))
+}
+
+func (p *backupsPanel) setDiff(msg backupDiffMsg) {
+	p.loading = false
+	if p.err = msg.err; p.err != nil {
+		return
+	}
+	backup := msg.backup
+	p.diff = &backup
+	content := StatusUnknownStyle.Render("Same as the current file")
+	if msg.diff != "" {
+		content = strings.Join(paintDiff(msg.diff), "\n")
+	}
+	p.viewport.SetContent(content)
+	p.viewport.GotoTop()
+}
+
+// handleKey returns whether the panel was closed.
+func (p *backupsPanel) handleKey(msg tea.KeyMsg) (closed bool, cmd tea.Cmd) {
+	if p.confirm != nil {
+		done, cmd := p.confirm.handleKey(msg)
+		if done {
+			p.confirm = nil
+		}
+		return false, cmd
+	}
+
+	if p.diff != nil {
+		switch msg.String() {
+		case "esc", "q":
+			p.diff = nil
+		case "r":
+			p.confirmRestore(*p.diff)
+		default:
+			p.viewport, cmd = p.viewport.Update(msg)
+		}
+		return false, cmd
+	}
+
+	switch msg.String() {
+	case "esc", "q":
+		return true, nil
+	case "up", "k":
+		if p.selected > 0 {
+			p.selected--
+		}
+	case "down", "j":
+		if p.selected < len(p.backups)-1 {
+			p.selected++
+		}
+	case "enter":
+		if p.selected < len(p.backups) {
+			p.loading = true
+			return false, p.loadDiff(p.backups[p.selected])
+		}
+	case "r":
+		if p.selected < len(p.backups) {
+			p.confirmRestore(p.backups[p.selected])
+		}
+	}
+	return false, nil
+}
+
+func (p *backupsPanel) confirmRestore(backup config.Backup) {
+	p.confirm = newConfirmModal(
+		"Restore backup",
+		fmt.Sprintf("Replace %s with the version replaced at %s? Unsaved changes are lost; the current file is backed up first.",
+			p.configMgr.Path(), backup.Time.Format("2006-01-02 15:04:05")),
+		p.restore(backup))
+}
+
+func (p *backupsPanel) View() string {
+	if p.confirm != nil {
+		return p.confirm.View(p.width)
+	}
+
+	content := []string{TitleStyle.Render("Backups of " + p.configMgr.Path()), ""}
+	if p.diff != nil {
+		content = append(content,
+			fmt.Sprintf("Backup from %s against the current file", p.diff.Time.Format("2006-01-02 15:04:05")),
+			"",
+			p.viewport.View(),
+			HelpStyle.Render("↑/↓/PgUp/PgDn: Scroll • r: Restore • Esc: Back to backups"))
+		return lipgloss.JoinVertical(lipgloss.Left, content...)
+	}
+
+	switch {
+	case p.loading:
+		content = append(content, "Loading backups...")
+	case p.err != nil:
+		content = append(content, ErrorBoxStyle.Render(p.err.Error()))
+	case len(p.backups) == 0:
+		content = append(content, StatusUnknownStyle.Render("No backups yet. Each save keeps the version it replaces."))
+	}
+	for i, backup := range p.backups {
+		line := fmt.Sprintf("%-20s %s", backup.Time.Format("2006-01-02 15:04:05"), filepath.Base(backup.Path))
+		if i == p.selected {
+			content = append(content, SelectedItemStyle.Render(line))
+		} else {
+			content = append(content, ListItemStyle.Render(line))
+		}
+	}
+	content = append(content, HelpStyle.Render("↑/↓: Select • Enter: Diff against current • r: Restore • Esc: Close"))
+	return lipgloss.JoinVertical(lipgloss.Left, content...)
+}
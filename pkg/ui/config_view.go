@@ -1,9 +1,11 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -23,21 +25,39 @@ type ConfigView struct {
 	height         int
 	selectedRunner int
 	editingRunner  bool
+	service        runner.Service
+	register       *registerForm
+	form           *runnerForm
+	preview        *savePreview
+	backups        *backupsPanel
+	// findings are the validation findings of the config as it is edited
+	findings []config.Finding
+	// changedOnDisk is set when config.toml was changed by someone else
+	// while the view has unsaved edits
+	changedOnDisk bool
+	watchPending  bool
+	watchAsked    time.Time
+	watchChecked  time.Time
 }
 
+// configWatchInterval is how often the Config tab checks whether
+// config.toml was changed by gitlab-runner or another tool.
+const configWatchInterval = 3 * time.Second
+
 const (
 	inputConcurrent = iota
 	inputCheckInterval
 	inputLogLevel
-	inputRunnerLimit
-	inputRunnerMaxBuilds
-	inputRunnerTags
 	inputCount
 )
 
 func NewConfigView(configPath string) *ConfigView {
-	configMgr := config.NewTOMLConfigManager(configPath)
+	return NewConfigViewWithManager(config.NewTOMLConfigManager(configPath))
+}
 
+// NewConfigViewWithManager returns a config view editing the file behind
+// configMgr, which may live on a remote host.
+func NewConfigViewWithManager(configMgr *config.TOMLConfigManager) *ConfigView {
 	inputs := make([]textinput.Model, inputCount)
 	for i := range inputs {
 		t := textinput.New()
@@ -54,15 +74,6 @@ func NewConfigView(configPath string) *ConfigView {
 	inputs[inputLogLevel].Placeholder = "Log level (debug/info/warn/error)"
 	inputs[inputLogLevel].Prompt = "Log Level: "
 
-	inputs[inputRunnerLimit].Placeholder = "Runner job limit"
-	inputs[inputRunnerLimit].Prompt = "Job Limit: "
-
-	inputs[inputRunnerMaxBuilds].Placeholder = "Max concurrent builds"
-	inputs[inputRunnerMaxBuilds].Prompt = "Max Builds: "
-
-	inputs[inputRunnerTags].Placeholder = "Comma-separated tags"
-	inputs[inputRunnerTags].Prompt = "Tags: "
-
 	return &ConfigView{
 		configMgr:  configMgr,
 		inputs:     inputs,
@@ -75,25 +86,79 @@ func (v *ConfigView) Init() tea.Cmd {
 }
 
 func (v *ConfigView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := v.update(msg)
+	// Only keys and (re)loads change the config or the settings being typed
+	switch msg.(type) {
+	case tea.KeyMsg, configLoadedMsg, backupRestoredMsg, runnerRegisteredMsg:
+		v.check()
+	}
+	return model, cmd
+}
+
+func (v *ConfigView) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		return v.handleWindowSize(msg)
 	case configLoadedMsg:
 		return v.handleConfigLoaded(msg)
+	case configWatchTickMsg:
+		if v.watchPending || time.Since(v.watchChecked) < configWatchInterval {
+			return v, nil
+		}
+		return v, v.checkConfig()
+	case configChangedMsg:
+		return v.handleConfigChanged(msg)
+	case savePreviewMsg:
+		return v.handleSavePreview(msg)
 	case configSavedMsg:
 		return v.handleConfigSaved(msg)
+	case runnerRegisteredMsg:
+		return v.handleRunnerRegistered(msg)
+	case backupsLoadedMsg:
+		if v.backups != nil {
+			v.backups.setBackups(msg)
+		}
+		return v, nil
+	case backupDiffMsg:
+		if v.backups != nil {
+			v.backups.setDiff(msg)
+		}
+		return v, nil
+	case backupRestoredMsg:
+		return v.handleBackupRestored(msg)
 	case tea.KeyMsg:
+		if v.preview != nil {
+			return v.handlePreviewKey(msg)
+		}
+		if v.backups != nil {
+			if closed, cmd := v.backups.handleKey(msg); !closed {
+				return v, cmd
+			}
+			v.backups = nil
+			return v, nil
+		}
+		if v.register != nil {
+			return v.handleRegisterKey(msg)
+		}
+		if v.form != nil {
+			return v.handleRunnerFormKey(msg)
+		}
+
 		switch msg.String() {
+		case "ctrl+n":
+			return v.startRegister()
+		case "ctrl+r":
+			return v.openBackups()
+		case "ctrl+l":
+			return v, v.reloadConfig("Reloaded " + v.configMgr.Path())
 		case "tab", "shift+tab":
 			return v.handleTabKey(msg.String() == "tab")
 		case "ctrl+s":
-			return v, v.saveConfig
+			return v.previewSave()
 		case "r", "R":
 			return v.handleRunnerEditToggle()
 		case "esc":
 			return v.handleEscape()
-		case "up", "down":
-			return v.handleArrowKeys(msg.String() == "up")
 		}
 	}
 
@@ -112,19 +177,49 @@ func (v *ConfigView) View() string {
 		HeaderStyle.Render("Configuration"),
 		"",
 	}
+	if v.changedOnDisk {
+		content = append(content, WarningBoxStyle.Render(fmt.Sprintf(
+			"%s was changed on disk and you have unsaved edits.\nSaving is refused so the change is not overwritten. Ctrl+L: Reload and drop your edits",
+			v.configMgr.Path())))
+	}
+
+	if v.register != nil {
+		content = append(content, v.register.View())
+		return lipgloss.JoinVertical(lipgloss.Left, content...)
+	}
+	if v.preview != nil {
+		content = append(content, v.preview.View())
+		return lipgloss.JoinVertical(lipgloss.Left, content...)
+	}
+	if v.backups != nil {
+		content = append(content, v.backups.View())
+		return lipgloss.JoinVertical(lipgloss.Left, content...)
+	}
 
+	findings := v.findings
 	if !v.editingRunner {
 		content = append(content, TitleStyle.Render("Global Settings"), "")
 
-		for i := 0; i < 3; i++ {
+		for i, key := range []string{"concurrent", "check_interval", "log_level"} {
 			style := InputStyle
 			if i == v.focusIndex {
 				style = FocusedInputStyle
 			}
-			content = append(content, style.Render(v.inputs[i].View()))
+			input := style.Render(v.inputs[i].View())
+			var inline []config.Finding
+			for _, f := range findings {
+				if f.Path == key {
+					inline = append(inline, f)
+				}
+			}
+			if len(inline) > 0 {
+				input = lipgloss.JoinHorizontal(lipgloss.Center, input, "  ", renderFindings(inline))
+			}
+			content = append(content, input)
 		}
 
 		content = append(content, "", InfoBoxStyle.Render(fmt.Sprintf("Total Runners: %d", len(v.config.Runners))))
+		content = append(content, v.problemsView(findings)...)
 	} else {
 		if len(v.config.Runners) == 0 {
 			content = append(content, ErrorBoxStyle.Render("No runners configured"))
@@ -134,15 +229,8 @@ func (v *ConfigView) View() string {
 				TitleStyle.Render(fmt.Sprintf("Runner: %s (%d/%d)", runner.Name, v.selectedRunner+1, len(v.config.Runners))),
 				"",
 				fmt.Sprintf("Executor: %s", runner.Executor),
-				"")
-
-			for i := 3; i < 6; i++ {
-				style := InputStyle
-				if i == v.focusIndex {
-					style = FocusedInputStyle
-				}
-				content = append(content, style.Render(v.inputs[i].View()))
-			}
+				"",
+				v.form.View(v.formHeight()))
 		}
 	}
 
@@ -172,22 +260,107 @@ func (v *ConfigView) updateInputs() {
 	}
 }
 
-func (v *ConfigView) updateRunnerInputs() {
-	if v.config == nil || len(v.config.Runners) == 0 {
+// check validates the config with the global settings as they are typed,
+// before they are applied, and passes the findings of the selected runner
+// to its form.
+func (v *ConfigView) check() {
+	v.findings = nil
+	if v.config == nil {
 		return
 	}
+	cfg := *v.config
+	if concurrent, err := strconv.Atoi(v.inputs[inputConcurrent].Value()); err == nil {
+		cfg.Concurrent = concurrent
+	}
+	if interval, err := strconv.Atoi(v.inputs[inputCheckInterval].Value()); err == nil {
+		cfg.CheckInterval = interval
+	}
+	v.findings = config.Check(&cfg)
+	if v.form != nil {
+		v.form.findings = runnerFindings(v.findings, v.selectedRunner)
+	}
+}
 
-	runner := v.config.Runners[v.selectedRunner]
-	v.inputs[inputRunnerLimit].SetValue(strconv.Itoa(runner.Limit))
-	v.inputs[inputRunnerMaxBuilds].SetValue(strconv.Itoa(runner.MaxBuilds))
-	v.inputs[inputRunnerTags].SetValue(strings.Join(runner.TagList, ","))
+// maxProblems is the number of runner findings the global settings list.
+const maxProblems = 8
+
+// problemsView lists the findings of the runners below the global
+// settings. Each is shown next to its field in the runner editor too.
+func (v *ConfigView) problemsView(findings []config.Finding) []string {
+	var errs, warnings int
+	var lines []string
+	for _, f := range findings {
+		for i, r := range v.config.Runners {
+			key, ok := strings.CutPrefix(f.Path, config.RunnerPath(i, ""))
+			if !ok {
+				continue
+			}
+			if f.Severity == config.SeverityError {
+				errs++
+			} else {
+				warnings++
+			}
+			lines = append(lines, fmt.Sprintf("%-20s %-32s %s", shorten(r.Name, 20), key, renderFindings([]config.Finding{f})))
+			break
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
 
-	v.inputs[inputRunnerLimit].Focus()
-	for i := 0; i < len(v.inputs); i++ {
-		if i != inputRunnerLimit {
-			v.inputs[i].Blur()
+	content := []string{TitleStyle.Render(fmt.Sprintf("Problems (errors: %d, warnings: %d)", errs, warnings))}
+	for _, line := range lines[:min(len(lines), maxProblems)] {
+		content = append(content, ListItemStyle.Render(line))
+	}
+	if len(lines) > maxProblems {
+		content = append(content, StatusUnknownStyle.Render(fmt.Sprintf("  ... and %d more", len(lines)-maxProblems)))
+	}
+	return append(content, HelpStyle.Render("r: Edit runners to see each problem next to its field"))
+}
+
+// runnerFindings returns the findings of the runner at index i by key,
+// relative to runner.RunnerConfig.
+func runnerFindings(findings []config.Finding, i int) map[string][]config.Finding {
+	prefix := config.RunnerPath(i, "")
+	byKey := make(map[string][]config.Finding)
+	for _, f := range findings {
+		if key, ok := strings.CutPrefix(f.Path, prefix); ok {
+			byKey[key] = append(byKey[key], f)
 		}
 	}
+	return byKey
+}
+
+// renderFindings shows the first, most severe, of the findings on a key.
+func renderFindings(findings []config.Finding) string {
+	f := findings[0]
+	text := f.Message
+	if len(findings) > 1 {
+		text += fmt.Sprintf(" (+%d more)", len(findings)-1)
+	}
+	if f.Severity == config.SeverityError {
+		return StatusInactiveStyle.Render("✗ " + text)
+	}
+	return StatusWarningStyle.Render("⚠ " + text)
+}
+
+// selectRunner opens the form of the selected runner, or none when
+// config.toml has no runners.
+func (v *ConfigView) selectRunner() {
+	v.form = nil
+	if v.config == nil || len(v.config.Runners) == 0 {
+		return
+	}
+	v.selectedRunner = min(v.selectedRunner, len(v.config.Runners)-1)
+	v.form = newRunnerForm(&v.config.Runners[v.selectedRunner])
+}
+
+// formHeight is the number of fields the runner form lists at once.
+func (v *ConfigView) formHeight() int {
+	if v.height == 0 {
+		return 20
+	}
+	return v.height - 24
 }
 
 func (v *ConfigView) loadConfig() tea.Msg {
@@ -197,11 +370,80 @@ func (v *ConfigView) loadConfig() tea.Msg {
 	return configLoadedMsg{config: v.configMgr.GetConfig()}
 }
 
-func (v *ConfigView) saveConfig() tea.Msg {
-	if v.config == nil {
-		return configSavedMsg{err: fmt.Errorf("no config loaded")}
+// reloadConfig loads config.toml again, dropping unsaved edits, and shows
+// notice once it is loaded.
+func (v *ConfigView) reloadConfig(notice string) tea.Cmd {
+	return func() tea.Msg {
+		msg := v.loadConfig().(configLoadedMsg)
+		msg.notice = notice
+		return msg
 	}
+}
 
+// Activate resumes watching config.toml when the tab is shown again.
+// Messages are only delivered to the active tab, so ticks and results sent
+// while the config was hidden are lost.
+func (v *ConfigView) Activate() tea.Cmd {
+	if v.watchPending && time.Since(v.watchAsked) < time.Minute {
+		return nil
+	}
+	if !v.watchPending && time.Since(v.watchChecked) < configWatchInterval {
+		return nil
+	}
+	return v.checkConfig()
+}
+
+// checkConfig asks whether config.toml changed since it was loaded.
+func (v *ConfigView) checkConfig() tea.Cmd {
+	v.watchPending = true
+	v.watchAsked = time.Now()
+	configMgr := v.configMgr
+	return func() tea.Msg {
+		changed, err := configMgr.Changed()
+		return configChangedMsg{changed: changed, err: err}
+	}
+}
+
+// watchTick schedules the next check of config.toml.
+func (v *ConfigView) watchTick() tea.Cmd {
+	v.watchChecked = time.Now()
+	return tea.Tick(configWatchInterval, func(time.Time) tea.Msg {
+		return configWatchTickMsg{}
+	})
+}
+
+// handleConfigChanged reloads a config.toml that was changed by someone
+// else, unless that would drop unsaved edits.
+func (v *ConfigView) handleConfigChanged(msg configChangedMsg) (tea.Model, tea.Cmd) {
+	v.watchPending = false
+	// A file that cannot be read now is checked again at the next tick
+	if msg.err != nil || !msg.changed || v.config == nil {
+		return v, v.watchTick()
+	}
+
+	switch {
+	case v.modified():
+		v.changedOnDisk = true
+		return v, v.watchTick()
+	case v.Capturing():
+		// Reloaded once the dialog is closed
+		return v, v.watchTick()
+	}
+	return v, v.reloadConfig(v.configMgr.Path() + " was changed on disk and reloaded")
+}
+
+// modified reports whether the view has edits that are not saved.
+func (v *ConfigView) modified() bool {
+	if v.configMgr.Modified() {
+		return true
+	}
+	return v.inputs[inputConcurrent].Value() != strconv.Itoa(v.config.Concurrent) ||
+		v.inputs[inputCheckInterval].Value() != strconv.Itoa(v.config.CheckInterval) ||
+		v.inputs[inputLogLevel].Value() != v.config.LogLevel
+}
+
+// applyInputs writes the global settings to the loaded config.
+func (v *ConfigView) applyInputs() {
 	if concurrent, err := strconv.Atoi(v.inputs[inputConcurrent].Value()); err == nil {
 		_ = v.configMgr.UpdateConcurrency(concurrent)
 	}
@@ -213,27 +455,60 @@ func (v *ConfigView) saveConfig() tea.Msg {
 	if logLevel := v.inputs[inputLogLevel].Value(); logLevel != "" {
 		_ = v.configMgr.UpdateLogLevel(logLevel)
 	}
+}
 
-	if v.editingRunner && len(v.config.Runners) > 0 {
-		runner := v.config.Runners[v.selectedRunner]
+// previewSave applies the global settings and checks the config, then
+// shows the changes saving makes. The runner form writes its fields to the
+// loaded config as they are applied.
+func (v *ConfigView) previewSave() (tea.Model, tea.Cmd) {
+	v.successMsg = ""
+	if v.config == nil {
+		v.err = fmt.Errorf("no config loaded")
+		return v, nil
+	}
+	v.applyInputs()
+	if v.err = v.configMgr.Validate(); v.err != nil {
+		return v, nil
+	}
 
-		if limit, err := strconv.Atoi(v.inputs[inputRunnerLimit].Value()); err == nil {
-			_ = v.configMgr.UpdateRunnerLimit(runner.Name, limit)
+	configMgr := v.configMgr
+	return v, func() tea.Msg {
+		if changed, err := configMgr.Changed(); err == nil && changed {
+			return savePreviewMsg{err: config.ErrChangedOnDisk}
 		}
+		diff, err := configMgr.PendingDiff()
+		return savePreviewMsg{diff: diff, err: err}
+	}
+}
 
-		if maxBuilds, err := strconv.Atoi(v.inputs[inputRunnerMaxBuilds].Value()); err == nil {
-			_ = v.configMgr.UpdateRunnerMaxBuilds(runner.Name, maxBuilds)
-		}
+func (v *ConfigView) handleSavePreview(msg savePreviewMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case errors.Is(msg.err, config.ErrChangedOnDisk):
+		v.changedOnDisk = true
+	case msg.err != nil:
+		v.err = msg.err
+	case msg.diff == "":
+		v.successMsg = "No changes to save"
+	default:
+		v.preview = newSavePreview(msg.diff, v.width, v.height)
+	}
+	return v, nil
+}
 
-		if tags := v.inputs[inputRunnerTags].Value(); tags != "" {
-			tagList := strings.Split(tags, ",")
-			for i := range tagList {
-				tagList[i] = strings.TrimSpace(tagList[i])
-			}
-			_ = v.configMgr.UpdateRunnerTags(runner.Name, tagList)
-		}
+func (v *ConfigView) handlePreviewKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	done, save, cmd := v.preview.handleKey(msg)
+	if !done {
+		return v, cmd
+	}
+	v.preview = nil
+	if !save {
+		v.successMsg = "Save cancelled"
+		return v, nil
 	}
+	return v, v.saveConfig
+}
 
+func (v *ConfigView) saveConfig() tea.Msg {
 	if err := v.configMgr.Validate(); err != nil {
 		return configSavedMsg{err: err}
 	}
@@ -248,28 +523,67 @@ func (v *ConfigView) saveConfig() tea.Msg {
 type configLoadedMsg struct {
 	config *runner.Config
 	err    error
+	// notice is shown once the config is loaded
+	notice string
+}
+
+type configWatchTickMsg struct{}
+
+type configChangedMsg struct {
+	changed bool
+	err     error
+}
+
+type savePreviewMsg struct {
+	diff string
+	err  error
 }
 
 type configSavedMsg struct {
 	err error
 }
 
+type runnerRegisteredMsg struct {
+	name string
+	err  error
+}
+
 func (v *ConfigView) handleWindowSize(msg tea.WindowSizeMsg) (tea.Model, tea.Cmd) {
 	v.width = msg.Width
 	v.height = msg.Height
+	if v.preview != nil {
+		v.preview.setSize(msg.Width, msg.Height)
+	}
+	if v.backups != nil {
+		v.backups.setSize(msg.Width, msg.Height)
+	}
 	return v, nil
 }
 
 func (v *ConfigView) handleConfigLoaded(msg configLoadedMsg) (tea.Model, tea.Cmd) {
 	v.config = msg.config
 	v.err = msg.err
+	v.changedOnDisk = false
+	v.successMsg = ""
 	if v.config != nil {
 		v.updateInputs()
+		v.successMsg = msg.notice
 	}
-	return v, nil
+	if v.editingRunner {
+		v.selectRunner()
+	}
+	if v.watchPending {
+		return v, nil
+	}
+	return v, v.watchTick()
 }
 
 func (v *ConfigView) handleConfigSaved(msg configSavedMsg) (tea.Model, tea.Cmd) {
+	if errors.Is(msg.err, config.ErrChangedOnDisk) {
+		v.changedOnDisk = true
+		v.successMsg = ""
+		return v, nil
+	}
 	if msg.err != nil {
 		v.err = msg.err
 		v.successMsg = ""
@@ -290,11 +604,7 @@ func (v *ConfigView) handleTabKey(forward bool) (tea.Model, tea.Cmd) {
 		v.focusIndex--
 	}
 
-	inputsCount := 3
-	if v.editingRunner {
-		inputsCount = 6
-	}
-
+	inputsCount := inputCount
 	if v.focusIndex < 0 {
 		v.focusIndex = inputsCount - 1
 	} else if v.focusIndex >= inputsCount {
@@ -314,8 +624,10 @@ func (v *ConfigView) handleTabKey(forward bool) (tea.Model, tea.Cmd) {
 func (v *ConfigView) handleRunnerEditToggle() (tea.Model, tea.Cmd) {
 	if !v.editingRunner {
 		v.editingRunner = true
-		v.focusIndex = 3
-		v.updateRunnerInputs()
+		for i := range v.inputs {
+			v.inputs[i].Blur()
+		}
+		v.selectRunner()
 	}
 	return v, nil
 }
@@ -323,27 +635,43 @@ func (v *ConfigView) handleRunnerEditToggle() (tea.Model, tea.Cmd) {
 func (v *ConfigView) handleEscape() (tea.Model, tea.Cmd) {
 	if v.editingRunner {
 		v.editingRunner = false
+		v.form = nil
 		v.focusIndex = 0
+		v.inputs[inputConcurrent].Focus()
 	}
 	return v, nil
 }
 
-func (v *ConfigView) handleArrowKeys(up bool) (tea.Model, tea.Cmd) {
-	if v.editingRunner && v.config != nil && len(v.config.Runners) > 0 {
-		if up {
-			v.selectedRunner--
-			if v.selectedRunner < 0 {
-				v.selectedRunner = len(v.config.Runners) - 1
-			}
+// handleRunnerFormKey handles keys in runner edit mode. Keys go to the form
+// while it edits a field; otherwise [ and ] select another runner and Esc
+// goes back to the global settings.
+func (v *ConfigView) handleRunnerFormKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if v.form.capturing() {
+		return v, v.form.handleKey(msg)
+	}
+
+	switch msg.String() {
+	case "ctrl+n":
+		return v.startRegister()
+	case "ctrl+r":
+		return v.openBackups()
+	case "ctrl+l":
+		return v, v.reloadConfig("Reloaded " + v.configMgr.Path())
+	case "ctrl+s":
+		return v.previewSave()
+	case "esc":
+		return v.handleEscape()
+	case "[", "]":
+		if msg.String() == "[" {
+			v.selectedRunner = (v.selectedRunner - 1 + len(v.config.Runners)) % len(v.config.Runners)
 		} else {
-			v.selectedRunner++
-			if v.selectedRunner >= len(v.config.Runners) {
-				v.selectedRunner = 0
-			}
+			v.selectedRunner = (v.selectedRunner + 1) % len(v.config.Runners)
 		}
-		v.updateRunnerInputs()
+		v.selectRunner()
+		return v, nil
 	}
-	return v, nil
+	v.successMsg = ""
+	return v, v.form.handleKey(msg)
 }
 
 func (v *ConfigView) updateAllInputs(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -357,3 +685,121 @@ func (v *ConfigView) updateAllInputs(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	return v, tea.Batch(cmds...)
 }
+
+// SetService sets the service runners are registered with. It must manage
+// the host whose config.toml the view edits.
+func (v *ConfigView) SetService(service runner.Service) {
+	v.service = service
+}
+
+// ConfigPath returns the path of the config.toml the view edits.
+func (v *ConfigView) ConfigPath() string {
+	return v.configMgr.Path()
+}
+
+// Capturing reports whether the registration form, the save preview, the
+// backups panel or a field of the runner form has the keyboard, so global
+// key bindings must not be applied.
+func (v *ConfigView) Capturing() bool {
+	return v.register != nil || v.preview != nil || v.backups != nil || (v.form != nil && v.form.capturing())
+}
+
+func (v *ConfigView) openBackups() (tea.Model, tea.Cmd) {
+	v.err = nil
+	v.successMsg = ""
+	v.backups = newBackupsPanel(v.configMgr, v.width, v.height)
+	return v, v.backups.load
+}
+
+// handleBackupRestored shows the restored config. The panel stays open
+// with the error when restoring failed.
+func (v *ConfigView) handleBackupRestored(msg backupRestoredMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		if v.backups != nil {
+			v.backups.err = msg.err
+			v.backups.diff = nil
+		}
+		return v, nil
+	}
+
+	v.backups = nil
+	v.config = v.configMgr.GetConfig()
+	v.updateInputs()
+	if v.editingRunner {
+		v.selectRunner()
+	}
+	v.err = nil
+	v.successMsg = fmt.Sprintf("Restored the version replaced at %s", msg.backup.Time.Format("2006-01-02 15:04:05"))
+	return v, nil
+}
+
+func (v *ConfigView) startRegister() (tea.Model, tea.Cmd) {
+	if v.service == nil {
+		v.err = fmt.Errorf("registering runners is not available")
+		return v, nil
+	}
+	v.err = nil
+	v.successMsg = ""
+	v.register = newRegisterForm()
+	return v, textinput.Blink
+}
+
+func (v *ConfigView) handleRegisterKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	cancel, submit, cmd := v.register.handleKey(msg)
+	switch {
+	case cancel:
+		v.register = nil
+		return v, nil
+	case submit:
+		opts := v.register.options()
+		if err := opts.Validate(); err != nil {
+			v.register.err = err
+			return v, nil
+		}
+		v.register.err = nil
+		v.register.running = true
+		return v, v.registerRunner(opts)
+	}
+	return v, cmd
+}
+
+// registerRunner runs gitlab-runner register and reloads config.toml, so
+// the new runner is listed right away.
+func (v *ConfigView) registerRunner(opts runner.RegisterOptions) tea.Cmd {
+	service, configMgr := v.service, v.configMgr
+	return func() tea.Msg {
+		if err := service.RegisterRunner(opts); err != nil {
+			return runnerRegisteredMsg{err: err}
+		}
+		if err := configMgr.Load(); err != nil {
+			return runnerRegisteredMsg{err: fmt.Errorf("runner registered, but reloading config failed: %w", err)}
+		}
+		return runnerRegisteredMsg{name: opts.Description}
+	}
+}
+
+func (v *ConfigView) handleRunnerRegistered(msg runnerRegisteredMsg) (tea.Model, tea.Cmd) {
+	if v.register == nil {
+		return v, nil
+	}
+	v.register.running = false
+	if msg.err != nil {
+		// The form stays open with gitlab-runner's output so the entries
+		// can be corrected
+		v.register.err = msg.err
+		return v, nil
+	}
+
+	v.register = nil
+	v.config = v.configMgr.GetConfig()
+	v.editingRunner = false
+	v.form = nil
+	v.focusIndex = 0
+	v.updateInputs()
+	v.err = nil
+	v.successMsg = "Runner registered"
+	if msg.name != "" {
+		v.successMsg = fmt.Sprintf("Runner %s registered", msg.name)
+	}
+	return v, nil
+}
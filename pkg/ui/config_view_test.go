@@ -0,0 +1,485 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/config"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+// registerService appends a runner to config.toml like gitlab-runner
+// register does, or fails with err.
+type registerService struct {
+	runner.Service
+	path       string
+	registered []runner.RegisterOptions
+	err        error
+}
+
+func (s *registerService) RegisterRunner(opts runner.RegisterOptions) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.registered = append(s.registered, opts)
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString("\n[[runners]]\n  name = \"" + opts.Description + "\"\n  token = \"glrt-new\"\n  executor = \"" + opts.Executor + "\"\n")
+	return err
+}
+
+func newRegisterConfigView(t *testing.T, service *registerService) *ConfigView {
+	t.Helper()
+	service.path = filepath.Join(t.TempDir(), "config.toml")
+	content := "concurrent = 1\n\n[[runners]]\n  name = \"shell-1\"\n  token = \"a\"\n  executor = \"shell\"\n"
+	if err := os.WriteFile(service.path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	v := NewConfigViewWithManager(config.NewTOMLConfigManager(service.path))
+	v.SetService(service)
+	v.Update(v.loadConfig())
+	return v
+}
+
+// typeInto types s into the focused field and moves to the next one.
+func typeInto(v *ConfigView, s string) {
+	for _, r := range s {
+		v.Update(key(string(r)))
+	}
+	v.Update(tea.KeyMsg{Type: tea.KeyTab})
+}
+
+func runConfig(v *ConfigView, cmd tea.Cmd) {
+	if cmd != nil {
+		v.Update(cmd())
+	}
+}
+
+func TestConfigView_RegisterRunner(t *testing.T) {
+	service := &registerService{}
+	v := newRegisterConfigView(t, service)
+
+	v.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	if !v.Capturing() {
+		t.Fatal("expected registration form")
+	}
+
+	typeInto(v, "https://gitlab.example.com")
+	typeInto(v, "glrt-abc")
+	// shell → docker
+	v.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if !strings.Contains(v.View(), "Docker Image") {
+		t.Fatalf("expected docker image field for docker executor:\n%s", v.View())
+	}
+	v.Update(tea.KeyMsg{Type: tea.KeyTab})
+	typeInto(v, "docker-2")
+	typeInto(v, "")
+	for _, r := range "alpine:3.20" {
+		v.Update(key(string(r)))
+	}
+	_, cmd := v.Update(key("enter"))
+	runConfig(v, cmd)
+
+	if len(service.registered) != 1 {
+		t.Fatalf("expected one registration, got %v", service.registered)
+	}
+	opts := service.registered[0]
+	if opts.Executor != "docker" || opts.DockerImage != "alpine:3.20" || opts.Token != "glrt-abc" {
+		t.Errorf("unexpected options %+v", opts)
+	}
+	if v.Capturing() || !strings.Contains(v.successMsg, "docker-2 registered") {
+		t.Errorf("expected form to close with a notice, got %q", v.successMsg)
+	}
+	if len(v.config.Runners) != 2 || v.config.Runners[1].Name != "docker-2" {
+		t.Errorf("expected reloaded config with the new runner, got %+v", v.config.Runners)
+	}
+}
+
+func TestConfigView_RegisterFailureShowsOutput(t *testing.T) {
+	service := &registerService{err: errors.New("failed to register runner: exit status 1: ERROR: Verifying runner... is not valid")}
+	v := newRegisterConfigView(t, service)
+
+	v.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	typeInto(v, "https://gitlab.example.com")
+	typeInto(v, "glrt-expired")
+	_, cmd := v.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	runConfig(v, cmd)
+
+	if !v.Capturing() || !strings.Contains(v.View(), "is not valid") {
+		t.Errorf("expected form to stay open with the error:\n%s", v.View())
+	}
+}
+
+func TestConfigView_RegisterValidatesBeforeRunning(t *testing.T) {
+	service := &registerService{}
+	v := newRegisterConfigView(t, service)
+
+	v.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	typeInto(v, "gitlab.example.com")
+	_, cmd := v.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+
+	if cmd != nil || len(service.registered) != 0 {
+		t.Fatal("invalid options were submitted")
+	}
+	if !strings.Contains(v.View(), "http or https URL") {
+		t.Errorf("expected validation error:\n%s", v.View())
+	}
+
+	v.Update(key("esc"))
+	if v.Capturing() {
+		t.Error("expected Esc to close the form")
+	}
+}
+
+func newRunnerFormConfigView(t *testing.T) (*ConfigView, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := "concurrent = 2\n\n[[runners]]\n  name = \"docker-1\"\n  url = \"https://gitlab.example.com\"\n  token = \"a\"\n  executor = \"docker\"\n  [runners.docker]\n    image = \"alpine\"\n\n[[runners]]\n  name = \"shell-1\"\n  url = \"https://gitlab.example.com\"\n  token = \"b\"\n  executor = \"shell\"\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	v := NewConfigView(path)
+	v.Update(v.loadConfig())
+	v.Update(key("r"))
+	if v.form == nil {
+		t.Fatal("expected runner form")
+	}
+	return v, path
+}
+
+// focusField moves the runner form's focus down to key.
+func focusField(t *testing.T, v *ConfigView, key string) {
+	t.Helper()
+	for range v.form.fields() {
+		if v.form.focused().Key == key {
+			return
+		}
+		v.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	t.Fatalf("no field %s in section %s", key, v.form.sections[v.form.section].name)
+}
+
+func TestConfigView_RunnerFormSections(t *testing.T) {
+	v, _ := newRunnerFormConfigView(t)
+
+	view := v.View()
+	for _, section := range []string{"General", "Scripts", "Docker"} {
+		if !strings.Contains(view, section) {
+			t.Errorf("expected %s section:\n%s", section, view)
+		}
+	}
+	if strings.Contains(view, "Kubernetes") || strings.Contains(view, "Machine") {
+		t.Errorf("expected sections of other executors to be hidden:\n%s", view)
+	}
+	if strings.Contains(view, "token") {
+		t.Errorf("expected token to be left out:\n%s", view)
+	}
+
+	v.Update(key("]"))
+	if v.form.runner.Name != "shell-1" || len(v.form.sections) != 2 {
+		t.Errorf("expected shell runner with General and Scripts, got %s with %d sections", v.form.runner.Name, len(v.form.sections))
+	}
+	v.Update(key("esc"))
+	if v.editingRunner || v.form != nil {
+		t.Error("expected Esc to leave runner mode")
+	}
+}
+
+func TestConfigView_RunnerFormEditsAndSaves(t *testing.T) {
+	v, path := newRunnerFormConfigView(t)
+
+	// An invalid value keeps the field open with the error
+	focusField(t, v, "limit")
+	v.Update(key("enter"))
+	v.Update(tea.KeyMsg{Type: tea.KeyCtrlU})
+	for _, r := range "four" {
+		v.Update(key(string(r)))
+	}
+	v.Update(key("enter"))
+	if !v.Capturing() || !strings.Contains(v.View(), "is not a whole number") {
+		t.Fatalf("expected validation error:\n%s", v.View())
+	}
+	v.Update(tea.KeyMsg{Type: tea.KeyCtrlU})
+	v.Update(key("4"))
+	v.Update(key("enter"))
+	if v.Capturing() || v.config.Runners[0].Limit != 4 {
+		t.Fatalf("expected limit 4, got %d", v.config.Runners[0].Limit)
+	}
+
+	// General → Scripts → Docker
+	v.Update(tea.KeyMsg{Type: tea.KeyRight})
+	v.Update(tea.KeyMsg{Type: tea.KeyRight})
+	focusField(t, v, "docker.privileged")
+	v.Update(key("enter"))
+
+	focusField(t, v, "docker.volumes")
+	v.Update(key("enter"))
+	for _, volume := range []string{"/cache", "/tmp", "/builds"} {
+		v.Update(key("a"))
+		for _, r := range volume {
+			v.Update(key(string(r)))
+		}
+		v.Update(key("enter"))
+	}
+	v.Update(tea.KeyMsg{Type: tea.KeyDown})
+	v.Update(key("d"))
+	v.Update(key("esc"))
+	if v.Capturing() {
+		t.Fatal("expected Esc to close the entries")
+	}
+
+	_, cmd := v.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	runConfig(v, cmd)
+	_, cmd = v.Update(key("y"))
+	runConfig(v, cmd)
+	if v.err != nil {
+		t.Fatalf("save failed: %v", v.err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"limit = 4", "privileged = true", `volumes = ["/cache", "/builds"]`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %q in saved config:\n%s", want, data)
+		}
+	}
+}
+
+func TestConfigView_SavePreview(t *testing.T) {
+	v, path := newRunnerFormConfigView(t)
+	original, _ := os.ReadFile(path)
+
+	_, cmd := v.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	runConfig(v, cmd)
+	if v.preview != nil || !strings.Contains(v.View(), "No changes to save") {
+		t.Fatalf("expected no preview without changes:\n%s", v.View())
+	}
+
+	v.config.Runners[0].Limit = 4
+	_, cmd = v.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	runConfig(v, cmd)
+	if !v.Capturing() || !strings.Contains(v.View(), "+  limit = 4") || !strings.Contains(v.View(), "1 lines added, 0 removed") {
+		t.Fatalf("expected diff preview:\n%s", v.View())
+	}
+
+	// The full diff opens in a viewport; Esc goes back to the summary
+	v.Update(key("v"))
+	if !v.preview.expanded || !strings.Contains(v.View(), "Position:") {
+		t.Fatalf("expected full diff:\n%s", v.View())
+	}
+	v.Update(key("esc"))
+	if v.preview == nil || v.preview.expanded {
+		t.Fatal("expected Esc to go back to the summary")
+	}
+
+	_, cmd = v.Update(key("n"))
+	if cmd != nil || v.Capturing() || !strings.Contains(v.View(), "Save cancelled") {
+		t.Fatalf("expected cancelled save:\n%s", v.View())
+	}
+	if data, _ := os.ReadFile(path); string(data) != string(original) {
+		t.Errorf("cancelled save wrote the file:\n%s", data)
+	}
+
+	_, cmd = v.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	runConfig(v, cmd)
+	_, cmd = v.Update(key("enter"))
+	runConfig(v, cmd)
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "limit = 4") {
+		t.Errorf("expected confirmed save to write the file:\n%s", data)
+	}
+}
+
+// saveView saves the view's config through the save preview.
+func saveView(t *testing.T, v *ConfigView) {
+	t.Helper()
+	_, cmd := v.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	runConfig(v, cmd)
+	_, cmd = v.Update(key("y"))
+	runConfig(v, cmd)
+	if v.err != nil {
+		t.Fatalf("save failed: %v", v.err)
+	}
+}
+
+func TestConfigView_Backups(t *testing.T) {
+	v, path := newRunnerFormConfigView(t)
+	v.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	original, _ := os.ReadFile(path)
+
+	v.config.Runners[0].Limit = 4
+	saveView(t, v)
+	v.config.Runners[0].Limit = 6
+	saveView(t, v)
+
+	_, cmd := v.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	runConfig(v, cmd)
+	if !v.Capturing() || len(v.backups.backups) != 2 || !strings.Contains(v.View(), "config.toml.bak.") {
+		t.Fatalf("expected two backups listed:\n%s", v.View())
+	}
+
+	// The oldest backup is the file as it was before the first save
+	v.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, cmd = v.Update(key("enter"))
+	runConfig(v, cmd)
+	if !strings.Contains(v.View(), "+  limit = 6") {
+		t.Fatalf("expected diff against the current file:\n%s", v.View())
+	}
+
+	v.Update(key("r"))
+	if !strings.Contains(v.View(), "Restore backup") {
+		t.Fatalf("expected confirmation:\n%s", v.View())
+	}
+	_, cmd = v.Update(key("y"))
+	runConfig(v, cmd)
+
+	if v.backups != nil || !strings.Contains(v.View(), "Restored the version") {
+		t.Fatalf("expected panel to close with a notice:\n%s", v.View())
+	}
+	if data, _ := os.ReadFile(path); string(data) != string(original) {
+		t.Errorf("expected original file restored, got:\n%s", data)
+	}
+	if v.config.Runners[0].Limit != 0 || v.form.runner != &v.config.Runners[0] {
+		t.Errorf("expected the form to edit the restored config")
+	}
+}
+
+// changeOnDisk rewrites config.toml the way another tool would, with
+// concurrent set to 5.
+func changeOnDisk(t *testing.T, path string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := strings.Replace(string(data), "concurrent = 2", "concurrent = 5", 1)
+	if err := os.WriteFile(path, []byte(changed), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigView_ReloadsExternalChanges(t *testing.T) {
+	v, path := newRunnerFormConfigView(t)
+
+	_, cmd := v.Update(v.checkConfig()())
+	if cmd == nil {
+		t.Fatal("expected the next check to be scheduled")
+	}
+
+	changeOnDisk(t, path)
+	_, cmd = v.Update(v.checkConfig()())
+	runConfig(v, cmd)
+
+	if v.config.Concurrent != 5 || v.inputs[inputConcurrent].Value() != "5" {
+		t.Errorf("expected the changed file to be loaded, got concurrent %d", v.config.Concurrent)
+	}
+	if v.changedOnDisk || !strings.Contains(v.View(), "changed on disk and reloaded") {
+		t.Errorf("expected reload notice:\n%s", v.View())
+	}
+	if v.form == nil || v.form.runner != &v.config.Runners[0] {
+		t.Error("expected the form to edit the reloaded config")
+	}
+}
+
+func TestConfigView_KeepsEditsOnExternalChanges(t *testing.T) {
+	v, path := newRunnerFormConfigView(t)
+	v.config.Runners[0].Limit = 4
+
+	changeOnDisk(t, path)
+	_, cmd := v.Update(v.checkConfig()())
+	runConfig(v, cmd)
+	if !v.changedOnDisk || v.config.Runners[0].Limit != 4 {
+		t.Fatal("expected edits to be kept")
+	}
+	if !strings.Contains(v.View(), "changed on disk and you have unsaved edits") {
+		t.Fatalf("expected banner:\n%s", v.View())
+	}
+
+	_, cmd = v.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	runConfig(v, cmd)
+	if v.preview != nil {
+		t.Fatal("expected save to be refused")
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "concurrent = 5") {
+		t.Errorf("external change overwritten:\n%s", data)
+	}
+
+	_, cmd = v.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+	runConfig(v, cmd)
+	if v.changedOnDisk || strings.Contains(v.View(), "unsaved edits") {
+		t.Errorf("expected banner to be gone after reload:\n%s", v.View())
+	}
+	if v.config.Concurrent != 5 || v.config.Runners[0].Limit != 0 {
+		t.Errorf("expected the changed file without edits, got concurrent %d limit %d", v.config.Concurrent, v.config.Runners[0].Limit)
+	}
+}
+
+func TestConfigView_ShowsFindings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := "concurrent = 2\n\n[[runners]]\n  name = \"docker-1\"\n  url = \"https://gitlab.example.com\"\n  token = \"a\"\n  executor = \"docker\"\n  limit = 8\n  [runners.docker]\n    image = \"alpine\"\n    memory = \"lots\"\n\n[[runners]]\n  name = \"shell-1\"\n  url = \"https://gitlab.example.com\"\n  token = \"a\"\n  executor = \"shell\"\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	v := NewConfigView(path)
+	v.Update(tea.WindowSizeMsg{Width: 160, Height: 50})
+	v.Update(v.loadConfig())
+
+	view := v.View()
+	for _, want := range []string{
+		"Problems (errors: 2, warnings: 1)",
+		`"lots" is not a size`,
+		"token is also used by runners[0]",
+		"limit 8 is above concurrent 2",
+	} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in global settings:\n%s", want, view)
+		}
+	}
+
+	// Findings follow the global settings as they are typed
+	v.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	v.Update(key("0"))
+	view = v.View()
+	if !strings.Contains(view, "must be at least 1") || strings.Contains(view, "above concurrent") {
+		t.Errorf("expected findings for concurrent 0:\n%s", view)
+	}
+	v.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	v.Update(key("2"))
+
+	v.Update(key("r"))
+	view = v.View()
+	if !strings.Contains(view, "Docker (1)") || !strings.Contains(view, "limit 8 is above concurrent 2") {
+		t.Errorf("expected findings in the runner form:\n%s", view)
+	}
+	focusField(t, v, "limit")
+	if !strings.Contains(v.View(), "above concurrent") {
+		t.Errorf("expected finding next to the focused field:\n%s", v.View())
+	}
+
+	// The token is not in the form, so its finding is listed above it
+	v.Update(key("]"))
+	if !strings.Contains(v.View(), "token") || !strings.Contains(v.View(), "also used by runners[0]") {
+		t.Errorf("expected token finding for the second runner:\n%s", v.View())
+	}
+
+	// Fixing a field clears its finding
+	v.Update(key("["))
+	focusField(t, v, "limit")
+	v.Update(key("enter"))
+	v.form.input.SetValue("2")
+	v.Update(key("enter"))
+	if strings.Contains(v.View(), "above concurrent") {
+		t.Errorf("expected finding to be gone after the fix:\n%s", v.View())
+	}
+}
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/export"
+)
+
+// exportPrompt asks for the file to export to. The format follows the file
+// extension; Tab switches the extension to the next format. Entering the
+// name of an existing file asks before replacing it.
+type exportPrompt struct {
+	input textinput.Model
+	// overwrite is set once the entered file was found to exist; Enter
+	// again replaces it
+	overwrite bool
+}
+
+// newExportPrompt suggests a file in the working directory named after
+// name and the current time.
+func newExportPrompt(name string) (*exportPrompt, tea.Cmd) {
+	input := textinput.New()
+	input.Prompt = "Export to: "
+	input.CharLimit = 1024
+	input.SetValue(name + time.Now().Format("-20060102-150405") + export.Text.Extension())
+	input.CursorEnd()
+
+	p := &exportPrompt{input: input}
+	return p, p.input.Focus()
+}
+
+// handleKey returns whether the prompt is done and the path entered, which
+// is empty when the export was canceled.
+func (p *exportPrompt) handleKey(msg tea.KeyMsg) (done bool, path string, cmd tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		path := strings.TrimSpace(p.input.Value())
+		if path == "" {
+			return false, "", nil
+		}
+		if !p.overwrite && export.Exists(path) {
+			p.overwrite = true
+			return false, "", nil
+		}
+		return true, path, nil
+	case "esc":
+		return true, "", nil
+	case "tab":
+		p.nextFormat()
+		p.overwrite = false
+		return false, "", nil
+	}
+
+	value := p.input.Value()
+	p.input, cmd = p.input.Update(msg)
+	if p.input.Value() != value {
+		p.overwrite = false
+	}
+	return false, "", cmd
+}
+
+func (p *exportPrompt) nextFormat() {
+	path := p.input.Value()
+	format := export.FormatOf(path)
+	next := export.Formats[0]
+	for i, f := range export.Formats {
+		if f == format {
+			next = export.Formats[(i+1)%len(export.Formats)]
+		}
+	}
+
+	ext := filepath.Ext(path)
+	if ext != "" && !strings.ContainsRune(ext, filepath.Separator) {
+		path = strings.TrimSuffix(path, ext)
+	}
+	p.input.SetValue(path + next.Extension())
+	p.input.CursorEnd()
+}
+
+func (p *exportPrompt) View() string {
+	if p.overwrite {
+		warning := StatusWarningStyle.Render("The file exists. Enter: Overwrite • Esc: Cancel")
+		return FocusedInputStyle.Render(p.input.View()) + "\n" + warning
+	}
+	help := fmt.Sprintf("Enter: Export • Tab: Format (%s) • Esc: Cancel", export.FormatOf(p.input.Value()))
+	return FocusedInputStyle.Render(p.input.View()) + "\n" + HelpStyle.Render(help)
+}
+
+// exportCmd writes a file with write and reports the outcome in an
+// exportedMsg; what describes what was written, as in "42 jobs". An
+// existing file is replaced only with overwrite.
+func exportCmd(path, what string, overwrite bool, write func(io.Writer, export.Format) error) tea.Cmd {
+	return func() tea.Msg {
+		written, err := export.ToFile(path, overwrite, write)
+		if err != nil {
+			return exportedMsg{err: err}
+		}
+		if abs, err := filepath.Abs(written); err == nil {
+			written = abs
+		}
+		return exportedMsg{notice: fmt.Sprintf("Exported %s to %s", what, written)}
+	}
+}
+
+// count returns n with noun, pluralized for n other than 1.
+func count(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+type exportedMsg struct {
+	notice string
+	err    error
+}
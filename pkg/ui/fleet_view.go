@@ -0,0 +1,305 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+const (
+	fleetPollInterval = 15 * time.Second
+	fleetHostTimeout  = 10 * time.Second
+)
+
+// FleetHost is one machine shown on the fleet dashboard.
+type FleetHost struct {
+	Name    string
+	Service runner.Service
+}
+
+// HostSummary is the result of polling one host.
+type HostSummary struct {
+	Name        string
+	Status      *runner.SystemStatus
+	Runners     int
+	RunningJobs int
+	Err         error
+	Refreshed   time.Time
+}
+
+// HostSelectedMsg asks for the other tabs to be scoped to a host.
+type HostSelectedMsg struct {
+	Name string
+}
+
+type FleetView struct {
+	table     table.Model
+	hosts     []FleetHost
+	summaries []HostSummary
+	timeout   time.Duration
+	polling   bool
+	pollStart time.Time
+	lastPoll  time.Time
+	current   string
+	width     int
+	height    int
+	spinner   spinner.Model
+}
+
+func NewFleetView(hosts []FleetHost, current string) *FleetView {
+	columns := []table.Column{
+		{Title: "Host", Width: 20},
+		{Title: "Service", Width: 14},
+		{Title: "Runners", Width: 8},
+		{Title: "Running", Width: 8},
+		{Title: "CPU", Width: 8},
+		{Title: "Memory", Width: 10},
+		{Title: "Refreshed", Width: 10},
+	}
+
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithFocused(true),
+		table.WithHeight(10),
+	)
+
+	s := table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(ColorSecondary).
+		BorderBottom(true).
+		Bold(false)
+	s.Selected = s.Selected.
+		Foreground(ColorBg).
+		Background(ColorPrimary).
+		Bold(false)
+	t.SetStyles(s)
+
+	sp := spinner.New()
+	sp.Spinner = spinner.Dot
+	sp.Style = SpinnerStyle
+
+	return &FleetView{
+		table:   t,
+		hosts:   hosts,
+		timeout: fleetHostTimeout,
+		current: current,
+		spinner: sp,
+	}
+}
+
+func (v *FleetView) Init() tea.Cmd {
+	return tea.Batch(v.startPoll(), v.spinner.Tick)
+}
+
+// Activate resumes polling when the tab is shown again. Messages are only
+// delivered to the active tab, so ticks and poll results sent while the
+// fleet was hidden are lost.
+func (v *FleetView) Activate() tea.Cmd {
+	if v.polling && time.Since(v.pollStart) < v.timeout+time.Second {
+		return nil
+	}
+	if !v.polling && time.Since(v.lastPoll) < fleetPollInterval {
+		return nil
+	}
+	return v.Init()
+}
+
+func (v *FleetView) startPoll() tea.Cmd {
+	v.polling = true
+	v.pollStart = time.Now()
+	return v.poll
+}
+
+func (v *FleetView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+		v.table.SetHeight(v.height - 10)
+		return v, nil
+
+	case fleetLoadedMsg:
+		v.summaries = msg.summaries
+		v.polling = false
+		v.lastPoll = time.Now()
+		v.updateTable()
+		return v, tea.Tick(fleetPollInterval, func(t time.Time) tea.Msg {
+			return fleetTickMsg(t)
+		})
+
+	case fleetTickMsg:
+		if v.polling {
+			return v, nil
+		}
+		return v, v.startPoll()
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "r", "R":
+			if !v.polling {
+				return v, v.Init()
+			}
+			return v, nil
+		case "enter":
+			if i := v.table.Cursor(); i >= 0 && i < len(v.hosts) {
+				name := v.hosts[i].Name
+				v.current = name
+				v.updateTable()
+				return v, func() tea.Msg { return HostSelectedMsg{Name: name} }
+			}
+			return v, nil
+		}
+	}
+
+	var cmds []tea.Cmd
+	if v.polling {
+		var cmd tea.Cmd
+		v.spinner, cmd = v.spinner.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	var cmd tea.Cmd
+	v.table, cmd = v.table.Update(msg)
+	cmds = append(cmds, cmd)
+
+	return v, tea.Batch(cmds...)
+}
+
+func (v *FleetView) View() string {
+	content := []string{
+		HeaderStyle.Render("Fleet"),
+		"",
+	}
+
+	switch {
+	case len(v.hosts) == 0:
+		content = append(content, InfoBoxStyle.Render("No hosts configured"))
+	case v.summaries == nil:
+		content = append(content, v.spinner.View()+" Polling hosts...")
+	default:
+		content = append(content, v.table.View())
+		if v.polling {
+			content = append(content, v.spinner.View()+" Refreshing...")
+		}
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, content...)
+}
+
+func (v *FleetView) updateTable() {
+	rows := make([]table.Row, 0, len(v.summaries))
+	for i := range v.summaries {
+		s := &v.summaries[i]
+
+		name := s.Name
+		if name == v.current {
+			name = "▸ " + name
+		}
+
+		state, cpu, mem := "unreachable", "-", "-"
+		runners, running := "-", "-"
+		switch {
+		case s.Err != nil:
+			if errors.Is(s.Err, errHostTimeout) {
+				state = "timeout"
+			}
+		case s.Status != nil:
+			state = "inactive"
+			if s.Status.ServiceActive {
+				state = "active"
+			}
+			cpu = fmt.Sprintf("%.1f%%", s.Status.CPUUsage)
+			mem = fmt.Sprintf("%.0f MB", float64(s.Status.MemoryUsage)/1024/1024)
+			runners = fmt.Sprintf("%d", s.Runners)
+			running = fmt.Sprintf("%d", s.RunningJobs)
+		}
+
+		rows = append(rows, table.Row{
+			TruncateString(name, 20),
+			RenderStatus(state),
+			runners,
+			running,
+			cpu,
+			mem,
+			s.Refreshed.Format("15:04:05"),
+		})
+	}
+	v.table.SetRows(rows)
+}
+
+func (v *FleetView) poll() tea.Msg {
+	return fleetLoadedMsg{summaries: pollFleet(v.hosts, v.timeout)}
+}
+
+var errHostTimeout = errors.New("host did not answer in time")
+
+// pollFleet polls all hosts concurrently. A host that does not answer within
+// timeout is reported as timed out; its commands are left to finish on
+// their own.
+func pollFleet(hosts []FleetHost, timeout time.Duration) []HostSummary {
+	summaries := make([]HostSummary, len(hosts))
+
+	var wg sync.WaitGroup
+	for i := range hosts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			done := make(chan HostSummary, 1)
+			go func() {
+				done <- pollHost(hosts[i])
+			}()
+
+			select {
+			case summaries[i] = <-done:
+			case <-time.After(timeout):
+				summaries[i] = HostSummary{Name: hosts[i].Name, Err: errHostTimeout}
+			}
+			summaries[i].Refreshed = time.Now()
+		}(i)
+	}
+	wg.Wait()
+
+	return summaries
+}
+
+func pollHost(host FleetHost) HostSummary {
+	summary := HostSummary{Name: host.Name}
+
+	runners, err := host.Service.ListRunners()
+	if err != nil {
+		summary.Err = err
+		return summary
+	}
+	summary.Runners = len(runners)
+
+	status, err := host.Service.GetSystemStatus()
+	if err != nil {
+		summary.Err = err
+		return summary
+	}
+	summary.Status = status
+
+	if jobs, err := host.Service.GetJobHistory(50); err == nil {
+		for i := range jobs {
+			if jobs[i].Status == "running" {
+				summary.RunningJobs++
+			}
+		}
+	}
+
+	return summary
+}
+
+type fleetLoadedMsg struct {
+	summaries []HostSummary
+}
+
+type fleetTickMsg time.Time
@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+type fleetService struct {
+	runner.Service
+	delay   time.Duration
+	listErr error
+}
+
+func (s *fleetService) ListRunners() ([]runner.Runner, error) {
+	time.Sleep(s.delay)
+	if s.listErr != nil {
+		return nil, s.listErr
+	}
+	return []runner.Runner{{Name: "a"}, {Name: "b"}}, nil
+}
+
+func (s *fleetService) GetSystemStatus() (*runner.SystemStatus, error) {
+	return &runner.SystemStatus{ServiceActive: true, CPUUsage: 12.5, MemoryUsage: 256 * 1024 * 1024}, nil
+}
+
+func (s *fleetService) GetJobHistory(_ int) ([]runner.Job, error) {
+	return []runner.Job{{ID: 1, Status: "running"}, {ID: 2, Status: "success"}, {ID: 3, Status: "running"}}, nil
+}
+
+func TestPollFleet(t *testing.T) {
+	hosts := []FleetHost{
+		{Name: "fast", Service: &fleetService{}},
+		{Name: "down", Service: &fleetService{listErr: errors.New("ssh: connect to host down port 22: No route to host")}},
+		{Name: "slow", Service: &fleetService{delay: time.Second}},
+	}
+
+	start := time.Now()
+	summaries := pollFleet(hosts, 100*time.Millisecond)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("polling took %v, slow host was not cut off", elapsed)
+	}
+
+	if len(summaries) != 3 {
+		t.Fatalf("expected 3 summaries, got %d", len(summaries))
+	}
+
+	fast := summaries[0]
+	if fast.Err != nil || fast.Runners != 2 || fast.RunningJobs != 2 || !fast.Status.ServiceActive {
+		t.Errorf("unexpected summary for fast host: %+v", fast)
+	}
+	if summaries[1].Err == nil || summaries[1].Status != nil {
+		t.Errorf("expected error for unreachable host: %+v", summaries[1])
+	}
+	if !errors.Is(summaries[2].Err, errHostTimeout) {
+		t.Errorf("expected timeout for slow host: %+v", summaries[2])
+	}
+	for _, s := range summaries {
+		if s.Refreshed.IsZero() {
+			t.Errorf("host %s has no refresh time", s.Name)
+		}
+	}
+}
+
+func TestFleetView_SelectHost(t *testing.T) {
+	v := NewFleetView([]FleetHost{
+		{Name: "local", Service: &fleetService{}},
+		{Name: "ci-01", Service: &fleetService{}},
+	}, "local")
+
+	v.Update(fleetLoadedMsg{summaries: pollFleet(v.hosts, time.Second)})
+	if out := v.View(); !strings.Contains(out, "▸ local") || !strings.Contains(out, "ci-01") {
+		t.Errorf("unexpected fleet view:\n%s", out)
+	}
+
+	v.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, cmd := v.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected a command for Enter")
+	}
+	if msg, ok := cmd().(HostSelectedMsg); !ok || msg.Name != "ci-01" {
+		t.Errorf("unexpected message %#v", msg)
+	}
+}
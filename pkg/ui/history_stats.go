@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/jobstats"
+)
+
+// statsRows is the number of runners and projects listed in each table.
+const statsRows = 5
+
+var statsHeaderStyle = lipgloss.NewStyle().Bold(true).Foreground(ColorSecondary)
+
+// sparkBlocks are the bar heights of sparklines, lowest first.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values as bars scaled to peak, which is at least the
+// largest value. Zero is blank, so quiet periods stand out.
+func sparkline(values []int, peak int) string {
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		if v == 0 {
+			b.WriteRune(' ')
+			continue
+		}
+		b.WriteRune(sparkBlocks[(v*len(sparkBlocks)-1)/peak])
+	}
+	return b.String()
+}
+
+// renderJobStats renders a statistics summary as charts and tables.
+func renderJobStats(s jobstats.Summary) string {
+	muted := lipgloss.NewStyle().Foreground(ColorMuted)
+
+	if s.All.Total == 0 {
+		return InfoBoxStyle.Render(fmt.Sprintf("No jobs in the last %s", s.Window.Name))
+	}
+
+	overview := fmt.Sprintf("Jobs: %d • Success: %s • Failed: %d • p50: %s • p95: %s • %.1f jobs/hour",
+		s.All.Total, formatRate(s.All), s.All.Failed,
+		formatStatDuration(s.All.P50), formatStatDuration(s.All.P95), s.JobsPerHour)
+
+	axis := fmt.Sprintf("-%s%s", s.Window.Name, strings.Repeat(" ", max(len(s.Jobs)-len(s.Window.Name)-4, 1))) + "now"
+	// Failures share the scale of all jobs, so their bars compare
+	peak := 0
+	for _, n := range s.Jobs {
+		peak = max(peak, n)
+	}
+	charts := lipgloss.JoinVertical(lipgloss.Left,
+		"Jobs      "+StatusActiveStyle.Render(sparkline(s.Jobs, peak)),
+		"Failures  "+StatusInactiveStyle.Render(sparkline(s.Failures, peak)),
+		"          "+muted.Render(axis),
+	)
+
+	content := []string{
+		InfoBoxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, overview, "", charts)),
+		"",
+		statsTable("Runner", s.ByRunner),
+		"",
+		statsTable("Project", s.ByProject),
+	}
+	if len(s.TopFailing) > 0 {
+		content = append(content, "", failingTable(s.TopFailing))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, content...)
+}
+
+// statsTable lists the groups with the most jobs.
+func statsTable(title string, groups []jobstats.Group) string {
+	lines := []string{
+		statsHeaderStyle.Render(fmt.Sprintf("%-20s %6s %8s %7s %9s %9s", title, "Jobs", "Success", "Failed", "p50", "p95")),
+	}
+	for _, g := range groups[:min(len(groups), statsRows)] {
+		lines = append(lines, fmt.Sprintf("%-20s %6d %8s %7d %9s %9s",
+			TruncateString(g.Name, 20), g.Total, formatRate(g), g.Failed,
+			formatStatDuration(g.P50), formatStatDuration(g.P95)))
+	}
+	if len(groups) > statsRows {
+		lines = append(lines, lipgloss.NewStyle().Foreground(ColorMuted).Render(
+			fmt.Sprintf("… and %d more", len(groups)-statsRows)))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// failingTable lists the projects with the most failed jobs.
+func failingTable(groups []jobstats.Group) string {
+	lines := []string{
+		statsHeaderStyle.Render(fmt.Sprintf("%-20s %7s %6s %8s", "Top failing project", "Failed", "Jobs", "Success")),
+	}
+	for _, g := range groups[:min(len(groups), statsRows)] {
+		lines = append(lines, fmt.Sprintf("%-20s %s %6d %8s",
+			TruncateString(g.Name, 20), StatusInactiveStyle.Render(fmt.Sprintf("%7d", g.Failed)), g.Total, formatRate(g)))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// formatRate formats the success rate of a group, or "-" without finished
+// jobs.
+func formatRate(g jobstats.Group) string {
+	if g.Succeeded+g.Failed == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", g.SuccessRate()*100)
+}
+
+func formatStatDuration(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return formatJobDuration(d)
+}
@@ -2,13 +2,18 @@ package ui
 
 import (
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/export"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/jobstats"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/jobstore"
 	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
 )
 
@@ -21,8 +26,39 @@ type HistoryView struct {
 	loading bool
 	spinner spinner.Model
 	err     error
+	export  *exportPrompt
+	notice  string
+	detail  *jobDetail
+
+	// The jobs known are the recorded history with a store, else the
+	// service's recent jobs. jobs is the page of those that pass the filter
+	// and search, sorted; matched is how many pass and total how many are
+	// known.
+	store     *jobstore.Store
+	recent    []runner.Job
+	matched   int
+	total     int
+	page      int
+	ingestErr error
+
+	sort        jobSort
+	filter      jobFilter
+	filterInput textinput.Model
+	filtering   bool
+	filterErr   error
+	search      string
+	searchInput textinput.Model
+	searching   bool
+
+	// The statistics panel covers the shown jobs, not just the page
+	showStats bool
+	window    int
+	stats     jobstats.Summary
 }
 
+// historyPageSize is the number of jobs listed at a time.
+const historyPageSize = 50
+
 func NewHistoryView(service runner.Service) *HistoryView {
 	columns := []table.Column{
 		{Title: "Job ID", Width: 10},
@@ -56,17 +92,30 @@ func NewHistoryView(service runner.Service) *HistoryView {
 	sp.Spinner = spinner.Dot
 	sp.Style = SpinnerStyle
 
+	searchInput := textinput.New()
+	searchInput.Prompt = "/"
+	searchInput.Placeholder = "Search jobs"
+	searchInput.CharLimit = 256
+
+	filterInput := textinput.New()
+	filterInput.Prompt = ":"
+	filterInput.Placeholder = "status=failed runner=docker project=web since=24h"
+	filterInput.CharLimit = 256
+
 	return &HistoryView{
-		table:   t,
-		service: service,
-		spinner: sp,
-		loading: true,
+		table:       t,
+		service:     service,
+		spinner:     sp,
+		loading:     true,
+		sort:        defaultJobSort,
+		searchInput: searchInput,
+		filterInput: filterInput,
 	}
 }
 
 func (v *HistoryView) Init() tea.Cmd {
 	return tea.Batch(
-		v.loadHistory,
+		v.loadHistory(),
 		v.spinner.Tick,
 		tea.Tick(30*time.Second, func(t time.Time) tea.Msg {
 			return historyTickMsg(t)
@@ -82,27 +131,116 @@ func (v *HistoryView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		v.width = msg.Width
 		v.height = msg.Height
 		v.table.SetHeight(v.height - 10)
+		if v.detail != nil {
+			v.detail.setSize(v.width, v.height)
+		}
+		return v, nil
+
+	case jobLogsMsg:
+		if v.detail != nil && v.detail.job.ID == msg.id {
+			v.detail.setLogs(msg)
+		}
 		return v, nil
 
 	case historyLoadedMsg:
-		v.jobs = msg.jobs
+		// Jobs of a host that is no longer shown are dropped
+		if msg.service != v.service {
+			return v, nil
+		}
+		v.recent = msg.jobs
+		v.ingestErr = msg.ingestErr
 		v.loading = false
 		v.err = msg.err
-		v.updateTable()
+		v.applyFilter()
 		return v, nil
 
 	case historyTickMsg:
 		if !v.loading {
 			v.loading = true
-			return v, v.loadHistory
+			return v, v.loadHistory()
+		}
+		return v, nil
+
+	case exportedMsg:
+		v.notice = msg.notice
+		if msg.err != nil {
+			v.notice = fmt.Sprintf("Error: %v", msg.err)
 		}
 		return v, nil
 
 	case tea.KeyMsg:
+		if v.detail != nil {
+			return v, v.handleDetailKey(msg)
+		}
+		if v.export != nil {
+			return v, v.handleExportKey(msg)
+		}
+		if v.searching {
+			return v, v.handleSearchKey(msg)
+		}
+		if v.filtering {
+			return v, v.handleFilterKey(msg)
+		}
 		switch msg.String() {
 		case "r", "R":
 			v.loading = true
-			return v, v.loadHistory
+			return v, v.loadHistory()
+		case "enter":
+			cursor := v.table.Cursor()
+			if v.showStats || cursor < 0 || cursor >= len(v.jobs) {
+				return v, nil
+			}
+			v.detail = newJobDetail(v.jobs[cursor], v.width, v.height)
+			return v, loadJobLogs(v.service, v.detail.job.ID)
+		case "s", "S":
+			v.showStats = !v.showStats
+			v.updateStats()
+			return v, nil
+		case "w", "W":
+			v.window = (v.window + 1) % len(jobstats.Windows)
+			v.updateStats()
+			return v, nil
+		case "right":
+			if (v.page+1)*historyPageSize < v.matched {
+				v.page++
+				v.updatePage()
+			}
+			return v, nil
+		case "left":
+			if v.page > 0 {
+				v.page--
+				v.updatePage()
+			}
+			return v, nil
+		case "o":
+			v.sort = v.sort.next()
+			v.applyFilter()
+			return v, nil
+		case "O":
+			v.sort.desc = !v.sort.desc
+			v.applyFilter()
+			return v, nil
+		case "/":
+			v.searching = true
+			v.searchInput.SetValue(v.search)
+			v.searchInput.CursorEnd()
+			return v, v.searchInput.Focus()
+		case ":":
+			v.filtering = true
+			v.filterErr = nil
+			v.filterInput.SetValue(v.filter.String())
+			v.filterInput.CursorEnd()
+			return v, v.filterInput.Focus()
+		case "esc":
+			v.filter = jobFilter{}
+			v.search = ""
+			v.applyFilter()
+			return v, nil
+		case "e", "E":
+			var cmd tea.Cmd
+			v.export, cmd = newExportPrompt("job-history")
+			v.notice = ""
+			return v, cmd
 		}
 	}
 
@@ -120,6 +258,9 @@ func (v *HistoryView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (v *HistoryView) View() string {
+	if v.detail != nil {
+		return v.detail.View()
+	}
 	if v.err != nil {
 		return ErrorBoxStyle.Render(fmt.Sprintf("Error: %v", v.err))
 	}
@@ -127,27 +268,54 @@ func (v *HistoryView) View() string {
 	if v.loading {
 		return lipgloss.JoinVertical(
 			lipgloss.Left,
-			HeaderStyle.Render("Job History"),
+			v.header(),
 			"",
 			v.spinner.View()+" Loading job history...",
 		)
 	}
 
 	content := []string{
-		HeaderStyle.Render("Job History"),
-		"",
+		v.header(),
+	}
+	if bar := v.inputBar(); bar != "" {
+		content = append(content, bar)
 	}
+	content = append(content, "")
 
-	if len(v.jobs) == 0 {
-		content = append(content, InfoBoxStyle.Render("No job history available"))
+	if v.showStats {
+		content = append(content, v.statsView())
+	} else if len(v.jobs) == 0 {
+		message := "No job history available"
+		if v.total > 0 {
+			message = "No jobs match the filter and search"
+		}
+		content = append(content, InfoBoxStyle.Render(message))
 	} else {
+		noun := "recent jobs"
+		if v.store != nil {
+			noun = "recorded jobs"
+		}
+		footer := fmt.Sprintf("%d %s", v.total, noun)
+		if v.matched != v.total {
+			footer = fmt.Sprintf("%d of %d %s match", v.matched, v.total, noun)
+		}
+		if pages := (v.matched + historyPageSize - 1) / historyPageSize; pages > 1 {
+			footer = fmt.Sprintf("Page %d of %d • %s", v.page+1, pages, footer)
+		}
+		if v.ingestErr != nil {
+			footer += fmt.Sprintf(" • Not updated: %v", v.ingestErr)
+		}
 		content = append(content, v.table.View(), "",
-			lipgloss.NewStyle().Foreground(ColorMuted).Render(
-				fmt.Sprintf("Showing %d recent jobs", len(v.jobs)),
-			),
+			lipgloss.NewStyle().Foreground(ColorMuted).Render(footer),
 		)
 	}
 
+	if v.export != nil {
+		content = append(content, "", v.export.View())
+	} else if v.notice != "" {
+		content = append(content, "", v.notice)
+	}
+
 	// Help is now shown in the status bar
 
 	return lipgloss.JoinVertical(lipgloss.Left, content...)
@@ -202,12 +370,230 @@ func (v *HistoryView) updateTable() {
 	v.table.SetRows(rows)
 }
 
-func (v *HistoryView) loadHistory() tea.Msg {
-	jobs, err := v.service.GetJobHistory(50)
-	if err != nil {
-		return historyLoadedMsg{err: err}
+// applyFilter lists the first page of the shown jobs after the jobs,
+// filter, search or sort changed.
+func (v *HistoryView) applyFilter() {
+	v.page = 0
+	v.updatePage()
+	v.updateStats()
+}
+
+// updatePage lists the current page of the shown jobs.
+func (v *HistoryView) updatePage() {
+	v.jobs, v.matched, v.total = v.query(v.page*historyPageSize, historyPageSize)
+	v.updateTable()
+	v.table.GotoTop()
+}
+
+// query returns the jobs that pass the filter and search, sorted, from
+// offset on (all of them with a limit of 0), how many pass and how many
+// are known. With a store, only the jobs asked for are copied out of it.
+func (v *HistoryView) query(offset, limit int) ([]runner.Job, int, int) {
+	search := strings.ToLower(v.search)
+	q := jobstore.Query{
+		Match: func(job runner.Job) bool {
+			return v.filter.matches(job) && searchJob(job, search)
+		},
+		Sort:   v.sort.apply,
+		Offset: offset,
+		Limit:  limit,
+	}
+	if v.store != nil {
+		return v.store.Query(q)
+	}
+	jobs, matched := q.Select(v.recent)
+	return jobs, matched, len(v.recent)
+}
+
+// updateStats recomputes the statistics panel for the selected window.
+func (v *HistoryView) updateStats() {
+	if v.showStats {
+		jobs, _, _ := v.query(0, 0)
+		v.stats = jobstats.Compute(jobs, jobstats.Windows[v.window], time.Now())
+	}
+}
+
+// header shows the title with the sort, filter and search in use.
+func (v *HistoryView) header() string {
+	parts := []string{"Job History"}
+	if v.sort != defaultJobSort {
+		parts = append(parts, "sorted by "+v.sort.String())
+	}
+	if v.filter.active() {
+		parts = append(parts, v.filter.String())
+	}
+	if v.search != "" {
+		parts = append(parts, fmt.Sprintf("search %q", v.search))
+	}
+	return HeaderStyle.Render(strings.Join(parts, " • "))
+}
+
+// inputBar shows the search or filter bar being edited.
+func (v *HistoryView) inputBar() string {
+	switch {
+	case v.searching:
+		return FocusedInputStyle.Render(v.searchInput.View())
+	case v.filtering:
+		bar := FocusedInputStyle.Render(v.filterInput.View())
+		if v.filterErr != nil {
+			bar = lipgloss.JoinHorizontal(lipgloss.Top, bar, "  ",
+				lipgloss.NewStyle().Foreground(ColorError).Render(v.filterErr.Error()))
+		}
+		return bar
+	}
+	return ""
+}
+
+// handleSearchKey edits the search bar. The search is applied as the query
+// is typed; Enter keeps it, Esc drops it.
+func (v *HistoryView) handleSearchKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		v.searching = false
+		v.searchInput.Blur()
+		return nil
+	case "esc":
+		v.searching = false
+		v.searchInput.Blur()
+		v.search = ""
+		v.applyFilter()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	v.searchInput, cmd = v.searchInput.Update(msg)
+	if query := v.searchInput.Value(); query != v.search {
+		v.search = query
+		v.applyFilter()
+	}
+	return cmd
+}
+
+// handleFilterKey edits the filter bar. Like the search, the filter is
+// applied as it is typed; terms that do not parse keep the last filter.
+func (v *HistoryView) handleFilterKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		v.filtering = false
+		v.filterErr = nil
+		v.filterInput.Blur()
+		return nil
+	case "esc":
+		v.filtering = false
+		v.filterErr = nil
+		v.filterInput.Blur()
+		v.filter = jobFilter{}
+		v.applyFilter()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	v.filterInput, cmd = v.filterInput.Update(msg)
+	filter, err := parseJobFilter(v.filterInput.Value(), time.Now())
+	v.filterErr = err
+	if err == nil && filter.String() != v.filter.String() {
+		v.filter = filter
+		v.applyFilter()
+	}
+	return cmd
+}
+
+func (v *HistoryView) statsView() string {
+	windows := []string{"Window:"}
+	for i, w := range jobstats.Windows {
+		if i == v.window {
+			windows = append(windows, StatusActiveStyle.Render("["+w.Name+"]"))
+		} else {
+			windows = append(windows, lipgloss.NewStyle().Foreground(ColorMuted).Render(w.Name))
+		}
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		strings.Join(windows, " "),
+		"",
+		renderJobStats(v.stats),
+	)
+}
+
+// Capturing reports whether the job detail, export prompt, search or
+// filter bar has the keyboard, so global key bindings must not be applied.
+func (v *HistoryView) Capturing() bool {
+	return v.detail != nil || v.export != nil || v.searching || v.filtering
+}
+
+// handleDetailKey scrolls the job detail, reloads its log lines with r and
+// closes it with Esc.
+func (v *HistoryView) handleDetailKey(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() == "r" || msg.String() == "R" {
+		v.detail.loading = true
+		return loadJobLogs(v.service, v.detail.job.ID)
+	}
+	open, cmd := v.detail.handleKey(msg)
+	if !open {
+		v.detail = nil
+	}
+	return cmd
+}
+
+// handleExportKey edits the export prompt and, once a path is entered,
+// writes the shown jobs, on every page, to it.
+func (v *HistoryView) handleExportKey(msg tea.KeyMsg) tea.Cmd {
+	done, path, cmd := v.export.handleKey(msg)
+	if !done {
+		return cmd
+	}
+	overwrite := v.export.overwrite
+	v.export = nil
+	if path == "" {
+		return nil
+	}
+
+	jobs, _, _ := v.query(0, 0)
+	return exportCmd(path, count(len(jobs), "job"), overwrite, func(w io.Writer, format export.Format) error {
+		return export.WriteJobs(w, format, jobs)
+	})
+}
+
+// SetService points the view at another host. Jobs loaded from the
+// previous host are dropped.
+func (v *HistoryView) SetService(service runner.Service) {
+	v.service = service
+	v.jobs = nil
+	v.recent = nil
+	v.matched = 0
+	v.total = 0
+	v.detail = nil
+	v.err = nil
+	v.page = 0
+	v.loading = true
+	v.updateTable()
+}
+
+// SetStore keeps the history of the host's jobs in store, which the view
+// updates from the service and lists. A nil store shows the service's
+// recent jobs only.
+func (v *HistoryView) SetStore(store *jobstore.Store) {
+	v.store = store
+	v.page = 0
+}
+
+func (v *HistoryView) loadHistory() tea.Cmd {
+	service, store := v.service, v.store
+	return func() tea.Msg {
+		if store == nil {
+			jobs, err := service.GetJobHistory(historyPageSize)
+			if err != nil {
+				return historyLoadedMsg{service: service, err: err}
+			}
+			return historyLoadedMsg{service: service, jobs: jobs}
+		}
+
+		// Recorded jobs are still shown when the host cannot be reached
+		ingestErr := store.Ingest(service)
+		if ingestErr != nil && store.Len() == 0 {
+			return historyLoadedMsg{service: service, err: ingestErr}
+		}
+		return historyLoadedMsg{service: service, ingestErr: ingestErr}
 	}
-	return historyLoadedMsg{jobs: jobs}
 }
 
 func formatJobDuration(d time.Duration) string {
@@ -220,8 +606,10 @@ func formatJobDuration(d time.Duration) string {
 }
 
 type historyLoadedMsg struct {
-	jobs []runner.Job
-	err  error
+	service   runner.Service
+	jobs      []runner.Job // without a store
+	ingestErr error
+	err       error
 }
 
 type historyTickMsg time.Time
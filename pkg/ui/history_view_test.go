@@ -0,0 +1,346 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/jobstore"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+// historyService serves a fixed job history and job logs.
+type historyService struct {
+	runner.Service
+	jobs []runner.Job
+	logs map[int][]string
+}
+
+func (s *historyService) GetJobHistory(_ int) ([]runner.Job, error) {
+	return s.jobs, nil
+}
+
+func (s *historyService) GetJobLogs(id int) ([]string, error) {
+	return s.logs[id], nil
+}
+
+func TestHistoryView_Export(t *testing.T) {
+	v := NewHistoryView(&historyService{jobs: []runner.Job{
+		{ID: 1001, Status: "success", RunnerName: "docker-1"},
+		{ID: 1002, Status: "failed", RunnerName: "shell-1", ExitCode: 1},
+	}})
+	v.Update(v.loadHistory()())
+
+	v.Update(key("e"))
+	if !v.Capturing() {
+		t.Fatal("expected the export prompt")
+	}
+	v.Update(key("esc"))
+	if v.Capturing() {
+		t.Fatal("expected Esc to cancel the export")
+	}
+
+	path := filepath.Join(t.TempDir(), "jobs.csv")
+	v.Update(key("e"))
+	v.export.input.SetValue(path)
+	_, cmd := v.Update(key("enter"))
+	v.Update(cmd())
+	if !strings.Contains(v.View(), "Exported 2 jobs to "+path) {
+		t.Errorf("expected a notice:\n%s", v.View())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 3 || !strings.HasPrefix(lines[1], "1002,") {
+		t.Errorf("unexpected CSV export %q", data)
+	}
+
+	// Exporting to the same file asks before replacing it
+	v.Update(key("/"))
+	for _, r := range "docker" {
+		v.Update(key(string(r)))
+	}
+	v.Update(key("enter"))
+	v.Update(key("e"))
+	v.export.input.SetValue(path)
+	if _, cmd := v.Update(key("enter")); cmd != nil || !strings.Contains(v.View(), "The file exists") {
+		t.Fatalf("expected to be asked before overwriting:\n%s", v.View())
+	}
+	_, cmd = v.Update(key("enter"))
+	v.Update(cmd())
+	if !strings.Contains(v.View(), "Exported 1 job to "+path) {
+		t.Errorf("expected a notice:\n%s", v.View())
+	}
+	if data, _ := os.ReadFile(path); strings.Count(string(data), "\n") != 2 {
+		t.Errorf("expected the file to be replaced, got %q", data)
+	}
+}
+
+func TestHistoryView_ExportError(t *testing.T) {
+	v := NewHistoryView(&historyService{})
+	v.Update(v.loadHistory()())
+
+	// A file cannot be created below a regular file
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	v.Update(key("e"))
+	v.export.input.SetValue(filepath.Join(file, "jobs.log"))
+	_, cmd := v.Update(key("enter"))
+	v.Update(cmd())
+	if !strings.Contains(v.notice, "Error:") {
+		t.Errorf("expected an error notice, got %q", v.notice)
+	}
+}
+
+func TestHistoryView_Pages(t *testing.T) {
+	store, err := jobstore.Open(filepath.Join(t.TempDir(), "local.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var older []runner.Job
+	for id := 1; id <= historyPageSize; id++ {
+		older = append(older, runner.Job{ID: id, Status: "success"})
+	}
+	if _, err := store.Add(older); err != nil {
+		t.Fatal(err)
+	}
+
+	service := &historyService{jobs: []runner.Job{{ID: 1001, Status: "running"}}}
+	v := NewHistoryView(service)
+	v.SetStore(store)
+	v.Update(v.loadHistory()())
+	if len(v.jobs) != historyPageSize || v.jobs[0].ID != 1001 {
+		t.Fatalf("expected the newest job first on a full page, got %d jobs", len(v.jobs))
+	}
+	if !strings.Contains(v.View(), "Page 1 of 2 • 51 recorded jobs") {
+		t.Errorf("expected the page in the footer:\n%s", v.View())
+	}
+
+	v.Update(key("right"))
+	if len(v.jobs) != 1 || v.jobs[0].ID != 1 {
+		t.Errorf("expected the oldest job on page 2, got %+v", v.jobs)
+	}
+	v.Update(key("right"))
+	if v.page != 1 {
+		t.Error("expected no page after the last")
+	}
+
+	// Recorded jobs stay listed when the host cannot be reached
+	v.SetService(&failingHistoryService{})
+	v.Update(v.loadHistory()())
+	if v.err != nil || len(v.jobs) != historyPageSize {
+		t.Errorf("expected the recorded jobs, got %d jobs and %v", len(v.jobs), v.err)
+	}
+	if !strings.Contains(v.View(), "Not updated: connection refused") {
+		t.Errorf("expected the ingest error in the footer:\n%s", v.View())
+	}
+}
+
+// failingHistoryService cannot reach its host.
+type failingHistoryService struct {
+	runner.Service
+}
+
+func (s *failingHistoryService) GetJobHistory(_ int) ([]runner.Job, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestHistoryView_Stats(t *testing.T) {
+	now := time.Now()
+	v := NewHistoryView(&historyService{jobs: []runner.Job{
+		{ID: 1001, Status: "success", Project: "web/app", RunnerName: "docker-1", Started: now.Add(-10 * time.Minute), Duration: time.Minute},
+		{ID: 1002, Status: "failed", Project: "web/app", RunnerName: "docker-1", Started: now.Add(-3 * time.Hour), Duration: 3 * time.Minute},
+	}})
+	v.Update(v.loadHistory()())
+
+	v.Update(key("s"))
+	view := v.View()
+	for _, expected := range []string{"Jobs: 1 ", "Success: 100.0%", "docker-1"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("expected %q in the last hour:\n%s", expected, view)
+		}
+	}
+
+	v.Update(key("w"))
+	view = v.View()
+	for _, expected := range []string{"Jobs: 2 ", "Success: 50.0%", "Top failing project", "p95: 3m 0s"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("expected %q in the last 24 hours:\n%s", expected, view)
+		}
+	}
+
+	v.Update(key("s"))
+	if !strings.Contains(v.View(), "#1002") {
+		t.Errorf("expected the job table back:\n%s", v.View())
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]int{0, 1, 4, 8}, 0); got != " ▁▄█" {
+		t.Errorf("sparkline = %q", got)
+	}
+	if got := sparkline([]int{0, 0}, 0); got != "  " {
+		t.Errorf("sparkline of nothing = %q", got)
+	}
+	if got := sparkline([]int{1, 4}, 8); got != "▁▄" {
+		t.Errorf("sparkline scaled to 8 = %q", got)
+	}
+}
+
+func TestHistoryView_SortFilterSearch(t *testing.T) {
+	now := time.Now()
+	v := NewHistoryView(&historyService{jobs: []runner.Job{
+		{ID: 1001, Status: "success", RunnerName: "docker-1", Project: "web/app", Started: now.Add(-3 * time.Hour), Duration: 5 * time.Minute},
+		{ID: 1002, Status: "failed", RunnerName: "shell-1", Project: "api", Started: now.Add(-2 * time.Hour), Duration: time.Minute},
+		{ID: 1003, Status: "failed", RunnerName: "docker-2", Project: "web/docs", Started: now.Add(-48 * time.Hour), Duration: 2 * time.Minute},
+	}})
+	v.Update(v.loadHistory()())
+
+	ids := func() []int {
+		var ids []int
+		for _, job := range v.jobs {
+			ids = append(ids, job.ID)
+		}
+		return ids
+	}
+	expect := func(what string, expected ...int) {
+		t.Helper()
+		if got := ids(); fmt.Sprint(got) != fmt.Sprint(expected) {
+			t.Errorf("%s: got jobs %v, expected %v", what, got, expected)
+		}
+	}
+	expect("newest first", 1003, 1002, 1001)
+
+	// o cycles id, status, runner, project, started, duration
+	for range 5 {
+		v.Update(key("o"))
+	}
+	expect("longest first", 1001, 1003, 1002)
+	v.Update(key("O"))
+	expect("shortest first", 1002, 1003, 1001)
+	if !strings.Contains(v.View(), "sorted by duration ↑") {
+		t.Errorf("expected the sort in the header:\n%s", v.View())
+	}
+
+	v.Update(key(":"))
+	for _, r := range "status=failed since=1d" {
+		v.Update(key(string(r)))
+	}
+	v.Update(key("enter"))
+	expect("failed in the last day", 1002)
+	if !strings.Contains(v.View(), "status=failed since=1d") {
+		t.Errorf("expected the filter in the header:\n%s", v.View())
+	}
+
+	v.Update(key("esc"))
+	v.Update(key("/"))
+	for _, r := range "WEB" {
+		v.Update(key(string(r)))
+	}
+	if !v.Capturing() {
+		t.Error("expected the search bar to have the keyboard")
+	}
+	v.Update(key("enter"))
+	expect("search", 1003, 1001)
+	if !strings.Contains(v.View(), `search "WEB"`) {
+		t.Errorf("expected the search in the header:\n%s", v.View())
+	}
+
+	v.Update(key("esc"))
+	expect("cleared", 1002, 1003, 1001)
+}
+
+func TestParseJobFilter(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		input   string
+		since   time.Time
+		until   time.Time
+		wantErr bool
+	}{
+		{input: "status=failed runner=docker project=web/app"},
+		{input: "since=24h", since: now.Add(-24 * time.Hour)},
+		{input: "since=7d", since: now.AddDate(0, 0, -7)},
+		{
+			input: "since=2024-01-02 until=2024-01-03",
+			since: time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local),
+			until: time.Date(2024, 1, 4, 0, 0, 0, 0, time.Local),
+		},
+		{input: "until=2024-01-03T15:04", until: time.Date(2024, 1, 3, 15, 4, 0, 0, time.Local)},
+		{input: "since=yesterday", wantErr: true},
+		{input: "exit=1", wantErr: true},
+		{input: "failed", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			f, err := parseJobFilter(tt.input, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseJobFilter(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !f.since.Equal(tt.since) || !f.until.Equal(tt.until) {
+				t.Errorf("since/until = %v/%v, expected %v/%v", f.since, f.until, tt.since, tt.until)
+			}
+			if f.String() != tt.input {
+				t.Errorf("String() = %q, expected %q", f.String(), tt.input)
+			}
+		})
+	}
+}
+
+func TestHistoryView_Detail(t *testing.T) {
+	v := NewHistoryView(&historyService{
+		jobs: []runner.Job{
+			{ID: 1001, Status: "success", RunnerName: "docker-1"},
+			{ID: 1002, Status: "failed", Stage: "test", Pipeline: 77, RunnerName: "shell-1", RunnerID: "42",
+				ExitCode: 1, URL: "https://gitlab.example.com/web/app/-/jobs/1002"},
+		},
+		logs: map[int][]string{1002: {
+			`Checking for jobs... received                       job=1002 runner=bbbbbbbb`,
+			`WARNING: Job failed: exit code 1                    duration_s=40.1 job=1002 runner=bbbbbbbb`,
+		}},
+	})
+	v.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	v.Update(v.loadHistory()())
+
+	_, cmd := v.Update(key("enter"))
+	if !v.Capturing() || cmd == nil {
+		t.Fatal("expected Enter to open the job detail and load its logs")
+	}
+	v.Update(cmd())
+	view := v.View()
+	for _, expected := range []string{"Job #1002", "Stage:", "test", "#77", "shell-1 (ID 42)", "Exit code: 1", "/-/jobs/1002", "Log lines: 2", "Job failed: exit code 1"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("expected %q in the detail:\n%s", expected, view)
+		}
+	}
+
+	v.Update(key("esc"))
+	if v.Capturing() || !strings.Contains(v.View(), "#1001") {
+		t.Errorf("expected Esc to return to the table:\n%s", v.View())
+	}
+}
+
+func TestHistoryView_DropsJobsOfPreviousHost(t *testing.T) {
+	v := NewHistoryView(&historyService{jobs: []runner.Job{{ID: 1001, Status: "success"}}})
+	stale := v.loadHistory()
+
+	// The host changes while its jobs are still being read
+	v.SetService(&failingHistoryService{})
+	v.Update(stale())
+	if !v.loading || len(v.recent) != 0 || v.err != nil {
+		t.Errorf("expected the jobs of the previous host to be dropped, got %+v", v.recent)
+	}
+}
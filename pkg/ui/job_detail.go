@@ -0,0 +1,161 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+// jobDetail shows every field of a job and the log lines gitlab-runner
+// wrote for it.
+type jobDetail struct {
+	job      runner.Job
+	entries  []runner.LogEntry
+	viewport viewport.Model
+	loading  bool
+	err      error
+}
+
+func newJobDetail(job runner.Job, width, height int) *jobDetail {
+	d := &jobDetail{job: job, loading: true, viewport: viewport.New(80, 10)}
+	d.setSize(width, height)
+	return d
+}
+
+// setSize fits the log lines below the job fields.
+func (d *jobDetail) setSize(width, height int) {
+	d.viewport.Width = max(width-2, 20)
+	d.viewport.Height = max(height-len(d.fields())-12, 3)
+}
+
+// loadJobLogs reads the log lines of a job from service.
+func loadJobLogs(service runner.Service, id int) tea.Cmd {
+	return func() tea.Msg {
+		lines, err := service.GetJobLogs(id)
+		return jobLogsMsg{id: id, lines: lines, err: err}
+	}
+}
+
+func (d *jobDetail) setLogs(msg jobLogsMsg) {
+	d.loading = false
+	d.err = msg.err
+	d.entries = d.entries[:0]
+	var content []string
+	for _, line := range msg.lines {
+		entry := runner.ParseLogLine(line)
+		d.entries = append(d.entries, entry)
+		line = entry.Raw
+		if paint := levelPaint(entry.Level); paint != nil {
+			line = paint(line)
+		}
+		content = append(content, line)
+	}
+	d.viewport.SetContent(strings.Join(content, "\n"))
+	d.viewport.GotoTop()
+}
+
+// fields are the job's fields as label and value, "-" when unknown.
+func (d *jobDetail) fields() [][2]string {
+	job := d.job
+	runnerName := dash(job.RunnerName)
+	if job.RunnerID != "" {
+		runnerName += fmt.Sprintf(" (ID %s)", job.RunnerID)
+	}
+	started, finished := "-", "-"
+	if !job.Started.IsZero() {
+		started = job.Started.Format("2006-01-02 15:04:05 MST")
+	}
+	if !job.Finished.IsZero() {
+		finished = job.Finished.Format("2006-01-02 15:04:05 MST")
+	}
+	duration := "-"
+	if job.Duration > 0 {
+		duration = formatJobDuration(job.Duration)
+	}
+	pipeline, exitCode := "-", "-"
+	if job.Pipeline != 0 {
+		pipeline = fmt.Sprintf("#%d", job.Pipeline)
+	}
+	if job.ExitCode != 0 {
+		exitCode = fmt.Sprintf("%d", job.ExitCode)
+	}
+
+	return [][2]string{
+		{"Name", dash(job.Name)},
+		{"Status", dash(job.Status)},
+		{"Stage", dash(job.Stage)},
+		{"Project", dash(job.Project)},
+		{"Pipeline", pipeline},
+		{"Runner", runnerName},
+		{"Started", started},
+		{"Finished", finished},
+		{"Duration", duration},
+		{"Exit code", exitCode},
+		{"URL", dash(job.URL)},
+	}
+}
+
+func (d *jobDetail) View() string {
+	var fields []string
+	for _, field := range d.fields() {
+		fields = append(fields, fmt.Sprintf("%-10s %s", field[0]+":", field[1]))
+	}
+
+	content := []string{
+		HeaderStyle.Render(fmt.Sprintf("Job #%d", d.job.ID)),
+		"",
+		InfoBoxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, fields...)),
+		"",
+	}
+
+	switch {
+	case d.loading:
+		content = append(content, "Loading log lines...")
+	case d.err != nil:
+		content = append(content, ErrorBoxStyle.Render(fmt.Sprintf("Error: %v", d.err)))
+	case len(d.entries) == 0:
+		content = append(content, lipgloss.NewStyle().Foreground(ColorMuted).Render(
+			"No log lines of this job in the journal; they may have been rotated out"))
+	default:
+		content = append(content,
+			fmt.Sprintf("Log lines: %d | Position: %d%%", len(d.entries), int(d.viewport.ScrollPercent()*100)),
+			LogStyle.Render(d.viewport.View()),
+		)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, content...)
+}
+
+// handleKey scrolls the log lines. It returns false when the key closes
+// the detail.
+func (d *jobDetail) handleKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "backspace":
+		return false, nil
+	case "g":
+		d.viewport.GotoTop()
+		return true, nil
+	case "G":
+		d.viewport.GotoBottom()
+		return true, nil
+	}
+	var cmd tea.Cmd
+	d.viewport, cmd = d.viewport.Update(msg)
+	return true, cmd
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+type jobLogsMsg struct {
+	id    int
+	lines []string
+	err   error
+}
@@ -0,0 +1,213 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+// jobFilter selects jobs, written in the filter bar as key=value terms:
+// status= matches a status, runner= and project= part of the name, and
+// since= and until= bound the start time with a date (2024-01-02), a time
+// (2024-01-02T15:04) or an age (30m, 24h, 7d).
+type jobFilter struct {
+	status  string
+	runner  string
+	project string
+	since   time.Time
+	until   time.Time
+
+	// The time terms as written, which are kept for editing
+	sinceTerm string
+	untilTerm string
+}
+
+func parseJobFilter(s string, now time.Time) (jobFilter, error) {
+	var f jobFilter
+	for _, term := range strings.Fields(s) {
+		key, value, ok := strings.Cut(term, "=")
+		if !ok || key == "" || value == "" {
+			return jobFilter{}, fmt.Errorf("expected key=value, got %q", term)
+		}
+		switch key {
+		case "status":
+			f.status = strings.ToLower(value)
+		case "runner":
+			f.runner = strings.ToLower(value)
+		case "project":
+			f.project = strings.ToLower(value)
+		case "since":
+			t, err := parseFilterTime(value, now, false)
+			if err != nil {
+				return jobFilter{}, err
+			}
+			f.since, f.sinceTerm = t, value
+		case "until":
+			t, err := parseFilterTime(value, now, true)
+			if err != nil {
+				return jobFilter{}, err
+			}
+			f.until, f.untilTerm = t, value
+		default:
+			return jobFilter{}, fmt.Errorf("unknown filter %q, expected status, runner, project, since or until", key)
+		}
+	}
+	return f, nil
+}
+
+// parseFilterTime reads a date, a local time or an age back from now. A
+// date as the end of a range includes that whole day.
+func parseFilterTime(value string, now time.Time, end bool) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04", "2006-01-02T15:04:05", time.RFC3339} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("expected a date, time or age such as 24h or 7d, got %q", value)
+}
+
+func (f jobFilter) active() bool {
+	return f.status != "" || f.runner != "" || f.project != "" || !f.since.IsZero() || !f.until.IsZero()
+}
+
+func (f jobFilter) matches(job runner.Job) bool {
+	if f.status != "" && strings.ToLower(job.Status) != f.status {
+		return false
+	}
+	if f.runner != "" && !strings.Contains(strings.ToLower(job.RunnerName), f.runner) {
+		return false
+	}
+	if f.project != "" && !strings.Contains(strings.ToLower(job.Project), f.project) {
+		return false
+	}
+	if !f.since.IsZero() || !f.until.IsZero() {
+		at := jobTime(job)
+		if at.IsZero() || (!f.since.IsZero() && at.Before(f.since)) || (!f.until.IsZero() && !at.Before(f.until)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f jobFilter) String() string {
+	var terms []string
+	for _, term := range [][2]string{
+		{"status", f.status},
+		{"runner", f.runner},
+		{"project", f.project},
+		{"since", f.sinceTerm},
+		{"until", f.untilTerm},
+	} {
+		if term[1] != "" {
+			terms = append(terms, term[0]+"="+term[1])
+		}
+	}
+	return strings.Join(terms, " ")
+}
+
+// jobTime is when a job started, or finished when its start is unknown.
+func jobTime(job runner.Job) time.Time {
+	if job.Started.IsZero() {
+		return job.Finished
+	}
+	return job.Started
+}
+
+// searchJob reports whether any column of a job, or its name, stage,
+// pipeline or URL, contains query, regardless of case. The query is lower
+// case.
+func searchJob(job runner.Job, query string) bool {
+	if query == "" {
+		return true
+	}
+	for _, field := range []string{
+		fmt.Sprintf("#%d", job.ID),
+		job.Name,
+		job.Status,
+		job.Stage,
+		job.Project,
+		strconv.Itoa(job.Pipeline),
+		job.RunnerName,
+		job.RunnerID,
+		job.URL,
+	} {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// jobColumns are the columns the History table sorts by, in the order the
+// sort key cycles through them.
+var jobColumns = []struct {
+	name string
+	less func(a, b runner.Job) bool
+	desc bool // sorted largest first by default
+}{
+	{"id", func(a, b runner.Job) bool { return a.ID < b.ID }, true},
+	{"status", func(a, b runner.Job) bool { return strings.ToLower(a.Status) < strings.ToLower(b.Status) }, false},
+	{"runner", func(a, b runner.Job) bool { return strings.ToLower(a.RunnerName) < strings.ToLower(b.RunnerName) }, false},
+	{"project", func(a, b runner.Job) bool { return strings.ToLower(a.Project) < strings.ToLower(b.Project) }, false},
+	{"started", func(a, b runner.Job) bool { return jobTime(a).Before(jobTime(b)) }, true},
+	{"duration", func(a, b runner.Job) bool { return a.Duration < b.Duration }, true},
+}
+
+// jobSort is the column the History table is sorted by and its direction.
+type jobSort struct {
+	column int
+	desc   bool
+}
+
+// defaultJobSort lists the newest jobs first.
+var defaultJobSort = jobSort{column: 0, desc: true}
+
+// next moves to the next column in its default direction.
+func (s jobSort) next() jobSort {
+	column := (s.column + 1) % len(jobColumns)
+	return jobSort{column: column, desc: jobColumns[column].desc}
+}
+
+// apply sorts jobs in place. Equal jobs stay newest first.
+func (s jobSort) apply(jobs []runner.Job) {
+	less := jobColumns[s.column].less
+	sort.SliceStable(jobs, func(i, j int) bool {
+		a, b := jobs[i], jobs[j]
+		if s.desc {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return jobs[i].ID > jobs[j].ID
+	})
+}
+
+func (s jobSort) String() string {
+	arrow := "↑"
+	if s.desc {
+		arrow = "↓"
+	}
+	return jobColumns[s.column].name + " " + arrow
+}
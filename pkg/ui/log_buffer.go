@@ -0,0 +1,55 @@
+package ui
+
+import "github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+
+// logBuffer keeps the most recent lines of a log, parsed once as they are
+// appended. Once full, appending a line drops the oldest one.
+type logBuffer struct {
+	entries []runner.LogEntry
+	start   int
+	size    int
+}
+
+func newLogBuffer(capacity int) *logBuffer {
+	return &logBuffer{entries: make([]runner.LogEntry, capacity)}
+}
+
+func (b *logBuffer) Append(lines ...string) {
+	for _, line := range lines {
+		end := (b.start + b.size) % len(b.entries)
+		b.entries[end] = runner.ParseLogLine(line)
+		if b.size < len(b.entries) {
+			b.size++
+		} else {
+			b.start = (b.start + 1) % len(b.entries)
+		}
+	}
+}
+
+// Entries returns the buffered entries, oldest first.
+func (b *logBuffer) Entries() []runner.LogEntry {
+	entries := make([]runner.LogEntry, 0, b.size)
+	for i := 0; i < b.size; i++ {
+		entries = append(entries, b.entries[(b.start+i)%len(b.entries)])
+	}
+	return entries
+}
+
+// Lines returns the buffered lines, oldest first.
+func (b *logBuffer) Lines() []string {
+	lines := make([]string, 0, b.size)
+	for i := 0; i < b.size; i++ {
+		lines = append(lines, b.entries[(b.start+i)%len(b.entries)].Raw)
+	}
+	return lines
+}
+
+func (b *logBuffer) Len() int {
+	return b.size
+}
+
+func (b *logBuffer) Reset() {
+	clear(b.entries)
+	b.start = 0
+	b.size = 0
+}
@@ -0,0 +1,25 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLogBuffer(t *testing.T) {
+	b := newLogBuffer(3)
+	b.Append("1", "2")
+	if got := b.Lines(); !reflect.DeepEqual(got, []string{"1", "2"}) {
+		t.Errorf("Lines() = %q", got)
+	}
+
+	b.Append("3", "4", "5")
+	if got := b.Lines(); !reflect.DeepEqual(got, []string{"3", "4", "5"}) || b.Len() != 3 {
+		t.Errorf("expected the oldest lines to be dropped, got %q", got)
+	}
+
+	b.Reset()
+	b.Append("6")
+	if got := b.Lines(); !reflect.DeepEqual(got, []string{"6"}) {
+		t.Errorf("Lines() after Reset = %q", got)
+	}
+}
@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+// logFilter selects log entries by their fields, written in the filter bar
+// as key=value terms: level=warning keeps warnings and more severe entries,
+// runner= matches a runner token prefix and any other key its value
+// exactly.
+type logFilter struct {
+	level  string
+	runner string
+	fields map[string]string
+}
+
+func parseLogFilter(s string) (logFilter, error) {
+	f := logFilter{fields: map[string]string{}}
+	for _, term := range strings.Fields(s) {
+		key, value, ok := strings.Cut(term, "=")
+		if !ok || key == "" || value == "" {
+			return logFilter{}, fmt.Errorf("expected key=value, got %q", term)
+		}
+		switch key {
+		case "level":
+			value = strings.ToLower(value)
+			if value == "warn" {
+				value = "warning"
+			}
+			if !slices.Contains(runner.LogLevels, value) {
+				return logFilter{}, fmt.Errorf("unknown level %q", value)
+			}
+			f.level = value
+		case "runner":
+			f.runner = value
+		default:
+			f.fields[key] = value
+		}
+	}
+	return f, nil
+}
+
+func (f logFilter) active() bool {
+	return f.level != "" || f.runner != "" || len(f.fields) > 0
+}
+
+func (f logFilter) matches(entry runner.LogEntry) bool {
+	if f.level != "" && entry.Severity() < runner.LevelSeverity(f.level) {
+		return false
+	}
+	if f.runner != "" {
+		// Logs carry a short token, the filter may be given a longer one
+		token := entry.Fields["runner"]
+		if token == "" || !(strings.HasPrefix(token, f.runner) || strings.HasPrefix(f.runner, token)) {
+			return false
+		}
+	}
+	for key, value := range f.fields {
+		if entry.Fields[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// nextLevel returns the filter with the next minimum level of the L key:
+// none, info, warning, error and none again.
+func (f logFilter) nextLevel() logFilter {
+	switch f.level {
+	case "":
+		f.level = "info"
+	case "info":
+		f.level = "warning"
+	case "warning":
+		f.level = "error"
+	default:
+		f.level = ""
+	}
+	return f
+}
+
+func (f logFilter) String() string {
+	var terms []string
+	if f.level != "" {
+		terms = append(terms, "level="+f.level)
+	}
+	if f.runner != "" {
+		terms = append(terms, "runner="+f.runner)
+	}
+	keys := make([]string, 0, len(f.fields))
+	for key := range f.fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		terms = append(terms, key+"="+f.fields[key])
+	}
+	return strings.Join(terms, " ")
+}
+
+// levelStyles color log lines by level. Info lines keep the default color.
+var levelStyles = map[string]lipgloss.Style{
+	"trace":   lipgloss.NewStyle().Foreground(ColorMuted),
+	"debug":   lipgloss.NewStyle().Foreground(ColorMuted),
+	"warning": lipgloss.NewStyle().Foreground(ColorWarning),
+	"error":   lipgloss.NewStyle().Foreground(ColorError),
+	"fatal":   lipgloss.NewStyle().Foreground(ColorError).Bold(true),
+	"panic":   lipgloss.NewStyle().Foreground(ColorError).Bold(true),
+}
+
+// levelPaint returns the function that colors text of a line at level, or
+// nil for lines that are not colored.
+func levelPaint(level string) func(...string) string {
+	if style, ok := levelStyles[level]; ok {
+		return style.Render
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+)
+
+// logSearch matches log lines against the query of the Logs tab search
+// bar. Literal queries ignore case; regex queries are used as written.
+type logSearch struct {
+	query  string
+	regex  bool
+	filter bool
+	invert bool
+	re     *regexp.Regexp
+	err    error
+}
+
+// set compiles query in the current mode. An invalid regex matches
+// nothing and is reported by err.
+func (s *logSearch) set(query string) {
+	s.query = query
+	s.re = nil
+	s.err = nil
+	if query == "" {
+		return
+	}
+
+	pattern := "(?i)" + regexp.QuoteMeta(query)
+	if s.regex {
+		pattern = query
+	}
+	s.re, s.err = regexp.Compile(pattern)
+}
+
+func (s *logSearch) toggleRegex() {
+	s.regex = !s.regex
+	s.set(s.query)
+}
+
+func (s *logSearch) active() bool {
+	return s.re != nil
+}
+
+// find returns the byte ranges of the matches in line.
+func (s *logSearch) find(line string) [][]int {
+	if s.re == nil {
+		return nil
+	}
+	var matches [][]int
+	for _, m := range s.re.FindAllStringIndex(line, -1) {
+		// Empty matches of patterns like a* cannot be highlighted
+		if m[1] > m[0] {
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+// shows reports whether a line with the given matches is shown. Without
+// filter mode every line is.
+func (s *logSearch) shows(matches [][]int) bool {
+	if !s.filter || s.re == nil {
+		return true
+	}
+	return (len(matches) > 0) != s.invert
+}
+
+// modes describes the enabled search modes for the search bar.
+func (s *logSearch) modes() string {
+	var modes []string
+	if s.regex {
+		modes = append(modes, "regex")
+	}
+	if s.filter {
+		modes = append(modes, "filter")
+	}
+	if s.invert {
+		modes = append(modes, "invert")
+	}
+	if len(modes) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(modes, ",") + "]"
+}
+
+// highlight renders line with its matches highlighted; the match at index
+// current of matches, if any, is highlighted as the current one. The rest
+// of the line is colored with paint, unless it is nil.
+func highlight(line string, matches [][]int, current int, paint func(...string) string) string {
+	if paint == nil {
+		paint = plain
+	}
+	if len(matches) == 0 {
+		return paint(line)
+	}
+
+	var b strings.Builder
+	last := 0
+	for i, m := range matches {
+		if m[0] > last {
+			b.WriteString(paint(line[last:m[0]]))
+		}
+		style := SearchMatchStyle
+		if i == current {
+			style = CurrentMatchStyle
+		}
+		b.WriteString(style.Render(line[m[0]:m[1]]))
+		last = m[1]
+	}
+	if last < len(line) {
+		b.WriteString(paint(line[last:]))
+	}
+	return b.String()
+}
+
+func plain(strs ...string) string {
+	return strings.Join(strs, "")
+}
@@ -1,27 +1,60 @@
 package ui
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"strings"
+	"sync"
 
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/export"
 	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
 )
 
+const (
+	// maxLogLines bounds the lines kept in the Logs tab, so following a
+	// busy runner for hours does not grow memory without limit.
+	maxLogLines = 5000
+	// maxStreamBatch is the most streamed lines handled in one update.
+	maxStreamBatch = 200
+)
+
 type LogsView struct {
 	viewport   viewport.Model
 	service    runner.Service
 	runnerName string
-	logs       []string
+	logs       *logBuffer
 	loading    bool
+	loadID     int
 	spinner    spinner.Model
 	err        error
 	width      int
 	height     int
 	autoScroll bool
+	following  bool
+	stream     *logStream
+	streamID   int
+
+	search      logSearch
+	searchInput textinput.Model
+	searching   bool
+	filter      logFilter
+	filterInput textinput.Model
+	filtering   bool
+	filterErr   error
+	matches     []int // viewport line of each search match
+	current     int
+	shown       int
+
+	// visible are the entries shown, which exports write
+	visible []runner.LogEntry
+	export  *exportPrompt
+	notice  string
 }
 
 func NewLogsView(service runner.Service) *LogsView {
@@ -32,17 +65,30 @@ func NewLogsView(service runner.Service) *LogsView {
 	sp.Spinner = spinner.Dot
 	sp.Style = SpinnerStyle
 
+	searchInput := textinput.New()
+	searchInput.Prompt = "/"
+	searchInput.Placeholder = "Search logs"
+	searchInput.CharLimit = 256
+
+	filterInput := textinput.New()
+	filterInput.Prompt = ":"
+	filterInput.Placeholder = "level=warning job=1234 runner=abcd1234"
+	filterInput.CharLimit = 256
+
 	return &LogsView{
-		viewport:   vp,
-		service:    service,
-		spinner:    sp,
-		autoScroll: true,
+		viewport:    vp,
+		service:     service,
+		logs:        newLogBuffer(maxLogLines),
+		spinner:     sp,
+		autoScroll:  true,
+		searchInput: searchInput,
+		filterInput: filterInput,
 	}
 }
 
 func (v *LogsView) Init() tea.Cmd {
 	return tea.Batch(
-		v.loadLogs,
+		v.loadLogs(),
 		v.spinner.Tick,
 	)
 }
@@ -59,17 +105,69 @@ func (v *LogsView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return v, nil
 
 	case logsLoadedMsg:
-		v.logs = msg.logs
+		if msg.id != v.loadID {
+			// A later load replaced this one, possibly for another runner
+			return v, nil
+		}
+		v.logs.Reset()
+		v.logs.Append(msg.logs...)
 		v.loading = false
 		v.err = msg.err
 		v.updateViewport()
 		return v, nil
 
+	case logLinesMsg:
+		if v.stream == nil || msg.id != v.stream.id {
+			return v, nil
+		}
+		v.logs.Append(msg.lines...)
+		v.updateViewport()
+		return v, v.stream.next
+
+	case logStreamOpenedMsg:
+		if v.stream == nil || msg.id != v.stream.id {
+			return v, nil
+		}
+		if msg.err != nil {
+			v.stopStream()
+			v.following = false
+			v.err = msg.err
+			return v, nil
+		}
+		return v, v.stream.read()
+
+	case logStreamEndedMsg:
+		if v.stream == nil || msg.id != v.stream.id {
+			return v, nil
+		}
+		v.stopStream()
+		v.following = false
+		v.err = msg.err
+		return v, nil
+
+	case exportedMsg:
+		v.notice = msg.notice
+		if msg.err != nil {
+			v.notice = fmt.Sprintf("Error: %v", msg.err)
+		}
+		return v, nil
+
 	case tea.KeyMsg:
+		if v.export != nil {
+			return v, v.handleExportKey(msg)
+		}
+		if v.searching {
+			return v, v.handleSearchKey(msg)
+		}
+		if v.filtering {
+			return v, v.handleFilterKey(msg)
+		}
 		switch msg.String() {
 		case "r", "R":
 			v.loading = true
-			return v, v.loadLogs
+			return v, v.loadLogs()
+		case "f", "F":
+			return v, v.toggleFollow()
 		case "a", "A":
 			v.autoScroll = !v.autoScroll
 			if v.autoScroll {
@@ -80,9 +178,41 @@ func (v *LogsView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "G":
 			v.viewport.GotoBottom()
 		case "/":
-			// TODO: Implement search/filter
+			v.searching = true
+			v.searchInput.SetValue(v.search.query)
+			v.searchInput.CursorEnd()
+			return v, v.searchInput.Focus()
+		case ":":
+			v.filtering = true
+			v.filterErr = nil
+			v.filterInput.SetValue(v.filter.String())
+			v.filterInput.CursorEnd()
+			return v, v.filterInput.Focus()
+		case "e", "E":
+			name := "runner-logs"
+			if v.runnerName != "" {
+				name = v.runnerName + "-logs"
+			}
+			var cmd tea.Cmd
+			v.export, cmd = newExportPrompt(name)
+			v.notice = ""
+			return v, cmd
+		case "L":
+			v.filter = v.filter.nextLevel()
+			v.applySearch()
+			return v, nil
+		case "n":
+			v.jumpToMatch(1)
+			return v, nil
+		case "N":
+			v.jumpToMatch(-1)
+			return v, nil
+		case "esc":
+			v.filter = logFilter{}
+			v.clearSearch()
+			return v, nil
 		case "c", "C":
-			v.logs = []string{}
+			v.logs.Reset()
 			v.updateViewport()
 		}
 	}
@@ -117,32 +247,333 @@ func (v *LogsView) View() string {
 
 	if v.loading {
 		content = append(content, v.spinner.View()+" Loading logs...")
-	} else if len(v.logs) == 0 {
+	} else if v.logs.Len() == 0 && !v.following {
 		content = append(content, InfoBoxStyle.Render("No logs available"))
 	} else {
-		statusBar := fmt.Sprintf("Lines: %d | Position: %d%%", len(v.logs), int(v.viewport.ScrollPercent()*100))
+		statusBar := fmt.Sprintf("Lines: %d | Position: %d%%", v.logs.Len(), int(v.viewport.ScrollPercent()*100))
+		if v.shown != v.logs.Len() {
+			statusBar = fmt.Sprintf("Lines: %d of %d | Position: %d%%", v.shown, v.logs.Len(), int(v.viewport.ScrollPercent()*100))
+		}
 		if v.autoScroll {
 			statusBar += " | Auto-scroll: ON"
 		}
+		if v.following {
+			statusBar += " | Following"
+		}
 		content = append(content,
 			v.viewport.View(),
-			"",
+			v.inputBar(),
 			lipgloss.NewStyle().Foreground(ColorMuted).Render(statusBar),
 		)
 	}
 
+	if v.export != nil {
+		content = append(content, "", v.export.View())
+	} else if v.notice != "" {
+		content = append(content, "", v.notice)
+	}
+
 	// Help is now shown in the status bar
 
 	return lipgloss.JoinVertical(lipgloss.Left, content...)
 }
 
+// SetRunner shows the logs of another runner. A running stream belongs to
+// the previous runner and is stopped; follow mode restarts with the view.
 func (v *LogsView) SetRunner(name string) {
+	v.stopStream()
 	v.runnerName = name
 	v.loading = true
 }
 
+// SetService points the view at another host. The selected runner and its
+// logs belong to the previous host and are dropped.
+func (v *LogsView) SetService(service runner.Service) {
+	v.stopStream()
+	v.following = false
+	v.service = service
+	v.runnerName = ""
+	v.logs.Reset()
+	v.err = nil
+	v.loading = true
+	v.updateViewport()
+}
+
+// Activate loads the logs of a newly selected runner and resumes following
+// the log when the tab is shown again.
+func (v *LogsView) Activate() tea.Cmd {
+	var cmds []tea.Cmd
+	if v.loading {
+		cmds = append(cmds, v.loadLogs(), v.spinner.Tick)
+	}
+	if v.following && v.stream == nil {
+		cmds = append(cmds, v.startStream())
+	}
+	return tea.Batch(cmds...)
+}
+
+// Deactivate stops the log stream while the tab is not shown. Follow mode
+// stays on and resumes with Activate.
+func (v *LogsView) Deactivate() {
+	v.stopStream()
+}
+
+func (v *LogsView) toggleFollow() tea.Cmd {
+	if v.following {
+		v.following = false
+		v.stopStream()
+		return nil
+	}
+	v.following = true
+	v.autoScroll = true
+	return v.startStream()
+}
+
+// startStream subscribes to the log of the current runner. The stream is
+// opened in a command, as finding the runner may take a round trip to the
+// host; it owns the reader from the start, so stopping it closes the reader
+// even when the message announcing it never reaches this tab.
+func (v *LogsView) startStream() tea.Cmd {
+	v.stopStream()
+
+	v.streamID++
+	v.stream = newLogStream(v.streamID)
+	return v.stream.open(v.service, v.runnerName)
+}
+
+func (v *LogsView) stopStream() {
+	if v.stream != nil {
+		v.stream.Close()
+		v.stream = nil
+	}
+}
+
+// Capturing reports whether the search or filter bar has the keyboard, so
+// global key bindings must not be applied.
+func (v *LogsView) Capturing() bool {
+	return v.searching || v.filtering || v.export != nil
+}
+
+// handleExportKey edits the export prompt and, once a path is entered,
+// writes the shown lines to it.
+func (v *LogsView) handleExportKey(msg tea.KeyMsg) tea.Cmd {
+	done, path, cmd := v.export.handleKey(msg)
+	if !done {
+		return cmd
+	}
+	overwrite := v.export.overwrite
+	v.export = nil
+	if path == "" {
+		return nil
+	}
+
+	entries := v.visible
+	return exportCmd(path, count(len(entries), "line"), overwrite, func(w io.Writer, format export.Format) error {
+		return export.WriteLogs(w, format, entries)
+	})
+}
+
+// handleSearchKey edits the search bar. The search is applied as the query
+// is typed; Enter keeps it and returns to the logs, Esc drops it.
+func (v *LogsView) handleSearchKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		v.searching = false
+		v.searchInput.Blur()
+		return nil
+	case "esc":
+		v.searching = false
+		v.searchInput.Blur()
+		v.clearSearch()
+		return nil
+	case "ctrl+r":
+		v.search.toggleRegex()
+		v.applySearch()
+		return nil
+	case "ctrl+f":
+		v.search.filter = !v.search.filter
+		v.applySearch()
+		return nil
+	case "ctrl+x":
+		v.search.invert = !v.search.invert
+		v.applySearch()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	v.searchInput, cmd = v.searchInput.Update(msg)
+	if query := v.searchInput.Value(); query != v.search.query {
+		v.search.set(query)
+		v.applySearch()
+	}
+	return cmd
+}
+
+// handleFilterKey edits the filter bar. Like the search, the filter is
+// applied as it is typed; terms that do not parse keep the last filter.
+func (v *LogsView) handleFilterKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		v.filtering = false
+		v.filterErr = nil
+		v.filterInput.Blur()
+		return nil
+	case "esc":
+		v.filtering = false
+		v.filterErr = nil
+		v.filterInput.Blur()
+		v.filter = logFilter{}
+		v.applySearch()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	v.filterInput, cmd = v.filterInput.Update(msg)
+	filter, err := parseLogFilter(v.filterInput.Value())
+	v.filterErr = err
+	if err == nil {
+		v.filter = filter
+		v.applySearch()
+	}
+	return cmd
+}
+
+func (v *LogsView) clearSearch() {
+	v.search.set("")
+	v.searchInput.SetValue("")
+	v.applySearch()
+}
+
+// applySearch shows the logs for a changed search and picks the current
+// match: the last one when auto-scrolling to new lines, otherwise the first
+// one from the top of the viewport on.
+func (v *LogsView) applySearch() {
+	v.current = -1
+	v.updateViewport()
+	if len(v.matches) == 0 || v.autoScroll {
+		return
+	}
+
+	v.current = 0
+	for i, line := range v.matches {
+		if line >= v.viewport.YOffset {
+			v.current = i
+			break
+		}
+	}
+	v.updateViewport()
+	v.scrollToCurrent()
+}
+
+// jumpToMatch moves to the next match, or the previous one for a negative
+// delta, wrapping around. Auto-scroll is turned off so new lines do not
+// move the view away from the match.
+func (v *LogsView) jumpToMatch(delta int) {
+	if len(v.matches) == 0 {
+		return
+	}
+	v.autoScroll = false
+	v.current = (v.current + delta + len(v.matches)) % len(v.matches)
+	v.updateViewport()
+	v.scrollToCurrent()
+}
+
+// scrollToCurrent centers the current match if it is out of view.
+func (v *LogsView) scrollToCurrent() {
+	if v.current < 0 || v.current >= len(v.matches) {
+		return
+	}
+	line := v.matches[v.current]
+	if line < v.viewport.YOffset || line >= v.viewport.YOffset+v.viewport.Height {
+		v.viewport.SetYOffset(line - v.viewport.Height/2)
+	}
+}
+
+// inputBar shows the search or filter bar being edited, or else the search
+// and filter in use.
+func (v *LogsView) inputBar() string {
+	if v.filtering {
+		status := lipgloss.NewStyle().Foreground(ColorMuted).Render("Enter: Done • Esc: Clear")
+		if v.filterErr != nil {
+			status = lipgloss.NewStyle().Foreground(ColorError).Render(v.filterErr.Error())
+		}
+		return lipgloss.JoinHorizontal(lipgloss.Top, v.filterInput.View(), "  ", status)
+	}
+
+	bar := v.searchBar()
+	if v.searching || !v.filter.active() {
+		return bar
+	}
+	if bar != "" {
+		bar += "  "
+	}
+	return bar + ":" + v.filter.String()
+}
+
+func (v *LogsView) searchBar() string {
+	if v.searching {
+		help := "Enter: Done • Esc: Clear • Ctrl+R: Regex • Ctrl+F: Filter • Ctrl+X: Invert"
+		return lipgloss.JoinHorizontal(lipgloss.Top, v.searchInput.View(), "  ", v.searchStatus(),
+			"  ", lipgloss.NewStyle().Foreground(ColorMuted).Render(help))
+	}
+	if v.search.query == "" {
+		return ""
+	}
+	return "/" + v.search.query + "  " + v.searchStatus()
+}
+
+func (v *LogsView) searchStatus() string {
+	status := v.search.modes()
+	switch {
+	case v.search.err != nil:
+		return lipgloss.NewStyle().Foreground(ColorError).Render(strings.TrimSpace(status + " invalid regex"))
+	case v.search.query == "":
+	case len(v.matches) == 0:
+		status = strings.TrimSpace(status + " no matches")
+	default:
+		status = strings.TrimSpace(fmt.Sprintf("%s %d/%d", status, v.current+1, len(v.matches)))
+	}
+	return status
+}
+
+// updateViewport renders the buffered lines that pass the filter and the
+// search, colored by level with matches highlighted, and records the
+// matches for n/N.
 func (v *LogsView) updateViewport() {
-	content := strings.Join(v.logs, "\n")
+	entries := v.logs.Entries()
+	v.matches = v.matches[:0]
+
+	kept := make([]runner.LogEntry, 0, len(entries))
+	var found [][][]int
+	for _, entry := range entries {
+		if !v.filter.matches(entry) {
+			continue
+		}
+		f := v.search.find(entry.Raw)
+		if !v.search.shows(f) {
+			continue
+		}
+		for range f {
+			v.matches = append(v.matches, len(kept))
+		}
+		kept = append(kept, entry)
+		found = append(found, f)
+	}
+	v.current = min(v.current, len(v.matches)-1)
+	if v.autoScroll {
+		// The newest match is the one in view
+		v.current = len(v.matches) - 1
+	}
+
+	lines := make([]string, len(kept))
+	first := 0
+	for i, entry := range kept {
+		lines[i] = highlight(entry.Raw, found[i], v.current-first, levelPaint(entry.Level))
+		first += len(found[i])
+	}
+	v.shown = len(kept)
+	v.visible = kept
+	content := strings.Join(lines, "\n")
 	v.viewport.SetContent(content)
 
 	if v.autoScroll {
@@ -150,16 +581,149 @@ func (v *LogsView) updateViewport() {
 	}
 }
 
-func (v *LogsView) loadLogs() tea.Msg {
-	logs, err := v.service.GetRunnerLogs(v.runnerName, 1000)
-	if err != nil {
-		return logsLoadedMsg{err: err}
+// loadLogs returns a command loading the recent logs of the current runner.
+// Each load gets a new id, so a slow load for a runner that is no longer
+// selected cannot overwrite the logs of the current one.
+func (v *LogsView) loadLogs() tea.Cmd {
+	v.loadID++
+	id, service, name := v.loadID, v.service, v.runnerName
+	return func() tea.Msg {
+		logs, err := service.GetRunnerLogs(name, 1000)
+		if err != nil {
+			return logsLoadedMsg{id: id, err: err}
+		}
+
+		return logsLoadedMsg{id: id, logs: logs}
+	}
+}
+
+// logStream reads lines from Service.StreamRunnerLogs in the background.
+// Its next command waits for the lines read since the last call.
+type logStream struct {
+	id     int
+	mu     sync.Mutex // guards reader, which open sets
+	reader io.ReadCloser
+	lines  chan string
+	done   chan struct{}
+	once   sync.Once
+	err    error
+}
+
+func newLogStream(id int) *logStream {
+	return &logStream{
+		id:    id,
+		lines: make(chan string, maxStreamBatch),
+		done:  make(chan struct{}),
+	}
+}
+
+// open returns a command starting the log command of the runner. A stream
+// closed in the meantime closes the new reader right away.
+func (s *logStream) open(service runner.Service, name string) tea.Cmd {
+	return func() tea.Msg {
+		reader, err := service.StreamRunnerLogs(name)
+		if err != nil {
+			return logStreamOpenedMsg{id: s.id, err: err}
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		select {
+		case <-s.done:
+			_ = reader.Close()
+			return nil
+		default:
+		}
+		s.reader = reader
+		return logStreamOpenedMsg{id: s.id}
 	}
+}
 
-	return logsLoadedMsg{logs: logs}
+// read starts reading the opened stream and returns the command waiting
+// for its first lines.
+func (s *logStream) read() tea.Cmd {
+	s.mu.Lock()
+	reader := s.reader
+	s.mu.Unlock()
+
+	go func() {
+		defer close(s.lines)
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			select {
+			case s.lines <- scanner.Text():
+			case <-s.done:
+				return
+			}
+		}
+		s.err = scanner.Err()
+	}()
+
+	return s.next
+}
+
+func (s *logStream) next() tea.Msg {
+	line, ok := <-s.lines
+	if !ok {
+		return s.ended()
+	}
+
+	batch := []string{line}
+	for len(batch) < maxStreamBatch {
+		select {
+		case line, ok := <-s.lines:
+			if !ok {
+				return logLinesMsg{id: s.id, lines: batch}
+			}
+			batch = append(batch, line)
+		default:
+			return logLinesMsg{id: s.id, lines: batch}
+		}
+	}
+	return logLinesMsg{id: s.id, lines: batch}
+}
+
+func (s *logStream) ended() tea.Msg {
+	select {
+	case <-s.done:
+		// Closed on purpose; the read error is the closed stream
+		return logStreamEndedMsg{id: s.id}
+	default:
+		return logStreamEndedMsg{id: s.id, err: s.err}
+	}
+}
+
+// Close stops reading and closes the stream, which stops the command
+// behind it.
+func (s *logStream) Close() {
+	s.once.Do(func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		close(s.done)
+		if s.reader != nil {
+			_ = s.reader.Close()
+		}
+	})
 }
 
 type logsLoadedMsg struct {
+	id   int
 	logs []string
 	err  error
 }
+
+type logLinesMsg struct {
+	id    int
+	lines []string
+}
+
+type logStreamOpenedMsg struct {
+	id  int
+	err error
+}
+
+type logStreamEndedMsg struct {
+	id  int
+	err error
+}
@@ -0,0 +1,350 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+// streamService hands out log streams fed through pipes and records which
+// of them were closed.
+type streamService struct {
+	runner.Service
+	mu      sync.Mutex
+	writers []*io.PipeWriter
+	names   []string
+}
+
+func (s *streamService) GetRunnerLogs(_ string, _ int) ([]string, error) {
+	return []string{"snapshot"}, nil
+}
+
+func (s *streamService) StreamRunnerLogs(name string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, w := io.Pipe()
+	s.writers = append(s.writers, w)
+	s.names = append(s.names, name)
+	return r, nil
+}
+
+func (s *streamService) writer(i int) *io.PipeWriter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.writers[i]
+}
+
+func TestLogsView_Follow(t *testing.T) {
+	service := &streamService{}
+	v := NewLogsView(service)
+	v.SetRunner("docker-1")
+	v.Update(v.loadLogs()())
+
+	_, open := v.Update(key("f"))
+	if open == nil || len(service.names) != 0 {
+		t.Fatalf("expected the stream to be opened in a command, got %v", service.names)
+	}
+	_, next := v.Update(open())
+	if next == nil || len(service.names) != 1 || service.names[0] != "docker-1" {
+		t.Fatalf("expected a stream of docker-1, got %v", service.names)
+	}
+
+	go fmt.Fprintln(service.writer(0), "line 1\nline 2")
+	for v.logs.Len() < 3 {
+		_, next = v.Update(next())
+	}
+	if got := strings.Join(v.logs.Lines(), ","); got != "snapshot,line 1,line 2" {
+		t.Errorf("unexpected logs %q", got)
+	}
+	if !strings.Contains(v.View(), "Following") {
+		t.Errorf("expected follow indicator:\n%s", v.View())
+	}
+
+	// Leaving the tab kills the command behind the stream
+	v.Deactivate()
+	if _, err := fmt.Fprintln(service.writer(0), "line 3"); err != io.ErrClosedPipe {
+		t.Errorf("expected stream to be closed, got %v", err)
+	}
+
+	// Coming back resumes following with a new stream
+	open = v.Activate()
+	if open == nil {
+		t.Fatal("expected a new stream")
+	}
+	v.Update(open())
+	if len(service.names) != 2 {
+		t.Fatalf("expected a new stream, got %v", service.names)
+	}
+
+	// Changing runner closes the stream of the previous one
+	v.SetRunner("shell-1")
+	if _, err := fmt.Fprintln(service.writer(1), "line 4"); err != io.ErrClosedPipe {
+		t.Errorf("expected stream to be closed, got %v", err)
+	}
+	for _, cmd := range v.Activate()().(tea.BatchMsg) {
+		if msg, ok := cmd().(logStreamOpenedMsg); ok {
+			v.Update(msg)
+		}
+	}
+	if service.names[2] != "shell-1" {
+		t.Errorf("expected a stream of shell-1, got %v", service.names)
+	}
+
+	v.Update(key("f"))
+	if v.following || v.stream != nil {
+		t.Error("expected f to stop following")
+	}
+}
+
+func TestLogsView_FollowIsBounded(t *testing.T) {
+	service := &streamService{}
+	v := NewLogsView(service)
+	v.Update(v.loadLogs()())
+	_, open := v.Update(key("f"))
+	_, next := v.Update(open())
+
+	go func() {
+		w := service.writer(0)
+		for i := 0; i < maxLogLines+10; i++ {
+			fmt.Fprintf(w, "line %d\n", i)
+		}
+		w.Close()
+	}()
+	for next != nil {
+		_, next = v.Update(next())
+	}
+
+	lines := v.logs.Lines()
+	if len(lines) != maxLogLines || lines[len(lines)-1] != fmt.Sprintf("line %d", maxLogLines+9) {
+		t.Errorf("expected the last %d lines, got %d ending with %q", maxLogLines, len(lines), lines[len(lines)-1])
+	}
+	if v.following {
+		t.Error("expected follow mode to end with the stream")
+	}
+}
+
+func TestLogsView_StaleResults(t *testing.T) {
+	service := &streamService{}
+	v := NewLogsView(service)
+	v.SetRunner("docker-1")
+	v.Update(v.loadLogs()())
+
+	// A stream stopped before it is opened closes its reader itself
+	_, open := v.Update(key("f"))
+	v.Deactivate()
+	if msg := open(); msg != nil {
+		t.Errorf("expected no message for a stopped stream, got %#v", msg)
+	}
+	if _, err := fmt.Fprintln(service.writer(0), "line"); err != io.ErrClosedPipe {
+		t.Errorf("expected stream to be closed, got %v", err)
+	}
+
+	// The logs of the previous runner arrive after those of the new one
+	logs := &searchService{}
+	v = NewLogsView(logs)
+	v.SetRunner("docker-1")
+	stale := v.loadLogs()
+	v.SetRunner("shell-1")
+	current := v.loadLogs()
+	logs.logs = []string{"shell-1"}
+	v.Update(current())
+	logs.logs = []string{"docker-1"}
+	v.Update(stale())
+	if got := strings.Join(v.logs.Lines(), ","); got != "shell-1" {
+		t.Errorf("expected the logs of shell-1, got %q", got)
+	}
+}
+
+// searchService serves a fixed log snapshot.
+type searchService struct {
+	runner.Service
+	logs []string
+}
+
+func (s *searchService) GetRunnerLogs(_ string, _ int) ([]string, error) {
+	return s.logs, nil
+}
+
+func TestLogsView_Search(t *testing.T) {
+	v := NewLogsView(&searchService{logs: []string{
+		"level=info msg=\"Job succeeded\" job=1",
+		"level=error msg=\"Job failed\" job=2",
+		"level=info msg=\"Checking for jobs\"",
+		"level=ERROR msg=\"Job failed\" job=3",
+	}})
+	v.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	v.Update(v.loadLogs()())
+
+	v.Update(key("/"))
+	if !v.Capturing() {
+		t.Fatal("expected / to open the search bar")
+	}
+	for _, r := range "error" {
+		v.Update(key(string(r)))
+	}
+	if len(v.matches) != 2 || !strings.Contains(v.View(), "2/2") {
+		t.Fatalf("expected case-insensitive matches on lines 1 and 3, got %v:\n%s", v.matches, v.View())
+	}
+
+	// Filter mode shows only matching lines, inverted only the others
+	v.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	if v.shown != 2 || strings.Contains(v.viewport.View(), "succeeded") {
+		t.Errorf("expected only matching lines, got %d:\n%s", v.shown, v.viewport.View())
+	}
+	v.Update(tea.KeyMsg{Type: tea.KeyCtrlX})
+	if v.shown != 2 || strings.Contains(v.viewport.View(), "failed") {
+		t.Errorf("expected only non-matching lines, got %d:\n%s", v.shown, v.viewport.View())
+	}
+	v.Update(tea.KeyMsg{Type: tea.KeyCtrlX})
+	v.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+
+	// Regex mode is case-sensitive unless asked otherwise
+	v.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if len(v.matches) != 1 || v.matches[0] != 1 {
+		t.Errorf("expected a regex match on line 1, got %v", v.matches)
+	}
+	v.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	v.Update(key("("))
+	if v.search.err == nil || len(v.matches) != 0 || !strings.Contains(v.View(), "invalid regex") {
+		t.Errorf("expected an invalid regex, got %v", v.matches)
+	}
+	v.searchInput.SetValue("")
+	for _, r := range `job=\d` {
+		v.Update(key(string(r)))
+	}
+
+	v.Update(key("enter"))
+	if v.Capturing() || len(v.matches) != 3 {
+		t.Fatalf("expected the search to be kept, got %v", v.matches)
+	}
+
+	// n and N move between matches and wrap around
+	v.Update(key("n"))
+	if v.current != 0 || v.autoScroll {
+		t.Errorf("expected n to wrap to the first match without auto-scroll, got %d", v.current)
+	}
+	v.Update(key("N"))
+	if v.current != 2 {
+		t.Errorf("expected N to wrap to the last match, got %d", v.current)
+	}
+
+	v.Update(key("esc"))
+	if v.search.active() || len(v.matches) != 0 || v.shown != 4 {
+		t.Error("expected Esc to clear the search")
+	}
+}
+
+func TestLogsView_Filter(t *testing.T) {
+	v := NewLogsView(&searchService{logs: []string{
+		`Jan 02 10:00:00 host gitlab-runner[812]: Checking for jobs... received   job=1001 runner=aaaaaaaa`,
+		`Jan 02 10:00:05 host gitlab-runner[812]: WARNING: Job failed: exit code 1  job=1001 runner=aaaaaaaa`,
+		`Jan 02 10:00:07 host gitlab-runner[812]: Checking for jobs... received   job=1002 runner=bbbbbbbb`,
+		`Jan 02 10:00:09 host gitlab-runner[812]: ERROR: Job failed: canceled      job=1002 runner=bbbbbbbb`,
+	}})
+	v.Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+	v.Update(v.loadLogs()())
+
+	// L cycles the minimum level
+	v.Update(key("L"))
+	v.Update(key("L"))
+	if v.shown != 2 || v.filter.level != "warning" {
+		t.Errorf("expected warnings and errors, got %d lines at %q", v.shown, v.filter.level)
+	}
+
+	v.Update(key(":"))
+	if !v.Capturing() || v.filterInput.Value() != "level=warning" {
+		t.Fatalf("expected the filter bar with the level, got %q", v.filterInput.Value())
+	}
+	v.filterInput.SetValue("")
+	for _, r := range "runner=bbbbbbbb1234" {
+		v.Update(key(string(r)))
+	}
+	if v.shown != 2 {
+		t.Errorf("expected the lines of the runner with the full token, got %d", v.shown)
+	}
+	v.filterInput.SetValue("")
+	for _, r := range "runner=aaaa job=1001" {
+		v.Update(key(string(r)))
+	}
+	if v.shown != 2 || !strings.Contains(v.View(), "Lines: 2 of 4") {
+		t.Errorf("expected the lines of job 1001 on runner aaaaaaaa:\n%s", v.View())
+	}
+
+	v.Update(key(" "))
+	v.Update(key("x"))
+	if v.filterErr == nil || v.shown != 2 {
+		t.Errorf("expected an invalid term to keep the filter, got %d lines", v.shown)
+	}
+
+	v.Update(key("esc"))
+	if v.Capturing() || v.filter.active() || v.shown != 4 {
+		t.Error("expected Esc to clear the filter")
+	}
+}
+
+func TestParseLogFilter(t *testing.T) {
+	f, err := parseLogFilter("level=WARN runner=abcd project=7")
+	if err != nil {
+		t.Fatalf("parseLogFilter failed: %v", err)
+	}
+	if f.level != "warning" || f.runner != "abcd" || f.fields["project"] != "7" {
+		t.Errorf("unexpected filter %+v", f)
+	}
+	if f.String() != "level=warning runner=abcd project=7" {
+		t.Errorf("String() = %q", f.String())
+	}
+
+	for _, s := range []string{"level=loud", "job", "=1"} {
+		if _, err := parseLogFilter(s); err == nil {
+			t.Errorf("expected %q to be rejected", s)
+		}
+	}
+}
+
+func TestLogsView_ExportShownLines(t *testing.T) {
+	v := NewLogsView(&searchService{logs: []string{
+		`Checking for jobs... received   job=1001 runner=aaaaaaaa`,
+		`ERROR: Job failed: canceled      job=1002 runner=bbbbbbbb`,
+	}})
+	v.SetRunner("docker-1")
+	v.Update(v.loadLogs()())
+	v.Update(key("L"))
+	v.Update(key("L"))
+
+	v.Update(key("e"))
+	if !v.Capturing() || !strings.HasPrefix(v.export.input.Value(), "docker-1-logs-") {
+		t.Fatalf("expected the export prompt with a suggested name:\n%s", v.View())
+	}
+
+	// Tab switches the extension to the next format
+	v.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if !strings.HasSuffix(v.export.input.Value(), ".jsonl") {
+		t.Errorf("expected a .jsonl file, got %q", v.export.input.Value())
+	}
+
+	path := filepath.Join(t.TempDir(), "incident", "logs.log")
+	v.export.input.SetValue(path)
+	_, cmd := v.Update(key("enter"))
+	if cmd == nil || v.Capturing() {
+		t.Fatal("expected the export to run")
+	}
+	v.Update(cmd())
+	if !strings.Contains(v.View(), "Exported 1 line to "+path) {
+		t.Errorf("expected a notice:\n%s", v.View())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "ERROR: Job failed: canceled      job=1002 runner=bbbbbbbb\n" {
+		t.Errorf("expected only the filtered line, got %q", data)
+	}
+}
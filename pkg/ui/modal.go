@@ -0,0 +1,54 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var ModalStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(ColorWarning).
+	Padding(1, 3).
+	Width(60)
+
+// confirmModal asks before a destructive action is run. y or Enter runs
+// the action, n or Esc dismisses the modal.
+type confirmModal struct {
+	title   string
+	message string
+	action  tea.Cmd
+}
+
+func newConfirmModal(title, message string, action tea.Cmd) *confirmModal {
+	return &confirmModal{
+		title:   title,
+		message: message,
+		action:  action,
+	}
+}
+
+// handleKey returns whether the modal is done and the command to run.
+func (m *confirmModal) handleKey(msg tea.KeyMsg) (done bool, cmd tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y", "enter":
+		return true, m.action
+	case "n", "N", "esc", "q":
+		return true, nil
+	}
+	return false, nil
+}
+
+func (m *confirmModal) View(width int) string {
+	box := ModalStyle.Render(lipgloss.JoinVertical(
+		lipgloss.Left,
+		lipgloss.NewStyle().Bold(true).Foreground(ColorWarning).Render(m.title),
+		"",
+		m.message,
+		"",
+		HelpStyle.Render("y/Enter: Confirm • n/Esc: Cancel"),
+	))
+	if width <= 0 {
+		return box
+	}
+	return lipgloss.PlaceHorizontal(width, lipgloss.Center, box)
+}
@@ -0,0 +1,207 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+const (
+	registerURL = iota
+	registerToken
+	registerExecutor
+	registerDescription
+	registerTags
+	registerDockerImage
+	registerKubernetesNamespace
+	registerFieldCount
+)
+
+// registerForm collects the settings of a runner to register. The executor
+// is picked from runner.Executors with ←/→; fields the executor does not
+// use are skipped.
+type registerForm struct {
+	inputs   []textinput.Model
+	executor int
+	focus    int
+	running  bool
+	err      error
+}
+
+func newRegisterForm() *registerForm {
+	inputs := make([]textinput.Model, registerFieldCount)
+	for i := range inputs {
+		t := textinput.New()
+		t.CharLimit = 256
+		inputs[i] = t
+	}
+
+	inputs[registerURL].Prompt = "GitLab URL: "
+	inputs[registerURL].Placeholder = "https://gitlab.example.com"
+
+	inputs[registerToken].Prompt = "Token: "
+	inputs[registerToken].Placeholder = "glrt-... or registration token"
+	inputs[registerToken].EchoMode = textinput.EchoPassword
+
+	inputs[registerExecutor].Prompt = "Executor: "
+
+	inputs[registerDescription].Prompt = "Description: "
+	inputs[registerDescription].Placeholder = "Defaults to the hostname"
+
+	inputs[registerTags].Prompt = "Tags: "
+	inputs[registerTags].Placeholder = "Comma-separated, registration tokens only"
+
+	inputs[registerDockerImage].Prompt = "Docker Image: "
+	inputs[registerDockerImage].Placeholder = "alpine:latest"
+
+	inputs[registerKubernetesNamespace].Prompt = "Namespace: "
+	inputs[registerKubernetesNamespace].Placeholder = "default"
+
+	f := &registerForm{inputs: inputs}
+	f.setFocus(registerURL)
+	return f
+}
+
+// options returns the runner.RegisterOptions entered so far.
+func (f *registerForm) options() runner.RegisterOptions {
+	opts := runner.RegisterOptions{
+		URL:         strings.TrimSpace(f.inputs[registerURL].Value()),
+		Token:       strings.TrimSpace(f.inputs[registerToken].Value()),
+		Executor:    runner.Executors[f.executor],
+		Description: strings.TrimSpace(f.inputs[registerDescription].Value()),
+	}
+	for _, tag := range strings.Split(f.inputs[registerTags].Value(), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			opts.TagList = append(opts.TagList, tag)
+		}
+	}
+	if opts.UsesDockerImage() {
+		opts.DockerImage = strings.TrimSpace(f.inputs[registerDockerImage].Value())
+	}
+	if opts.UsesKubernetesNamespace() {
+		opts.KubernetesNamespace = strings.TrimSpace(f.inputs[registerKubernetesNamespace].Value())
+	}
+	return opts
+}
+
+// visible reports whether field applies to the selected executor.
+func (f *registerForm) visible(field int) bool {
+	opts := runner.RegisterOptions{Executor: runner.Executors[f.executor]}
+	switch field {
+	case registerDockerImage:
+		return opts.UsesDockerImage()
+	case registerKubernetesNamespace:
+		return opts.UsesKubernetesNamespace()
+	}
+	return true
+}
+
+func (f *registerForm) setFocus(field int) tea.Cmd {
+	f.focus = field
+	var cmd tea.Cmd
+	for i := range f.inputs {
+		if i == field && i != registerExecutor {
+			cmd = f.inputs[i].Focus()
+		} else {
+			f.inputs[i].Blur()
+		}
+	}
+	return cmd
+}
+
+func (f *registerForm) moveFocus(forward bool) tea.Cmd {
+	field := f.focus
+	for {
+		if forward {
+			field = (field + 1) % registerFieldCount
+		} else {
+			field = (field - 1 + registerFieldCount) % registerFieldCount
+		}
+		if f.visible(field) {
+			return f.setFocus(field)
+		}
+	}
+}
+
+func (f *registerForm) cycleExecutor(forward bool) {
+	if forward {
+		f.executor = (f.executor + 1) % len(runner.Executors)
+	} else {
+		f.executor = (f.executor - 1 + len(runner.Executors)) % len(runner.Executors)
+	}
+}
+
+// handleKey returns whether the form was cancelled and whether it should
+// be submitted.
+func (f *registerForm) handleKey(msg tea.KeyMsg) (cancel, submit bool, cmd tea.Cmd) {
+	if f.running {
+		return false, false, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		return true, false, nil
+	case "ctrl+s":
+		return false, true, nil
+	case "tab", "down":
+		return false, false, f.moveFocus(true)
+	case "shift+tab", "up":
+		return false, false, f.moveFocus(false)
+	case "enter":
+		if f.focus == f.lastVisible() {
+			return false, true, nil
+		}
+		return false, false, f.moveFocus(true)
+	case "left", "right":
+		if f.focus == registerExecutor {
+			f.cycleExecutor(msg.String() == "right")
+			return false, false, nil
+		}
+	}
+
+	f.inputs[f.focus], cmd = f.inputs[f.focus].Update(msg)
+	return false, false, cmd
+}
+
+func (f *registerForm) lastVisible() int {
+	for field := registerFieldCount - 1; field > 0; field-- {
+		if f.visible(field) {
+			return field
+		}
+	}
+	return 0
+}
+
+func (f *registerForm) View() string {
+	content := []string{TitleStyle.Render("Register Runner"), ""}
+
+	for i := range f.inputs {
+		if !f.visible(i) {
+			continue
+		}
+		style := InputStyle
+		if i == f.focus {
+			style = FocusedInputStyle
+		}
+		line := f.inputs[i].View()
+		if i == registerExecutor {
+			line = fmt.Sprintf("%s‹ %s ›", f.inputs[i].Prompt, runner.Executors[f.executor])
+		}
+		content = append(content, style.Render(line))
+	}
+
+	content = append(content, "")
+	switch {
+	case f.running:
+		content = append(content, InfoBoxStyle.Render("Registering runner..."))
+	case f.err != nil:
+		content = append(content, ErrorBoxStyle.Render(f.err.Error()))
+	}
+	content = append(content, HelpStyle.Render("Tab/↑/↓: Next field • ←/→: Executor • Enter on last field or Ctrl+S: Register • Esc: Cancel"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, content...)
+}
@@ -0,0 +1,424 @@
+package ui
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/config"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+// formSection is a group of fields of the runner form.
+type formSection struct {
+	name   string
+	fields []config.Field
+}
+
+// runnerFormSections groups the keys of config.RunnerSchema. Keys managed
+// by gitlab-runner register, such as the token, are left out.
+var runnerFormSections = buildFormSections()
+
+func buildFormSections() []formSection {
+	sections := []formSection{{name: "General"}, {name: "Scripts"}, {name: "Docker"}, {name: "Kubernetes"}, {name: "Machine"}}
+	prefixes := map[string]int{"docker.": 2, "kubernetes.": 3, "machine.": 4}
+
+	var tableLists []string
+	for _, f := range config.RunnerSchema() {
+		if f.Kind == config.KindTableList {
+			tableLists = append(tableLists, f.Key+".")
+		}
+	}
+	inTableList := func(key string) bool {
+		for _, prefix := range tableLists {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, f := range config.RunnerSchema() {
+		if f.Kind == config.KindTable || f.Kind == config.KindTime || inTableList(f.Key) {
+			continue
+		}
+		section := -1
+		switch {
+		case f.Key == "id" || f.Key == "token":
+		case strings.HasSuffix(f.Key, "_script"):
+			section = 1
+		case !strings.Contains(f.Key, "."):
+			section = 0
+		default:
+			for prefix, i := range prefixes {
+				// Nested tables, such as [runners.docker.services], are
+				// listed in their parent section
+				if strings.HasPrefix(f.Key, prefix) {
+					section = i
+				}
+			}
+		}
+		if section >= 0 {
+			sections[section].fields = append(sections[section].fields, f)
+		}
+	}
+	return sections
+}
+
+// entryList edits the items of a list field, or the key=value entries of
+// a map field.
+type entryList struct {
+	field    config.Field
+	items    []string
+	selected int
+	// editing is the index of the item in the input, len(items) for a new
+	// one, or -1
+	editing int
+}
+
+// runnerForm edits every key of a [[runners]] entry the schema models. The
+// fields of the focused section are listed with their values; Enter edits
+// the focused one, toggles a bool or opens the entries of a list or map.
+// Values are checked and written to the runner as each field is applied.
+type runnerForm struct {
+	runner   *runner.RunnerConfig
+	sections []formSection
+	section  int
+	focus    int
+	offset   int
+	input    textinput.Model
+	editing  bool
+	entries  *entryList
+	err      error
+	// findings are the validation findings of the runner by field key,
+	// set by the Config view before each render
+	findings map[string][]config.Finding
+}
+
+func newRunnerForm(r *runner.RunnerConfig) *runnerForm {
+	input := textinput.New()
+	input.CharLimit = 1024
+	input.Prompt = "> "
+
+	f := &runnerForm{runner: r, input: input}
+	for _, section := range runnerFormSections {
+		if f.applies(section.name) {
+			f.sections = append(f.sections, section)
+		}
+	}
+	return f
+}
+
+// applies reports whether a section is shown for the runner's executor.
+// Sections of other executors are shown when the runner already has their
+// table.
+func (f *runnerForm) applies(section string) bool {
+	executor := f.runner.Executor
+	switch section {
+	case "Docker":
+		return strings.HasPrefix(executor, "docker") || f.runner.Docker != nil
+	case "Kubernetes":
+		return executor == "kubernetes" || f.runner.Kubernetes != nil
+	case "Machine":
+		return executor == "docker+machine" || f.runner.Machine != nil
+	}
+	return true
+}
+
+// capturing reports whether a field or entry is being edited, so keys must
+// go to the input.
+func (f *runnerForm) capturing() bool {
+	return f.editing || f.entries != nil
+}
+
+func (f *runnerForm) fields() []config.Field {
+	return f.sections[f.section].fields
+}
+
+func (f *runnerForm) focused() config.Field {
+	return f.fields()[f.focus]
+}
+
+func (f *runnerForm) handleKey(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case f.entries != nil:
+		return f.handleEntriesKey(msg)
+	case f.editing:
+		return f.handleEditKey(msg)
+	}
+
+	f.err = nil
+	switch msg.String() {
+	case "down", "tab":
+		f.focus = (f.focus + 1) % len(f.fields())
+	case "up", "shift+tab":
+		f.focus = (f.focus - 1 + len(f.fields())) % len(f.fields())
+	case "right":
+		f.section = (f.section + 1) % len(f.sections)
+		f.focus, f.offset = 0, 0
+	case "left":
+		f.section = (f.section - 1 + len(f.sections)) % len(f.sections)
+		f.focus, f.offset = 0, 0
+	case "enter", " ":
+		return f.edit()
+	}
+	return nil
+}
+
+// edit starts editing the focused field.
+func (f *runnerForm) edit() tea.Cmd {
+	field := f.focused()
+	switch {
+	case !field.Editable():
+		f.err = fmt.Errorf("%s cannot be edited here, edit config.toml instead", field.Key)
+	case field.Kind == config.KindBool && field.Type.Kind() != reflect.Ptr:
+		value := "true"
+		if field.Text(f.runner) == "true" {
+			value = "false"
+		}
+		f.err = field.SetText(f.runner, value)
+	case field.Kind == config.KindList || field.Kind == config.KindMap:
+		f.entries = &entryList{field: field, items: field.Entries(f.runner), editing: -1}
+	default:
+		f.editing = true
+		f.input.Placeholder = field.Kind.String()
+		f.input.SetValue(field.Text(f.runner))
+		f.input.CursorEnd()
+		return f.input.Focus()
+	}
+	return nil
+}
+
+func (f *runnerForm) handleEditKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		f.editing = false
+		f.err = nil
+		f.input.Blur()
+		return nil
+	case "enter":
+		// Invalid values keep the input open to be corrected
+		if f.err = f.focused().SetText(f.runner, f.input.Value()); f.err == nil {
+			f.editing = false
+			f.input.Blur()
+		}
+		return nil
+	}
+	var cmd tea.Cmd
+	f.input, cmd = f.input.Update(msg)
+	return cmd
+}
+
+func (f *runnerForm) handleEntriesKey(msg tea.KeyMsg) tea.Cmd {
+	e := f.entries
+	if e.editing >= 0 {
+		switch msg.String() {
+		case "esc":
+			e.editing = -1
+			f.err = nil
+			f.input.Blur()
+			return nil
+		case "enter":
+			items := append([]string(nil), e.items...)
+			if e.editing == len(items) {
+				items = append(items, f.input.Value())
+			} else {
+				items[e.editing] = f.input.Value()
+			}
+			f.setEntries(items)
+			if f.err == nil {
+				e.editing = -1
+				f.input.Blur()
+			}
+			return nil
+		}
+		var cmd tea.Cmd
+		f.input, cmd = f.input.Update(msg)
+		return cmd
+	}
+
+	f.err = nil
+	switch msg.String() {
+	case "esc":
+		f.entries = nil
+	case "down":
+		if e.selected < len(e.items)-1 {
+			e.selected++
+		}
+	case "up":
+		if e.selected > 0 {
+			e.selected--
+		}
+	case "a":
+		return f.editEntry(len(e.items), "")
+	case "enter", "e":
+		if e.selected < len(e.items) {
+			return f.editEntry(e.selected, e.items[e.selected])
+		}
+	case "d", "delete":
+		if e.selected < len(e.items) {
+			items := append(append([]string(nil), e.items[:e.selected]...), e.items[e.selected+1:]...)
+			f.setEntries(items)
+		}
+	}
+	return nil
+}
+
+func (f *runnerForm) editEntry(index int, value string) tea.Cmd {
+	f.entries.editing = index
+	f.input.Placeholder = "value"
+	if f.entries.field.Kind == config.KindMap {
+		f.input.Placeholder = "key=value"
+	}
+	f.input.SetValue(value)
+	f.input.CursorEnd()
+	return f.input.Focus()
+}
+
+// setEntries stores items in the open field and lists them as stored.
+func (f *runnerForm) setEntries(items []string) {
+	e := f.entries
+	if f.err = e.field.SetEntries(f.runner, items); f.err != nil {
+		return
+	}
+	e.items = e.field.Entries(f.runner)
+	e.selected = min(e.selected, max(len(e.items)-1, 0))
+}
+
+// View renders the form in at most height lines.
+func (f *runnerForm) View(height int) string {
+	var tabs []string
+	shown := make(map[string]bool)
+	for i, section := range f.sections {
+		style := ListItemStyle
+		if i == f.section {
+			style = SelectedItemStyle
+		}
+		name := section.name
+		count := 0
+		for _, field := range section.fields {
+			shown[field.Key] = true
+			count += len(f.findings[field.Key])
+		}
+		if count > 0 {
+			name = fmt.Sprintf("%s (%d)", name, count)
+		}
+		tabs = append(tabs, style.Render(name))
+	}
+	content := []string{lipgloss.JoinHorizontal(lipgloss.Top, tabs...), ""}
+
+	// Findings on keys the form does not list, such as the token
+	var keys []string
+	for key := range f.findings {
+		if !shown[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		content = append(content, ListItemStyle.Render(fmt.Sprintf("%-38s %s", key, renderFindings(f.findings[key]))))
+	}
+	if len(keys) > 0 {
+		content = append(content, "")
+	}
+
+	if f.entries != nil {
+		content = append(content, f.entriesView()...)
+	} else {
+		content = append(content, f.fieldsView(height)...)
+	}
+
+	if f.err != nil {
+		content = append(content, ErrorBoxStyle.Render(f.err.Error()))
+	}
+	help := "↑/↓: Field • ←/→: Section • Enter: Edit • [/]: Runner • Ctrl+S: Save • Esc: Back"
+	switch {
+	case f.editing || (f.entries != nil && f.entries.editing >= 0):
+		help = "Enter: Apply • Esc: Cancel"
+	case f.entries != nil:
+		help = "↑/↓: Select • a: Add • Enter: Edit • d: Remove • Esc: Done"
+	}
+	content = append(content, HelpStyle.Render(help))
+	return lipgloss.JoinVertical(lipgloss.Left, content...)
+}
+
+// fieldsView lists the fields of the focused section that fit in height
+// lines, scrolled to keep the focused field visible.
+func (f *runnerForm) fieldsView(height int) []string {
+	fields := f.fields()
+	rows := max(height, 5)
+	if f.focus < f.offset {
+		f.offset = f.focus
+	} else if f.focus >= f.offset+rows {
+		f.offset = f.focus - rows + 1
+	}
+
+	var lines []string
+	for i := f.offset; i < min(len(fields), f.offset+rows); i++ {
+		field := fields[i]
+		value := field.Text(f.runner)
+		switch {
+		case !field.Editable():
+			value = StatusUnknownStyle.Render(fmt.Sprintf("%d entries (edit config.toml)", len(field.Entries(f.runner))))
+		case i == f.focus && f.editing:
+			value = f.input.View()
+		case value == "":
+			value = StatusUnknownStyle.Render("-")
+		case field.Kind == config.KindList || field.Kind == config.KindMap:
+			value = fmt.Sprintf("%s (%d)", shorten(value, 60), len(field.Entries(f.runner)))
+		default:
+			value = shorten(strings.ReplaceAll(value, "\n", "⏎"), 70)
+		}
+
+		if findings := f.findings[field.Key]; len(findings) > 0 {
+			value += "  " + renderFindings(findings)
+		}
+
+		line := fmt.Sprintf("%-38s %s", field.Name, value)
+		if i == f.focus {
+			line = SelectedItemStyle.Render(fmt.Sprintf("%-36s", field.Name)) + " " + value
+		} else {
+			line = ListItemStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	if len(fields) > rows {
+		lines = append(lines, StatusUnknownStyle.Render(fmt.Sprintf("  %d-%d of %d", f.offset+1, min(len(fields), f.offset+rows), len(fields))))
+	}
+	return lines
+}
+
+func (f *runnerForm) entriesView() []string {
+	e := f.entries
+	lines := []string{TitleStyle.Render(fmt.Sprintf("%s (%s)", e.field.Key, e.field.Kind)), ""}
+	if len(e.items) == 0 && e.editing < 0 {
+		lines = append(lines, StatusUnknownStyle.Render("  No entries"))
+	}
+	for i, item := range e.items {
+		switch {
+		case i == e.editing:
+			lines = append(lines, ListItemStyle.Render(f.input.View()))
+		case i == e.selected && e.editing < 0:
+			lines = append(lines, SelectedItemStyle.Render(item))
+		default:
+			lines = append(lines, ListItemStyle.Render(item))
+		}
+	}
+	if e.editing == len(e.items) {
+		lines = append(lines, ListItemStyle.Render(f.input.View()))
+	}
+	return lines
+}
+
+// shorten cuts s to n runes, ending it in "…" when it is longer.
+func shorten(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -2,34 +2,71 @@ package ui
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/config"
 	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
 )
 
+// runningJobsInterval is how often the Runners tab refreshes the jobs in
+// progress.
+const runningJobsInterval = 5 * time.Second
+
 type RunnersView struct {
-	table       table.Model
-	runners     []runner.Runner
-	service     runner.Service
+	table     table.Model
+	runners   []runner.Runner
+	service   runner.Service
+	configMgr *config.TOMLConfigManager
+	// configMu serializes the commands loading and changing config.toml,
+	// which run concurrently
+	configMu    sync.Mutex
 	width       int
 	height      int
 	loading     bool
 	spinner     spinner.Model
 	err         error
 	selectedIdx int
+	confirm     *confirmModal
+	descInput   textinput.Model
+	editingDesc bool
+	notice      string
+
+	// The jobs in progress on the host, newest first, and the job limits
+	// of config.toml they count against
+	running     []runner.Job
+	jobsErr     error
+	jobsPending bool
+	jobsAsked   time.Time
+	jobsLoaded  time.Time
+	limits      runnerLimits
+}
+
+// runnerLimits are the concurrent setting of config.toml and the limit of
+// each runner in it. Zero is unknown or unlimited.
+type runnerLimits struct {
+	concurrent int
+	runner     map[string]int
 }
 
 func NewRunnersView(service runner.Service) *RunnersView {
 	columns := []table.Column{
-		{Title: "Name", Width: 30},
-		{Title: "Status", Width: 12},
-		{Title: "Executor", Width: 15},
-		{Title: "Tags", Width: 30},
+		{Title: "Name", Width: 20},
+		{Title: "Status", Width: 10},
+		{Title: "Executor", Width: 10},
+		{Title: "Tags", Width: 16},
 		{Title: "ID", Width: 10},
+		{Title: "Last Contact", Width: 12},
+		{Title: "Jobs", Width: 7},
+		{Title: "Current Job", Width: 30},
 	}
 
 	t := table.New(
@@ -54,21 +91,51 @@ func NewRunnersView(service runner.Service) *RunnersView {
 	sp.Spinner = spinner.Dot
 	sp.Style = SpinnerStyle
 
+	descInput := textinput.New()
+	descInput.Prompt = "Description: "
+	descInput.CharLimit = 255
+
 	return &RunnersView{
-		table:   t,
-		service: service,
-		spinner: sp,
-		loading: true,
+		table:     t,
+		service:   service,
+		spinner:   sp,
+		loading:   true,
+		descInput: descInput,
 	}
 }
 
 func (v *RunnersView) Init() tea.Cmd {
 	return tea.Batch(
-		v.loadRunners,
+		v.loadRunners(),
 		v.spinner.Tick,
+		v.loadRunningJobs(),
 	)
 }
 
+// Activate resumes refreshing the running jobs when the tab is shown
+// again. Messages are only delivered to the active tab, so ticks and
+// results sent while the runners were hidden are lost.
+func (v *RunnersView) Activate() tea.Cmd {
+	if v.jobsPending && time.Since(v.jobsAsked) < time.Minute {
+		return nil
+	}
+	if !v.jobsPending && time.Since(v.jobsLoaded) < runningJobsInterval {
+		return nil
+	}
+	return v.loadRunningJobs()
+}
+
+// loadRunningJobs asks the service for the jobs in progress.
+func (v *RunnersView) loadRunningJobs() tea.Cmd {
+	v.jobsPending = true
+	v.jobsAsked = time.Now()
+	service := v.service
+	return func() tea.Msg {
+		jobs, err := service.GetRunningJobs()
+		return runningJobsMsg{service: service, jobs: jobs, err: err}
+	}
+}
+
 func (v *RunnersView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
@@ -76,21 +143,72 @@ func (v *RunnersView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		v.width = msg.Width
 		v.height = msg.Height
-		v.table.SetHeight(v.height - 10)
+		// Room for the running jobs of the selected runner below the table
+		v.table.SetHeight(v.height - 16)
 		return v, nil
 
 	case runnersLoadedMsg:
+		// Runners of a host that is no longer shown are dropped
+		if msg.service != v.service {
+			return v, nil
+		}
 		v.runners = msg.runners
+		v.limits = msg.limits
 		v.loading = false
 		v.err = msg.err
 		v.updateTable()
 		return v, nil
 
+	case runningJobsMsg:
+		// Jobs of a host that is no longer shown are dropped
+		if msg.service != v.service {
+			return v, nil
+		}
+		v.running, v.jobsErr = msg.jobs, msg.err
+		v.jobsPending = false
+		v.jobsLoaded = time.Now()
+		v.updateTable()
+		service := v.service
+		return v, tea.Tick(runningJobsInterval, func(time.Time) tea.Msg {
+			return runningJobsTickMsg{service: service}
+		})
+
+	case runningJobsTickMsg:
+		if msg.service != v.service || v.jobsPending {
+			return v, nil
+		}
+		return v, v.loadRunningJobs()
+
+	case runnerActionMsg:
+		v.notice = msg.notice
+		if msg.err != nil {
+			v.notice = fmt.Sprintf("Error: %v", msg.err)
+		}
+		v.loading = true
+		return v, tea.Batch(v.loadRunners(), v.spinner.Tick)
+
 	case tea.KeyMsg:
+		if v.confirm != nil {
+			done, cmd := v.confirm.handleKey(msg)
+			if done {
+				v.confirm = nil
+			}
+			return v, cmd
+		}
+		if v.editingDesc {
+			return v.handleDescriptionKey(msg)
+		}
+
 		switch msg.String() {
 		case "r", "R":
 			v.loading = true
-			return v, v.loadRunners
+			return v, v.loadRunners()
+		case "p", "P":
+			return v, v.confirmPause()
+		case "e", "E":
+			return v, v.startDescriptionEdit()
+		case "d", "delete":
+			return v, v.confirmUnregister()
 		case "enter":
 			if len(v.runners) > 0 && v.table.Cursor() < len(v.runners) {
 				v.selectedIdx = v.table.Cursor()
@@ -127,13 +245,27 @@ func (v *RunnersView) View() string {
 
 	content := []string{
 		HeaderStyle.Render("GitLab Runners"),
+		v.runningSummary(),
 		"",
 	}
 
-	if len(v.runners) == 0 {
+	switch {
+	case v.confirm != nil:
+		content = append(content, v.confirm.View(v.width))
+	case v.editingDesc:
+		content = append(content, FocusedInputStyle.Render(v.descInput.View()),
+			HelpStyle.Render("Enter: Save • Esc: Cancel"))
+	case len(v.runners) == 0:
 		content = append(content, InfoBoxStyle.Render("No runners found"))
-	} else {
+	default:
 		content = append(content, v.table.View())
+		if panel := v.runningPanel(); panel != "" {
+			content = append(content, "", panel)
+		}
+	}
+
+	if v.notice != "" {
+		content = append(content, "", v.notice)
 	}
 
 	// Help is now shown in the status bar
@@ -150,39 +282,188 @@ func (v *RunnersView) updateTable() {
 			tags = "-"
 		}
 
+		// A paused runner stays online, so pausing is shown first
 		status := "unknown"
-		if r.Online {
+		switch {
+		case r.Paused || r.Status == "paused":
+			status = "paused"
+		case r.Online:
 			status = "online"
-		} else if r.Status != "" {
+		case r.Status != "":
 			status = r.Status
 		}
 
+		lastContact := "-"
+		if !r.LastContact.IsZero() {
+			lastContact = formatAgo(time.Since(r.LastContact))
+		}
+
+		jobs := v.runnerJobs(r)
+		if v.jobsLoaded.IsZero() || v.jobsErr != nil {
+			jobs = nil
+			if r.CurrentJob != nil {
+				jobs = []runner.Job{*r.CurrentJob}
+			}
+		} else {
+			r.CurrentJob = nil
+			if len(jobs) > 0 {
+				r.CurrentJob = &jobs[0]
+			}
+		}
+
+		currentJob := "-"
+		if r.CurrentJob != nil {
+			currentJob = describeRunningJob(*r.CurrentJob)
+			if len(jobs) > 1 {
+				currentJob += fmt.Sprintf(" (+%d)", len(jobs)-1)
+			}
+		}
+
 		rows = append(rows, table.Row{
-			TruncateString(r.Name, 30),
+			TruncateString(r.Name, 20),
 			RenderStatus(status),
 			r.Executor,
-			TruncateString(tags, 30),
+			TruncateString(tags, 16),
 			r.ID,
+			lastContact,
+			v.jobCount(r.Name, len(jobs)),
+			TruncateString(currentJob, 30),
 		})
 	}
 	v.table.SetRows(rows)
 }
 
-func (v *RunnersView) loadRunners() tea.Msg {
-	runners, err := v.service.ListRunners()
-	if err != nil {
-		return runnersLoadedMsg{err: err}
+// runnerJobs are the running jobs of a runner, newest first. The log
+// names runners by short token when config.toml does not have them.
+func (v *RunnersView) runnerJobs(r *runner.Runner) []runner.Job {
+	tokens := r.ShortTokens()
+	var jobs []runner.Job
+	for _, job := range v.running {
+		if job.RunnerName == r.Name || (job.RunnerName != "" && slices.Contains(tokens, job.RunnerName)) {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs
+}
+
+// limit is how many jobs a runner may run at once: its limit in
+// config.toml, or concurrent when it has none.
+func (v *RunnersView) limit(name string) int {
+	if limit := v.limits.runner[name]; limit > 0 && (v.limits.concurrent == 0 || limit < v.limits.concurrent) {
+		return limit
+	}
+	return v.limits.concurrent
+}
+
+// jobCount renders running jobs against the runner's limit, e.g. "2/4".
+func (v *RunnersView) jobCount(name string, running int) string {
+	if limit := v.limit(name); limit > 0 {
+		return fmt.Sprintf("%d/%d", running, limit)
 	}
+	return strconv.Itoa(running)
+}
 
-	for i := range runners {
-		status, err := v.service.GetRunnerStatus(runners[i].Name)
-		if err == nil && status != nil {
-			runners[i].Status = status.Status
-			runners[i].Online = status.Online
+// describeRunningJob renders a running job as its ID, project and how
+// long it has been running.
+func describeRunningJob(job runner.Job) string {
+	parts := []string{fmt.Sprintf("#%d", job.ID)}
+	if job.Project != "" {
+		parts = append(parts, job.Project)
+	} else if job.Name != "" {
+		parts = append(parts, job.Name)
+	}
+	if !job.Started.IsZero() {
+		parts = append(parts, formatJobDuration(time.Since(job.Started)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// runningSummary is the line under the header with the jobs running on
+// the host against concurrent.
+func (v *RunnersView) runningSummary() string {
+	muted := lipgloss.NewStyle().Foreground(ColorMuted)
+	switch {
+	case v.jobsErr != nil:
+		return muted.Render(fmt.Sprintf("Running jobs unknown: %v", v.jobsErr))
+	case v.jobsLoaded.IsZero():
+		return muted.Render("Loading running jobs...")
+	case v.limits.concurrent > 0:
+		return muted.Render(fmt.Sprintf("Running jobs: %d of %d concurrent", len(v.running), v.limits.concurrent))
+	default:
+		return muted.Render(fmt.Sprintf("Running jobs: %d", len(v.running)))
+	}
+}
+
+// runningPanel lists the running jobs of the runner under the cursor.
+func (v *RunnersView) runningPanel() string {
+	r := v.cursorRunner()
+	if r == nil || v.jobsLoaded.IsZero() || v.jobsErr != nil {
+		return ""
+	}
+	jobs := v.runnerJobs(r)
+	if len(jobs) == 0 {
+		return lipgloss.NewStyle().Foreground(ColorMuted).Render(fmt.Sprintf("No jobs running on %s", r.Name))
+	}
+
+	lines := []string{fmt.Sprintf("Running on %s (%s):", r.Name, v.jobCount(r.Name, len(jobs)))}
+	for _, job := range jobs {
+		elapsed := "-"
+		if !job.Started.IsZero() {
+			elapsed = formatJobDuration(time.Since(job.Started))
 		}
+		lines = append(lines, fmt.Sprintf("  #%-10d %-40s %s", job.ID, TruncateString(dash(job.Project), 40), elapsed))
 	}
+	return InfoBoxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
 
-	return runnersLoadedMsg{runners: runners}
+// formatAgo renders how long ago something happened, e.g. "5m ago".
+func formatAgo(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours())/24)
+	}
+}
+
+// loadRunners lists the runners of the service and their limits in
+// config.toml.
+func (v *RunnersView) loadRunners() tea.Cmd {
+	service, configMgr, mu := v.service, v.configMgr, &v.configMu
+	return func() tea.Msg {
+		runners, err := service.ListRunners()
+		if err != nil {
+			return runnersLoadedMsg{service: service, err: err}
+		}
+
+		var limits runnerLimits
+		if configMgr != nil {
+			mu.Lock()
+			if configMgr.Load() == nil {
+				cfg := configMgr.GetConfig()
+				limits.concurrent = cfg.Concurrent
+				limits.runner = make(map[string]int)
+				for _, rc := range cfg.Runners {
+					limits.runner[rc.Name] = rc.Limit
+				}
+			}
+			mu.Unlock()
+		}
+
+		for i := range runners {
+			status, err := service.GetRunnerStatus(runners[i].Name)
+			if err == nil && status != nil {
+				runners[i].Status = status.Status
+				runners[i].Online = status.Online
+			}
+		}
+
+		return runnersLoadedMsg{service: service, runners: runners, limits: limits}
+	}
 }
 
 func (v *RunnersView) GetSelectedRunner() *runner.Runner {
@@ -192,7 +473,178 @@ func (v *RunnersView) GetSelectedRunner() *runner.Runner {
 	return nil
 }
 
+// SetService points the view at another host. Runners loaded from the
+// previous host are dropped.
+func (v *RunnersView) SetService(service runner.Service) {
+	v.service = service
+	v.runners = nil
+	v.selectedIdx = 0
+	v.confirm = nil
+	v.editingDesc = false
+	v.notice = ""
+	v.err = nil
+	v.loading = true
+	v.running = nil
+	v.jobsErr = nil
+	v.jobsPending = false
+	v.jobsLoaded = time.Time{}
+	v.updateTable()
+}
+
+// Capturing reports whether a modal or input has the keyboard, so global
+// key bindings must not be applied.
+func (v *RunnersView) Capturing() bool {
+	return v.confirm != nil || v.editingDesc
+}
+
+// SetConfigManager sets the config.toml of the host the runners belong to,
+// from which unregistered runners are removed.
+func (v *RunnersView) SetConfigManager(configMgr *config.TOMLConfigManager) {
+	v.configMgr = configMgr
+}
+
+func (v *RunnersView) cursorRunner() *runner.Runner {
+	if i := v.table.Cursor(); !v.loading && i >= 0 && i < len(v.runners) {
+		return &v.runners[i]
+	}
+	return nil
+}
+
+func (v *RunnersView) administrator() (runner.Administrator, bool) {
+	admin, ok := v.service.(runner.Administrator)
+	if !ok {
+		v.notice = "Pausing and editing runners requires the GitLab API (set GITLAB_URL and GITLAB_TOKEN)"
+	}
+	return admin, ok
+}
+
+func (v *RunnersView) confirmPause() tea.Cmd {
+	r := v.cursorRunner()
+	if r == nil {
+		return nil
+	}
+	admin, ok := v.administrator()
+	if !ok {
+		return nil
+	}
+
+	name, pause := r.Name, !r.Paused
+	title, message, done := "Pause runner", fmt.Sprintf("Pause %s? It stops picking up new jobs; running jobs finish.", name), "paused"
+	if !pause {
+		title, message, done = "Resume runner", fmt.Sprintf("Resume %s? It starts picking up jobs again.", name), "resumed"
+	}
+
+	v.confirm = newConfirmModal(title, message, func() tea.Msg {
+		if err := admin.PauseRunner(name, pause); err != nil {
+			return runnerActionMsg{err: err}
+		}
+		return runnerActionMsg{notice: fmt.Sprintf("Runner %s %s", name, done)}
+	})
+	return nil
+}
+
+func (v *RunnersView) startDescriptionEdit() tea.Cmd {
+	r := v.cursorRunner()
+	if r == nil {
+		return nil
+	}
+	if _, ok := v.administrator(); !ok {
+		return nil
+	}
+
+	v.editingDesc = true
+	v.descInput.SetValue(r.Description)
+	v.descInput.CursorEnd()
+	return v.descInput.Focus()
+}
+
+func (v *RunnersView) handleDescriptionKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		v.editingDesc = false
+		v.descInput.Blur()
+		return v, nil
+	case "enter":
+		v.editingDesc = false
+		v.descInput.Blur()
+
+		r := v.cursorRunner()
+		admin, ok := v.administrator()
+		if r == nil || !ok {
+			return v, nil
+		}
+		name, description := r.Name, strings.TrimSpace(v.descInput.Value())
+		return v, func() tea.Msg {
+			if err := admin.SetRunnerDescription(name, description); err != nil {
+				return runnerActionMsg{err: err}
+			}
+			return runnerActionMsg{notice: fmt.Sprintf("Description of %s updated", name)}
+		}
+	}
+
+	var cmd tea.Cmd
+	v.descInput, cmd = v.descInput.Update(msg)
+	return v, cmd
+}
+
+func (v *RunnersView) confirmUnregister() tea.Cmd {
+	r := v.cursorRunner()
+	if r == nil {
+		return nil
+	}
+
+	name := r.Name
+	message := fmt.Sprintf("Unregister %s? It is deleted from GitLab and removed from config.toml. This cannot be undone.", name)
+	service, configMgr, mu := v.service, v.configMgr, &v.configMu
+	v.confirm = newConfirmModal("Unregister runner", message, func() tea.Msg {
+		return runnerActionMsg{notice: fmt.Sprintf("Runner %s unregistered", name), err: unregister(service, configMgr, mu, name)}
+	})
+	return nil
+}
+
+// unregister runs gitlab-runner unregister and makes sure the runner's
+// entry is gone from config.toml afterwards. mu guards configMgr.
+func unregister(service runner.Service, configMgr *config.TOMLConfigManager, mu *sync.Mutex, name string) error {
+	if err := service.UnregisterRunner(name); err != nil {
+		return err
+	}
+	if configMgr == nil {
+		return nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if err := configMgr.Load(); err != nil {
+		return err
+	}
+	if r, _ := configMgr.GetRunner(name); r == nil {
+		// gitlab-runner already removed it
+		return nil
+	}
+	if err := configMgr.RemoveRunner(name); err != nil {
+		return err
+	}
+	return configMgr.Save()
+}
+
+type runnerActionMsg struct {
+	notice string
+	err    error
+}
+
 type runnersLoadedMsg struct {
+	service runner.Service
 	runners []runner.Runner
+	limits  runnerLimits
 	err     error
 }
+
+type runningJobsMsg struct {
+	service runner.Service
+	jobs    []runner.Job
+	err     error
+}
+
+type runningJobsTickMsg struct {
+	service runner.Service
+}
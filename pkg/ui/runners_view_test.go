@@ -0,0 +1,301 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/config"
+	"github.com/larkinwc/gitlab-runner-tui/pkg/runner"
+)
+
+type adminService struct {
+	runner.Service
+	runners      []runner.Runner
+	unregistered []string
+	paused       map[string]bool
+	descriptions map[string]string
+}
+
+func (s *adminService) ListRunners() ([]runner.Runner, error) {
+	return s.runners, nil
+}
+
+func (s *adminService) GetRunnerStatus(name string) (*runner.Runner, error) {
+	for i := range s.runners {
+		if s.runners[i].Name == name {
+			return &s.runners[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *adminService) UnregisterRunner(name string) error {
+	s.unregistered = append(s.unregistered, name)
+	return nil
+}
+
+func (s *adminService) PauseRunner(name string, paused bool) error {
+	s.paused[name] = paused
+	for i := range s.runners {
+		if s.runners[i].Name == name {
+			s.runners[i].Paused = paused
+		}
+	}
+	return nil
+}
+
+func (s *adminService) SetRunnerDescription(name, description string) error {
+	s.descriptions[name] = description
+	return nil
+}
+
+// plainService lists runners but cannot change them in GitLab.
+type plainService struct {
+	runner.Service
+}
+
+func (s *plainService) ListRunners() ([]runner.Runner, error) {
+	return []runner.Runner{{Name: "docker-1"}}, nil
+}
+
+func (s *plainService) GetRunnerStatus(_ string) (*runner.Runner, error) {
+	return nil, nil
+}
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func newAdminRunnersView(t *testing.T) (*RunnersView, *adminService) {
+	t.Helper()
+	service := &adminService{
+		runners: []runner.Runner{
+			{Name: "docker-1", Description: "old", Paused: true},
+			{Name: "shell-1"},
+		},
+		paused:       make(map[string]bool),
+		descriptions: make(map[string]string),
+	}
+	v := NewRunnersView(service)
+	v.Update(v.loadRunners()())
+	return v, service
+}
+
+// run executes cmd and feeds the resulting message back into the view.
+func run(v *RunnersView, cmd tea.Cmd) {
+	if cmd != nil {
+		v.Update(cmd())
+	}
+}
+
+func TestRunnersView_PauseNeedsConfirmation(t *testing.T) {
+	v, service := newAdminRunnersView(t)
+
+	v.Update(key("p"))
+	if !v.Capturing() || !strings.Contains(v.View(), "Resume docker-1?") {
+		t.Fatalf("expected resume confirmation:\n%s", v.View())
+	}
+
+	_, cmd := v.Update(key("n"))
+	run(v, cmd)
+	if v.Capturing() || len(service.paused) != 0 {
+		t.Fatalf("cancelled action was run: %v", service.paused)
+	}
+
+	v.Update(key("p"))
+	_, cmd = v.Update(key("y"))
+	run(v, cmd)
+	if paused, ok := service.paused["docker-1"]; !ok || paused {
+		t.Errorf("expected docker-1 to be resumed, got %v", service.paused)
+	}
+	if !strings.Contains(v.notice, "resumed") {
+		t.Errorf("unexpected notice %q", v.notice)
+	}
+}
+
+func TestRunnersView_PausedOnlineRunner(t *testing.T) {
+	v, service := newAdminRunnersView(t)
+	service.runners[1].Online = true
+	v.Update(v.loadRunners()())
+	if status := v.table.Rows()[1][1]; !strings.Contains(status, "online") {
+		t.Fatalf("expected shell-1 online, got %q", status)
+	}
+
+	v.Update(tea.KeyMsg{Type: tea.KeyDown})
+	v.Update(key("p"))
+	_, cmd := v.Update(key("y"))
+	run(v, cmd)
+	v.Update(v.loadRunners()())
+
+	if status := v.table.Rows()[1][1]; !strings.Contains(status, "paused") {
+		t.Errorf("expected paused online runner to show paused, got %q", status)
+	}
+}
+
+func TestRunnersView_EditDescription(t *testing.T) {
+	v, service := newAdminRunnersView(t)
+
+	v.Update(key("e"))
+	if !v.Capturing() || v.descInput.Value() != "old" {
+		t.Fatalf("expected description input with current value, got %q", v.descInput.Value())
+	}
+	for _, r := range " runner" {
+		v.Update(key(string(r)))
+	}
+	_, cmd := v.Update(key("enter"))
+	run(v, cmd)
+
+	if service.descriptions["docker-1"] != "old runner" {
+		t.Errorf("unexpected descriptions %v", service.descriptions)
+	}
+}
+
+func TestRunnersView_UnregisterRemovesFromConfig(t *testing.T) {
+	v, service := newAdminRunnersView(t)
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := "concurrent = 1\n\n[[runners]]\n  name = \"docker-1\"\n  token = \"a\"\n\n[[runners]]\n  name = \"shell-1\"\n  token = \"b\"\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	v.SetConfigManager(config.NewTOMLConfigManager(path))
+
+	v.Update(key("d"))
+	if !strings.Contains(v.View(), "Unregister docker-1?") {
+		t.Fatalf("expected unregister confirmation:\n%s", v.View())
+	}
+	_, cmd := v.Update(key("enter"))
+	run(v, cmd)
+
+	if len(service.unregistered) != 1 || service.unregistered[0] != "docker-1" {
+		t.Errorf("unexpected unregistered runners %v", service.unregistered)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "docker-1") || !strings.Contains(string(data), "shell-1") {
+		t.Errorf("unexpected config after unregister:\n%s", data)
+	}
+}
+
+func TestRunnersView_RefreshDuringUnregister(t *testing.T) {
+	v, _ := newAdminRunnersView(t)
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := "concurrent = 1\n\n[[runners]]\n  name = \"docker-1\"\n  token = \"a\"\n\n[[runners]]\n  name = \"shell-1\"\n  token = \"b\"\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	v.SetConfigManager(config.NewTOMLConfigManager(path))
+
+	v.Update(key("d"))
+	_, unregister := v.Update(key("enter"))
+	refresh := v.loadRunners()
+
+	// Run with -race: both commands use the view's config manager
+	done := make(chan tea.Msg)
+	go func() { done <- refresh() }()
+	msg := unregister()
+	<-done
+	if action, ok := msg.(runnerActionMsg); !ok || action.err != nil {
+		t.Fatalf("unexpected result %+v", msg)
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "docker-1") {
+		t.Errorf("expected runner removed from config:\n%s", data)
+	}
+}
+
+func TestRunnersView_WithoutAPI(t *testing.T) {
+	v := NewRunnersView(&plainService{})
+	v.Update(v.loadRunners()())
+
+	v.Update(key("p"))
+	if v.Capturing() || !strings.Contains(v.notice, "requires the GitLab API") {
+		t.Errorf("expected notice about the API, got %q", v.notice)
+	}
+}
+
+// runningService lists runners with jobs in progress.
+type runningService struct {
+	plainService
+	jobs  []runner.Job
+	calls int
+}
+
+func (s *runningService) ListRunners() ([]runner.Runner, error) {
+	return []runner.Runner{
+		{Name: "docker-1", ID: "glrt-Zx9", Token: "glrt-Zx9pQw7LmN4bV6cX8Aq"},
+		{Name: "shell-1", ID: "Kq8rTzUw", Token: "Kq8rTzUwxYcD3fG7hJ2e"},
+	}, nil
+}
+
+func (s *runningService) GetRunningJobs() ([]runner.Job, error) {
+	s.calls++
+	return s.jobs, nil
+}
+
+func TestRunnersView_RunningJobs(t *testing.T) {
+	now := time.Now()
+	service := &runningService{jobs: []runner.Job{
+		// The log names runners missing from config.toml by short token
+		{ID: 5513, Status: "running", Project: "web/docs", RunnerName: "Zx9pQw7L", Started: now.Add(-30 * time.Second)},
+		{ID: 5512, Status: "running", Project: "web/app", RunnerName: "docker-1", Started: now.Add(-2 * time.Minute)},
+	}}
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := "concurrent = 4\n\n[[runners]]\n  name = \"docker-1\"\n  limit = 2\n\n[[runners]]\n  name = \"shell-1\"\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	v := NewRunnersView(service)
+	v.SetConfigManager(config.NewTOMLConfigManager(path))
+	v.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+	v.Update(v.loadRunners()())
+	if !strings.Contains(v.View(), "Loading running jobs") {
+		t.Errorf("expected the running jobs to be loading:\n%s", v.View())
+	}
+
+	_, tick := v.Update(v.loadRunningJobs()())
+	if tick == nil {
+		t.Fatal("expected the next refresh to be scheduled")
+	}
+	view := v.View()
+	for _, expected := range []string{"Running jobs: 2 of 4 concurrent", "2/2", "0/4", "#5513 web/docs 30s (+1)", "Running on docker-1 (2/2)", "#5512", "2m 0s"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("expected %q:\n%s", expected, view)
+		}
+	}
+	if r := v.GetSelectedRunner(); r.CurrentJob == nil || r.CurrentJob.ID != 5513 {
+		t.Errorf("expected the newest job as the current job, got %+v", r.CurrentJob)
+	}
+
+	// Refreshes load the running jobs again, but not those of another host
+	_, cmd := v.Update(runningJobsTickMsg{service: service})
+	v.Update(cmd())
+	if service.calls != 2 {
+		t.Errorf("expected the jobs to be refreshed, got %d calls", service.calls)
+	}
+	v.Update(runningJobsMsg{service: &runningService{}})
+	if !strings.Contains(v.View(), "Running jobs: 2 of 4") {
+		t.Errorf("expected jobs of another host to be dropped:\n%s", v.View())
+	}
+}
+
+func TestRunnersView_DropsRunnersOfPreviousHost(t *testing.T) {
+	v := NewRunnersView(&plainService{})
+	stale := v.loadRunners()
+
+	// The host changes while its runners are still being listed
+	v.SetService(&plainService{})
+	v.Update(stale())
+	if !v.loading || len(v.runners) != 0 {
+		t.Errorf("expected the runners of the previous host to be dropped, got %+v", v.runners)
+	}
+}
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// previewLines is the number of diff lines the save preview shows before
+// the full diff is opened.
+const previewLines = 15
+
+var diffStyles = map[byte]lipgloss.Style{
+	'+': lipgloss.NewStyle().Foreground(ColorSuccess),
+	'-': lipgloss.NewStyle().Foreground(ColorError),
+	'@': lipgloss.NewStyle().Foreground(ColorPrimary),
+}
+
+// paintDiff colors the lines of a unified diff: additions green, removals
+// red and hunk headers in the primary color.
+func paintDiff(diff string) []string {
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+			lines[i] = lipgloss.NewStyle().Bold(true).Render(line)
+		case line != "":
+			if style, ok := diffStyles[line[0]]; ok {
+				lines[i] = style.Render(line)
+			}
+		}
+	}
+	return lines
+}
+
+// diffStat counts the added and removed lines of a unified diff.
+func diffStat(diff string) (added, removed int) {
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// savePreview shows the changes saving config.toml makes before they are
+// written. y or Enter saves, n or Esc cancels, and v opens the whole diff
+// in a scrollable viewport.
+type savePreview struct {
+	diff     string
+	lines    []string
+	expanded bool
+	viewport viewport.Model
+}
+
+func newSavePreview(diff string, width, height int) *savePreview {
+	p := &savePreview{diff: diff, lines: paintDiff(diff), viewport: viewport.New(80, 10)}
+	p.viewport.SetContent(strings.Join(p.lines, "\n"))
+	p.setSize(width, height)
+	return p
+}
+
+func (p *savePreview) setSize(width, height int) {
+	p.viewport.Width = max(width-2, 20)
+	p.viewport.Height = max(height-14, 5)
+}
+
+// handleKey returns whether the preview is done and whether the changes
+// should be saved.
+func (p *savePreview) handleKey(msg tea.KeyMsg) (done, save bool, cmd tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y", "enter":
+		return true, true, nil
+	case "n", "N":
+		return true, false, nil
+	case "esc", "q":
+		if p.expanded {
+			p.expanded = false
+			return false, false, nil
+		}
+		return true, false, nil
+	case "v", "V":
+		p.expanded = !p.expanded
+		p.viewport.GotoTop()
+		return false, false, nil
+	}
+	if p.expanded {
+		p.viewport, cmd = p.viewport.Update(msg)
+	}
+	return false, false, cmd
+}
+
+func (p *savePreview) View() string {
+	added, removed := diffStat(p.diff)
+	content := []string{
+		TitleStyle.Render("Save config.toml?"),
+		fmt.Sprintf("%d lines added, %d removed", added, removed),
+		"",
+	}
+
+	if p.expanded {
+		content = append(content,
+			p.viewport.View(),
+			StatusUnknownStyle.Render(fmt.Sprintf("Position: %d%%", int(p.viewport.ScrollPercent()*100))),
+			HelpStyle.Render("↑/↓/PgUp/PgDn: Scroll • y/Enter: Save • n: Cancel • v/Esc: Back to summary"))
+		return lipgloss.JoinVertical(lipgloss.Left, content...)
+	}
+
+	content = append(content, p.lines[:min(len(p.lines), previewLines)]...)
+	if len(p.lines) > previewLines {
+		content = append(content, StatusUnknownStyle.Render(fmt.Sprintf("… %d more lines", len(p.lines)-previewLines)))
+	}
+	content = append(content, HelpStyle.Render("y/Enter: Save • n/Esc: Cancel • v: View full diff"))
+	return lipgloss.JoinVertical(lipgloss.Left, content...)
+}
@@ -57,6 +57,10 @@ var (
 				Foreground(ColorError).
 				Bold(true)
 
+	StatusWarningStyle = lipgloss.NewStyle().
+				Foreground(ColorWarning).
+				Bold(true)
+
 	StatusUnknownStyle = lipgloss.NewStyle().
 				Foreground(ColorMuted)
 
@@ -72,10 +76,22 @@ var (
 	SuccessBoxStyle = InfoBoxStyle.Copy().
 			BorderForeground(ColorSuccess)
 
+	WarningBoxStyle = InfoBoxStyle.Copy().
+			BorderForeground(ColorWarning)
+
 	LogStyle = lipgloss.NewStyle().
 			Foreground(ColorMuted).
 			PaddingLeft(1)
 
+	SearchMatchStyle = lipgloss.NewStyle().
+				Foreground(ColorBg).
+				Background(ColorSecondary)
+
+	CurrentMatchStyle = lipgloss.NewStyle().
+				Foreground(ColorBg).
+				Background(ColorWarning).
+				Bold(true)
+
 	HelpStyle = lipgloss.NewStyle().
 			Foreground(ColorMuted).
 			Padding(1, 0)
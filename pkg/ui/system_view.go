@@ -167,6 +167,14 @@ func (v *SystemView) restartService() tea.Msg {
 	return v.loadSystemStatus()
 }
 
+// SetService points the view at another host.
+func (v *SystemView) SetService(service runner.Service) {
+	v.service = service
+	v.systemStatus = nil
+	v.err = nil
+	v.loading = true
+}
+
 func formatDuration(d time.Duration) string {
 	days := int(d.Hours()) / 24
 	hours := int(d.Hours()) % 24